@@ -8,15 +8,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Vighnesh-V-H/sync/internal/app"
 	"github.com/Vighnesh-V-H/sync/internal/config"
 	"github.com/Vighnesh-V-H/sync/internal/db"
-	"github.com/Vighnesh-V-H/sync/internal/handler"
 	"github.com/Vighnesh-V-H/sync/internal/logger"
+	"github.com/Vighnesh-V-H/sync/internal/password"
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
 	"github.com/Vighnesh-V-H/sync/internal/repositories"
-	"github.com/Vighnesh-V-H/sync/internal/routes"
 	"github.com/Vighnesh-V-H/sync/internal/service"
-	"github.com/Vighnesh-V-H/sync/internal/utils"
-	"github.com/gin-gonic/gin"
 	_ "github.com/joho/godotenv/autoload"
 )
 
@@ -34,6 +33,12 @@ func main() {
 		ServiceName: cfg.Observability.ServiceName,
 		Environment: cfg.Primary.Env,
 		IsProd:      cfg.Primary.Env == "prod",
+		Redaction: logger.RedactionFromFields(
+			*cfg.Logging.Redact,
+			cfg.Logging.RedactFields,
+			cfg.Logging.RedactPayloadFields,
+			cfg.Logging.RedactSalt,
+		),
 	}
 	if cfg.Logging.Pretty {
 		logCfg.Format = "console"
@@ -46,24 +51,46 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to initialize database")
 	}
 	defer database.Close()
+	database.Guard = db.NewPostgresGuard(cfg.Resilience.ResilienceOptions())
 
-	jwtCfg := utils.JWTConfig{
-		Secret: cfg.JWT.Secret,
-		Expiry: time.Hour * 24 * 7,
+	if done, err := db.PrepareSchema(context.Background(), database, os.Args[1:], cfg.Database.AutoMigrate, log); err != nil {
+		log.Fatal().Err(err).Msg("Database schema is not ready")
+	} else if done {
+		return
 	}
 
-	authRepo := repositories.NewAuthRepository(database, log)
-	authSvc := service.NewAuthService(authRepo, jwtCfg, log)
-	authHandler := handler.NewAuthHandler(authSvc, log)
-
-	if cfg.Primary.Env == "prod" {
-		gin.SetMode(gin.ReleaseMode)
+	var limiter *ratelimit.Limiter
+	if *cfg.RateLimit.Enabled {
+		redisClient, err := db.NewRedis(cfg.Redis.URL, time.Duration(cfg.Redis.Timeout)*time.Second, log)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to connect to Redis")
+		}
+		defer redisClient.Close()
+		db.GuardRedis(redisClient, cfg.Resilience.ResilienceOptions().Breaker)
+		limiter = ratelimit.NewLimiter(redisClient, log)
 	}
 
-	router := gin.Default()
-	api := router.Group("/api/v1")
+	var breached *password.BreachedList
+	if cfg.Password.BreachedList != "" {
+		breached, err = password.OpenBreachedList(cfg.Password.BreachedList)
+		if err != nil {
+			log.Fatal().Err(err).Str("path", cfg.Password.BreachedList).Msg("Failed to open breached password list")
+		}
+		defer breached.Close()
+	}
 
-	routes.SetupAuthRoutes(api, authHandler)
+	router, err := app.NewRouter(cfg, app.AuthCORS(cfg))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid trusted proxies")
+	}
+	app.SetupAuthAPI(router, cfg, app.AuthAPI{
+		DB:       database,
+		Audit:    service.NewAuditService(repositories.NewAuditRepository(database, log), log),
+		Limiter:  limiter,
+		Breached: breached,
+	}, log)
+
+	app.ServeDebug(cfg.Server.DebugAddr, log)
 
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.AuthPort)
 	log.Info().Str("address", addr).Msg("Starting HTTP server")
@@ -0,0 +1,122 @@
+// Command dev runs the events API and the processor with its webhook and
+// erasure workers in one process for local development, wired by package app
+// exactly as cmd/events and cmd/processor wire them. The event queue and
+// dedup store are in memory, so only Postgres is needed; usage quotas, rate
+// limiting and bulk imports, which keep their state in Redis, are disabled.
+// It is the only binary that accepts the memory queue. There is no
+// aggregator to run yet: cmd/aggregator is an empty placeholder.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/app"
+	"github.com/Vighnesh-V-H/sync/internal/config"
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/logger"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	logCfg := logger.Config{
+		Level:       cfg.Logging.Level,
+		Format:      "console",
+		ServiceName: cfg.Observability.ServiceName,
+		Environment: cfg.Primary.Env,
+		Redaction: logger.RedactionFromFields(
+			*cfg.Logging.Redact,
+			cfg.Logging.RedactFields,
+			cfg.Logging.RedactPayloadFields,
+			cfg.Logging.RedactSalt,
+		),
+	}
+	log := logger.New(logCfg)
+	log.Info().Msg("Starting development server")
+
+	database, err := db.NewDB(cfg.Database.URL, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize database")
+	}
+	defer database.Close()
+	database.Guard = db.NewPostgresGuard(cfg.Resilience.ResilienceOptions())
+
+	if done, err := db.PrepareSchema(context.Background(), database, os.Args[1:], true, log); err != nil {
+		log.Fatal().Err(err).Msg("Database schema is not ready")
+	} else if done {
+		return
+	}
+
+	eventQueue := queue.NewMemoryQueue()
+	defer eventQueue.Close()
+
+	eventRepo := repositories.NewEventRepository(database, dedup.NewMemoryStore(), eventQueue, log)
+
+	router, err := app.NewRouter(cfg, app.EventsCORS(cfg)...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid trusted proxies")
+	}
+	app.SetupEventsAPI(router, cfg, app.EventsAPI{
+		DB:       database,
+		Sessions: app.NewSessions(database, log),
+		Events:   eventRepo,
+		Audit:    service.NewAuditService(repositories.NewAuditRepository(database, log), log),
+	}, log)
+
+	processing, err := app.NewProcessing(cfg, database, eventRepo, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up the processor")
+	}
+	defer processing.Close()
+
+	app.ServeDebug(cfg.Server.DebugAddr, log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	processed := make(chan struct{})
+	go func() {
+		defer close(processed)
+		processing.Run(ctx)
+	}()
+
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.EventsPort)
+	srv := &http.Server{Addr: addr, Handler: router}
+	go func() {
+		log.Info().Str("address", addr).Msg("Starting HTTP server")
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("Failed to start server")
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info().Msg("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("HTTP server shutdown failed")
+	}
+	<-processed
+
+	if n := eventQueue.Len(); n > 0 {
+		log.Warn().Int("events", n).Msg("Unprocessed events discarded")
+	}
+	log.Info().Msg("Development server exited")
+}
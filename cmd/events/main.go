@@ -3,21 +3,24 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/Vighnesh-V-H/sync/internal/app"
 	"github.com/Vighnesh-V-H/sync/internal/config"
 	"github.com/Vighnesh-V-H/sync/internal/db"
-	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/grpcserver"
 	"github.com/Vighnesh-V-H/sync/internal/logger"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
 	"github.com/Vighnesh-V-H/sync/internal/repositories"
-	"github.com/Vighnesh-V-H/sync/internal/routes"
 	"github.com/Vighnesh-V-H/sync/internal/service"
-	"github.com/gin-gonic/gin"
+	"github.com/Vighnesh-V-H/sync/internal/wal"
 	_ "github.com/joho/godotenv/autoload"
-	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -34,6 +37,12 @@ func main() {
 		ServiceName: cfg.Observability.ServiceName,
 		Environment: cfg.Primary.Env,
 		IsProd:      cfg.Primary.Env == "prod",
+		Redaction: logger.RedactionFromFields(
+			*cfg.Logging.Redact,
+			cfg.Logging.RedactFields,
+			cfg.Logging.RedactPayloadFields,
+			cfg.Logging.RedactSalt,
+		),
 	}
 	if cfg.Logging.Pretty {
 		logCfg.Format = "console"
@@ -46,47 +55,80 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to initialize database")
 	}
 	defer database.Close()
+	database.Guard = db.NewPostgresGuard(cfg.Resilience.ResilienceOptions())
 
-	opt, err := redis.ParseURL(cfg.Redis.URL)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to parse Upstash Redis URL")
+	if done, err := db.PrepareSchema(context.Background(), database, os.Args[1:], cfg.Database.AutoMigrate, log); err != nil {
+		log.Fatal().Err(err).Msg("Database schema is not ready")
+	} else if done {
+		return
 	}
-	opt.DialTimeout = time.Duration(cfg.Redis.Timeout) * time.Second
-	opt.ReadTimeout = time.Duration(cfg.Redis.Timeout) * time.Second
-	opt.WriteTimeout = time.Duration(cfg.Redis.Timeout) * time.Second
-
-	redisClient := redis.NewClient(opt)
-	defer redisClient.Close()
 
-	// Old method (commented out):
-	// redisClient := redis.NewClient(&redis.Options{
-	// 	Addr:         cfg.Redis.URL,
-	// 	Password:     cfg.Redis.Password,
-	// 	DB:           cfg.Redis.DB,
-	// 	DialTimeout:  time.Duration(cfg.Redis.Timeout) * time.Second,
-	// 	ReadTimeout:  time.Duration(cfg.Redis.Timeout) * time.Second,
-	// 	WriteTimeout: time.Duration(cfg.Redis.Timeout) * time.Second,
-	// })
-
-	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer pingCancel()
-	if err := redisClient.Ping(pingCtx).Err(); err != nil {
+	redisClient, err := db.NewRedis(cfg.Redis.URL, time.Duration(cfg.Redis.Timeout)*time.Second, log)
+	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to Redis")
 	}
-	log.Info().Msg("Successfully connected to Redis")
-
-	eventRepo := repositories.NewEventRepository(database, redisClient, log)
-	eventSvc := service.NewEventService(eventRepo, log)
-	eventHandler := handler.NewEventHandler(eventSvc, log)
-
-	if cfg.Primary.Env == "prod" {
-		gin.SetMode(gin.ReleaseMode)
+	defer redisClient.Close()
+	db.GuardRedis(redisClient, cfg.Resilience.ResilienceOptions().Breaker)
+
+	usageSvc := service.NewUsageService(repositories.NewUsageRepository(database, redisClient, log), app.UsageQuotas(cfg), log)
+
+	eventQueue, err := queue.Open(queue.Options{
+		Backend: cfg.Queue.Backend,
+		Redis:   redisClient,
+		Kafka: queue.KafkaOptions{
+			Brokers: cfg.Queue.KafkaBrokers,
+			Topic:   cfg.Queue.KafkaTopic,
+		},
+	}, log)
+	if err != nil {
+		log.Fatal().Err(err).Str("backend", cfg.Queue.Backend).Msg("Failed to open event queue")
 	}
 
-	router := gin.Default()
-	api := router.Group("/api/v1")
+	spillLog, err := wal.Open(cfg.Queue.WALDir, wal.Options{
+		SegmentBytes: cfg.Queue.WALSegmentBytes,
+		MaxBytes:     cfg.Queue.WALMaxBytes,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", cfg.Queue.WALDir).Msg("Failed to open queue WAL")
+	}
+	spillQueue := queue.NewSpillQueue(eventQueue, spillLog, time.Duration(cfg.Queue.WALReplayInterval)*time.Second, log)
+	defer spillQueue.Close()
+
+	replayCtx, stopReplay := context.WithCancel(context.Background())
+	defer stopReplay()
+	go spillQueue.Run(replayCtx)
+
+	eventRepo := repositories.NewEventRepository(database, dedup.NewRedisStore(redisClient), spillQueue, log)
+	importSvc := service.NewImportService(eventRepo, repositories.NewImportRepository(redisClient, log), usageSvc, cfg.App.BatchSize, log)
+
+	var limiter *ratelimit.Limiter
+	var grpcLimit *grpcserver.RateLimit
+	if *cfg.RateLimit.Enabled {
+		limiter = ratelimit.NewLimiter(redisClient, log)
+		rule := app.EventsRateLimit(cfg)
+		grpcLimit = &grpcserver.RateLimit{
+			Limiter: limiter,
+			Name:    rule.Name,
+			Limit:   rule.Limit,
+			Window:  rule.Window,
+			Logger:  log,
+		}
+	}
 
-	routes.SetupEventRoutes(api, eventHandler, cfg.JWT.Secret)
+	router, err := app.NewRouter(cfg, app.EventsCORS(cfg)...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid trusted proxies")
+	}
+	sessions := app.NewSessions(database, log)
+	eventSvc := app.SetupEventsAPI(router, cfg, app.EventsAPI{
+		DB:       database,
+		Sessions: sessions,
+		Events:   eventRepo,
+		Audit:    service.NewAuditService(repositories.NewAuditRepository(database, log), log),
+		Usage:    usageSvc,
+		Imports:  importSvc,
+		Limiter:  limiter,
+	}, log)
 
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.EventsPort)
 	log.Info().Str("address", addr).Msg("Starting HTTP server")
@@ -97,16 +139,43 @@ func main() {
 		}
 	}()
 
+	grpcServer := grpcserver.NewServer(
+		grpcserver.NewIngestServer(eventSvc, cfg.App.BatchSize, log),
+		cfg.JWT.Secret,
+		sessions,
+		int(cfg.Server.MaxDecompressedBytes),
+		grpcLimit,
+	)
+	grpcAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.GRPCPort)
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatal().Err(err).Str("address", grpcAddr).Msg("Failed to listen for gRPC")
+	}
+	log.Info().Str("address", grpcAddr).Msg("Starting gRPC server")
+
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatal().Err(err).Msg("Failed to start gRPC server")
+		}
+	}()
+
+	// Internal metrics, including the queue WAL's.
+	app.ServeDebug(cfg.Server.DebugAddr, log)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info().Msg("Shutting down server...")
 
+	grpcServer.GracefulStop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_ = ctx
+	if err := importSvc.Shutdown(ctx); err != nil {
+		log.Warn().Err(err).Msg("Import jobs did not stop in time")
+	}
 
 	log.Info().Msg("Server exited")
 }
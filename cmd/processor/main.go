@@ -1 +1,97 @@
 package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/app"
+	"github.com/Vighnesh-V-H/sync/internal/config"
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/logger"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	logCfg := logger.Config{
+		Level:       cfg.Logging.Level,
+		Format:      "json",
+		ServiceName: cfg.Observability.ServiceName,
+		Environment: cfg.Primary.Env,
+		IsProd:      cfg.Primary.Env == "prod",
+		Redaction: logger.RedactionFromFields(
+			*cfg.Logging.Redact,
+			cfg.Logging.RedactFields,
+			cfg.Logging.RedactPayloadFields,
+			cfg.Logging.RedactSalt,
+		),
+	}
+	if cfg.Logging.Pretty {
+		logCfg.Format = "console"
+	}
+	log := logger.New(logCfg)
+	log.Info().Msg("Starting processor service")
+
+	database, err := db.NewDB(cfg.Database.URL, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize database")
+	}
+	defer database.Close()
+	database.Guard = db.NewPostgresGuard(cfg.Resilience.ResilienceOptions())
+
+	if done, err := db.PrepareSchema(context.Background(), database, os.Args[1:], cfg.Database.AutoMigrate, log); err != nil {
+		log.Fatal().Err(err).Msg("Database schema is not ready")
+	} else if done {
+		return
+	}
+
+	redisClient, err := db.NewRedis(cfg.Redis.URL, time.Duration(cfg.Redis.Timeout)*time.Second, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to Redis")
+	}
+	defer redisClient.Close()
+	db.GuardRedis(redisClient, cfg.Resilience.ResilienceOptions().Breaker)
+
+	eventQueue, err := queue.Open(queue.Options{
+		Backend: cfg.Queue.Backend,
+		Redis:   redisClient,
+		Kafka: queue.KafkaOptions{
+			Brokers: cfg.Queue.KafkaBrokers,
+			Topic:   cfg.Queue.KafkaTopic,
+			Group:   cfg.Queue.KafkaGroup,
+		},
+	}, log)
+	if err != nil {
+		log.Fatal().Err(err).Str("backend", cfg.Queue.Backend).Msg("Failed to open event queue")
+	}
+	defer eventQueue.Close()
+
+	eventRepo := repositories.NewEventRepository(database, dedup.NewRedisStore(redisClient), eventQueue, log)
+	processing, err := app.NewProcessing(cfg, database, eventRepo, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up the processor")
+	}
+	defer processing.Close()
+
+	app.ServeDebug(cfg.Server.DebugAddr, log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	processing.Run(ctx)
+
+	log.Info().Msg("Processor exited")
+}
@@ -0,0 +1,40 @@
+// Package app builds the services' components from config. cmd/auth,
+// cmd/events, cmd/processor and cmd/dev share it, so a component is wired
+// the same way in every binary.
+package app
+
+import (
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/config"
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/Vighnesh-V-H/sync/internal/routes"
+	"github.com/gin-gonic/gin"
+)
+
+// NewRouter returns a gin engine with what every service shares: trusted
+// proxies, request IDs, the given CORS policies and the health routes.
+func NewRouter(cfg *config.Config, cors ...middleware.CORSPolicy) (*gin.Engine, error) {
+	if cfg.Primary.Env == "prod" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+	router.Use(middleware.RequestIDMiddleware())
+	if len(cors) > 0 {
+		router.Use(middleware.CORSMiddleware(cors...))
+	}
+	// Every service needs Postgres; Redis only backs features that fail
+	// open, so it does not make a service unready.
+	routes.SetupHealthRoutes(router, handler.NewHealthHandler(db.PostgresDependency))
+	return router, nil
+}
+
+func corsMaxAge(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.Server.CORSMaxAge) * time.Second
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/config"
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/Vighnesh-V-H/sync/internal/oidc"
+	"github.com/Vighnesh-V-H/sync/internal/password"
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/routes"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// AuthCORS is the auth service's CORS policy: only management origins.
+func AuthCORS(cfg *config.Config) middleware.CORSPolicy {
+	return middleware.ManagementCORSPolicy("/api/v1", cfg.Server.CORSManagementOrigins, corsMaxAge(cfg))
+}
+
+// AuthAPI is what the auth API is built from. Limiter may be nil, which
+// disables rate limiting of signups and signins; Breached may be nil, which
+// disables the breached password check.
+//
+// There is no email provider yet, so email changes, which must be confirmed
+// from the new address, are only available in dev, where the confirmation
+// token is logged instead of sent.
+type AuthAPI struct {
+	DB       *db.DB
+	Audit    *service.AuditService
+	Limiter  *ratelimit.Limiter
+	Breached *password.BreachedList
+}
+
+// SetupAuthAPI registers the auth service's routes, including OIDC login
+// when providers are configured, on router and returns the AuthService.
+func SetupAuthAPI(router gin.IRouter, cfg *config.Config, deps AuthAPI, log zerolog.Logger) *service.AuthService {
+	jwtCfg := utils.JWTConfig{
+		Secret: cfg.JWT.Secret,
+		Expiry: time.Hour * 24 * 7,
+	}
+	policy := &password.Policy{
+		MinLength: cfg.Password.MinLength,
+		MaxLength: cfg.Password.MaxLength,
+		Breached:  deps.Breached,
+	}
+	hasher := password.NewHasher(password.Params{
+		Memory:      cfg.Password.Argon2Memory,
+		Iterations:  cfg.Password.Argon2Iterations,
+		Parallelism: cfg.Password.Argon2Parallelism,
+	})
+
+	var mailer service.Mailer
+	if cfg.Primary.Env == "dev" {
+		mailer = logMailer{logger: log}
+	}
+
+	authRepo := repositories.NewAuthRepository(deps.DB, log)
+	authSvc := service.NewAuthService(authRepo, jwtCfg, policy, hasher,
+		time.Duration(cfg.Account.ErasureDelay)*time.Hour, mailer, log)
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret, NewSessions(deps.DB, log))
+
+	var authMiddleware []gin.HandlerFunc
+	if deps.Limiter != nil {
+		authMiddleware = append(authMiddleware, middleware.RateLimitMiddleware(deps.Limiter, middleware.RateLimitRule{
+			Name:   "auth",
+			Limit:  cfg.RateLimit.AuthLimit,
+			Window: time.Duration(cfg.RateLimit.AuthWindow) * time.Second,
+		}, log))
+	}
+
+	api := router.Group("/api/v1")
+	routes.SetupAuthRoutes(api, handler.NewAuthHandler(authSvc, log), auth, deps.Audit, authMiddleware...)
+
+	if len(cfg.OIDC.ProviderConfigs) > 0 {
+		providers := make(map[string]oidc.ProviderConfig, len(cfg.OIDC.ProviderConfigs))
+		for name, p := range cfg.OIDC.ProviderConfigs {
+			providers[name] = oidc.ProviderConfig{
+				Issuer:       p.Issuer,
+				ClientID:     p.ClientID,
+				ClientSecret: p.ClientSecret,
+				Scopes:       p.Scopes,
+			}
+		}
+		oidcClient := oidc.NewClient(providers, cfg.OIDC.RedirectBaseURL, cfg.JWT.Secret)
+		oidcHandler := handler.NewOIDCHandler(oidcClient, authSvc, cfg.Primary.Env != "dev", log)
+		routes.SetupOIDCRoutes(api, oidcHandler, deps.Audit, authMiddleware...)
+		log.Info().Strs("providers", oidcClient.Providers()).Msg("OIDC login enabled")
+	}
+
+	return authSvc
+}
+
+// NewSessions returns the check that tokens have not been revoked, for the
+// auth middleware and gRPC interceptors.
+func NewSessions(database *db.DB, log zerolog.Logger) *service.SessionService {
+	return service.NewSessionService(repositories.NewAuthRepository(database, log), log)
+}
+
+// logMailer stands in for an email provider in development.
+type logMailer struct {
+	logger zerolog.Logger
+}
+
+func (m logMailer) SendEmailConfirmation(_ context.Context, to string, token string) error {
+	m.logger.Info().
+		Str("to", to).
+		Str("token", token).
+		Msg("Email confirmation not sent, no email provider in dev")
+	return nil
+}
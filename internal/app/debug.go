@@ -0,0 +1,23 @@
+package app
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// ServeDebug serves internal metrics as expvar JSON at /debug/vars on addr,
+// a listener of its own so they are never exposed on a public port. It
+// returns at once; a listener that fails is logged, not fatal.
+func ServeDebug(addr string, log zerolog.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	go func() {
+		log.Info().Str("address", addr).Msg("Starting debug server")
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Error().Err(err).Msg("Debug server stopped")
+		}
+	}()
+}
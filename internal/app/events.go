@@ -0,0 +1,107 @@
+package app
+
+import (
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/config"
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/routes"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// EventsCORS returns the events service's CORS policies: ingestion paths
+// accept any configured site, the rest of the API only management origins.
+func EventsCORS(cfg *config.Config) []middleware.CORSPolicy {
+	origins, management, maxAge := cfg.Server.CORSAllowedOrigins, cfg.Server.CORSManagementOrigins, corsMaxAge(cfg)
+	return []middleware.CORSPolicy{
+		middleware.ManagementCORSPolicy("/api/v1/event/import", management, maxAge),
+		middleware.IngestionCORSPolicy("/api/v1/event", origins, maxAge),
+		middleware.IngestionCORSPolicy("/api/v1/track", origins, maxAge),
+		middleware.IngestionCORSPolicy("/api/v1/identify", origins, maxAge),
+		middleware.IngestionCORSPolicy("/api/v1/alias", origins, maxAge),
+		middleware.IngestionCORSPolicy("/v1", origins, maxAge),
+		middleware.ManagementCORSPolicy("/api/v1", management, maxAge),
+	}
+}
+
+// EventsAPI is what the events API is built from. Usage, Imports and
+// Limiter keep their state in Redis and may be nil, as in cmd/dev: usage
+// quotas and their routes, bulk imports and rate limiting are then disabled.
+// Sessions, from NewSessions, is shared with the gRPC server.
+type EventsAPI struct {
+	DB       *db.DB
+	Sessions *service.SessionService
+	Events   *repositories.EventRepository
+	Audit    *service.AuditService
+	Usage    *service.UsageService
+	Imports  *service.ImportService
+	Limiter  *ratelimit.Limiter
+}
+
+// SetupEventsAPI registers the events service's routes on router and
+// returns the EventService, which the gRPC server shares.
+func SetupEventsAPI(router gin.IRouter, cfg *config.Config, deps EventsAPI, log zerolog.Logger) *service.EventService {
+	database := deps.DB
+	eventSvc := service.NewEventService(deps.Events, deps.Usage, log)
+	writeKeySvc := service.NewWriteKeyService(repositories.NewWriteKeyRepository(database, log), log)
+	identitySvc := service.NewIdentityService(repositories.NewIdentityRepository(database, log), log)
+	enrichmentSvc := service.NewEnrichmentService(repositories.NewEnrichmentRepository(database, log), log)
+	ruleSvc := service.NewRuleService(repositories.NewRuleRepository(database, log), log)
+	webhookSvc := service.NewWebhookService(repositories.NewWebhookRepository(database, log), cfg.Webhook.AllowPrivateDestinations, log)
+
+	eventMiddleware := []gin.HandlerFunc{
+		middleware.DecompressMiddleware(cfg.Server.MaxBodyBytes, cfg.Server.MaxDecompressedBytes, log),
+	}
+	importMiddleware := []gin.HandlerFunc{
+		middleware.DecompressMiddleware(cfg.Server.MaxImportBytes, cfg.Server.MaxImportBytes, log),
+	}
+	if deps.Limiter != nil {
+		eventsLimit := middleware.RateLimitMiddleware(deps.Limiter, EventsRateLimit(cfg), log)
+		eventMiddleware = append(eventMiddleware, eventsLimit)
+		importMiddleware = append(importMiddleware, eventsLimit)
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret, deps.Sessions)
+	api := router.Group("/api/v1")
+	routes.SetupEventRoutes(api, handler.NewEventHandler(eventSvc, log), auth, eventMiddleware...)
+	if deps.Imports != nil {
+		routes.SetupImportRoutes(api, handler.NewImportHandler(deps.Imports, log), auth, deps.Audit, importMiddleware...)
+	}
+	routes.SetupIdentityRoutes(api, handler.NewIdentityHandler(identitySvc, eventSvc, log), auth, eventMiddleware...)
+	routes.SetupEnrichmentRoutes(api, handler.NewEnrichmentHandler(enrichmentSvc, log), auth, deps.Audit)
+	routes.SetupRuleRoutes(api, handler.NewRuleHandler(ruleSvc, log), auth, deps.Audit)
+	routes.SetupWebhookRoutes(api, handler.NewWebhookHandler(webhookSvc, log), auth, deps.Audit)
+	if deps.Usage != nil {
+		routes.SetupUsageRoutes(api, handler.NewUsageHandler(deps.Usage, log), auth)
+	}
+	routes.SetupWriteKeyRoutes(api, handler.NewWriteKeyHandler(writeKeySvc, log), auth, deps.Audit)
+	routes.SetupAuditRoutes(api, handler.NewAuditHandler(deps.Audit, log), auth, deps.Audit)
+	routes.SetupTrackRoutes(api, handler.NewTrackHandler(writeKeySvc, eventSvc, log), eventMiddleware...)
+	routes.SetupSegmentRoutes(router, handler.NewSegmentHandler(writeKeySvc, eventSvc, log), eventMiddleware...)
+
+	return eventSvc
+}
+
+// EventsRateLimit is the per-tenant limit on ingestion, over HTTP and gRPC.
+func EventsRateLimit(cfg *config.Config) middleware.RateLimitRule {
+	return middleware.RateLimitRule{
+		Name:   "events",
+		Limit:  cfg.RateLimit.EventsLimit,
+		Window: time.Duration(cfg.RateLimit.EventsWindow) * time.Second,
+	}
+}
+
+// UsageQuotas maps each plan to its monthly event quota.
+func UsageQuotas(cfg *config.Config) map[string]int64 {
+	return map[string]int64{
+		"free":       *cfg.RateLimit.FreeMonthlyEvents,
+		"pro":        *cfg.RateLimit.ProMonthlyEvents,
+		"enterprise": *cfg.RateLimit.EnterpriseMonthlyEvents,
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/config"
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/enrichment"
+	"github.com/Vighnesh-V-H/sync/internal/erasure"
+	"github.com/Vighnesh-V-H/sync/internal/processor"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/Vighnesh-V-H/sync/internal/webhook"
+	"github.com/rs/zerolog"
+)
+
+// Processing is the processor together with the workers that run beside
+// it: webhook delivery, account erasure and, if configured, audit log
+// retention.
+type Processing struct {
+	Processor *processor.Processor
+	workers   []func(context.Context) error
+	geoip     *enrichment.GeoIPEnricher
+	logger    zerolog.Logger
+}
+
+// NewProcessing builds a processor that drains events and stores into
+// database.
+func NewProcessing(cfg *config.Config, database *db.DB, events *repositories.EventRepository, log zerolog.Logger) (*Processing, error) {
+	enrichers := enrichment.NewRegistry(log)
+	var geoip *enrichment.GeoIPEnricher
+	if cfg.Enrichment.GeoIPDatabase != "" {
+		var err error
+		geoip, err = enrichment.NewGeoIPEnricher(cfg.Enrichment.GeoIPDatabase)
+		if err != nil {
+			return nil, err
+		}
+		enrichers.Register(geoip)
+	} else {
+		log.Warn().Msg("No GeoIP database configured, geoip enrichment disabled")
+	}
+	enrichers.Register(enrichment.NewUserAgentEnricher())
+	enrichers.Register(enrichment.NewUTMEnricher())
+	enrichers.Register(enrichment.NewStaticPropertiesEnricher())
+
+	webhookRepo := repositories.NewWebhookRepository(database, log)
+	webhookWorker := webhook.NewWorker(webhookRepo, nil, webhook.Config{
+		MaxAttempts:              cfg.Webhook.MaxAttempts,
+		Timeout:                  time.Duration(cfg.Webhook.Timeout) * time.Second,
+		Concurrency:              cfg.Webhook.Concurrency,
+		PollInterval:             time.Second,
+		BreakerThreshold:         cfg.Webhook.BreakerThreshold,
+		BreakerCooldown:          time.Duration(cfg.Webhook.BreakerCooldown) * time.Second,
+		AllowPrivateDestinations: cfg.Webhook.AllowPrivateDestinations,
+	}, log)
+	erasureWorker := erasure.NewWorker(repositories.NewErasureRepository(database, log), time.Minute, log)
+
+	proc := processor.New(processor.Deps{
+		Events:     events,
+		Store:      events,
+		Identity:   service.NewIdentityService(repositories.NewIdentityRepository(database, log), log),
+		Enrichment: service.NewEnrichmentService(repositories.NewEnrichmentRepository(database, log), log),
+		Enrichers:  enrichers,
+		Rules:      service.NewRuleService(repositories.NewRuleRepository(database, log), log),
+		Webhooks:   service.NewWebhookService(webhookRepo, cfg.Webhook.AllowPrivateDestinations, log),
+	}, log)
+
+	workers := []func(context.Context) error{proc.Run, webhookWorker.Run, erasureWorker.Run}
+	if cfg.Audit.RetentionDays > 0 {
+		auditSvc := service.NewAuditService(repositories.NewAuditRepository(database, log), log)
+		retention := time.Duration(cfg.Audit.RetentionDays) * 24 * time.Hour
+		workers = append(workers, func(ctx context.Context) error {
+			return auditSvc.RunRetention(ctx, retention, time.Hour)
+		})
+	}
+
+	return &Processing{
+		Processor: proc,
+		workers:   workers,
+		geoip:     geoip,
+		logger:    log,
+	}, nil
+}
+
+// Run runs the processor and its workers until ctx is cancelled and all of
+// them have stopped.
+func (p *Processing) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, run := range p.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := run(ctx); err != nil {
+				p.logger.Error().Err(err).Msg("Background worker exited with error")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Close releases the GeoIP database, once Run has returned.
+func (p *Processing) Close() error {
+	if p.geoip == nil {
+		return nil
+	}
+	return p.geoip.Close()
+}
@@ -3,8 +3,11 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
+	"github.com/Vighnesh-V-H/sync/internal/resilience"
 	"github.com/go-playground/validator/v10"
+	"github.com/go-viper/mapstructure/v2"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/v2"
@@ -20,6 +23,15 @@ type Config struct {
 	Logging       LoggingConfig        `koanf:"logging" validate:"required"`
 	App           AppConfig            `koanf:"app" validate:"required"`
 	JWT           JWTConfig            `koanf:"jwt" validate:"required"`
+	RateLimit     RateLimitConfig      `koanf:"ratelimit"`
+	Enrichment    EnrichmentConfig     `koanf:"enrichment"`
+	Webhook       WebhookConfig        `koanf:"webhook"`
+	Account       AccountConfig        `koanf:"account"`
+	Audit         AuditConfig          `koanf:"audit"`
+	Password      PasswordConfig       `koanf:"password"`
+	OIDC          OIDCConfig           `koanf:"oidc"`
+	Queue         QueueConfig          `koanf:"queue"`
+	Resilience    ResilienceConfig     `koanf:"resilience"`
 	Observability *ObservabilityConfig `koanf:"observability"`
 }
 
@@ -36,10 +48,29 @@ type ServerConfig struct {
 	Port               int      `koanf:"port" validate:"required,min=1,max=65535"`
 	AuthPort           int      `koanf:"auth_port" validate:"required,min=1,max=65535"`
 	EventsPort         int      `koanf:"events_port" validate:"required,min=1,max=65535"`
+	GRPCPort           int      `koanf:"grpc_port" validate:"omitempty,min=1,max=65535"`
 	ReadTimeout        int      `koanf:"read_timeout" validate:"required,min=1"`
 	WriteTimeout       int      `koanf:"write_timeout" validate:"required,min=1"`
 	IdleTimeout        int      `koanf:"idle_timeout" validate:"required,min=1"`
 	CORSAllowedOrigins []string `koanf:"cors_allowed_origins" validate:"required,dive,http_url"`
+	// CORSManagementOrigins are the dashboard origins allowed to call the
+	// authenticated management routes, with credentials; none by default.
+	// "*" is rejected.
+	CORSManagementOrigins []string `koanf:"cors_management_origins" validate:"dive,ne=*"`
+	CORSMaxAge            int      `koanf:"cors_max_age" validate:"omitempty,min=0"`
+	// Ingestion body limits in bytes, before and after Content-Encoding is decoded.
+	MaxBodyBytes         int64 `koanf:"max_body_bytes" validate:"omitempty,min=1"`
+	MaxDecompressedBytes int64 `koanf:"max_decompressed_bytes" validate:"omitempty,min=1"`
+	MaxImportBytes       int64 `koanf:"max_import_bytes" validate:"omitempty,min=1"`
+	// TrustedProxies lists the proxy addresses or CIDRs whose
+	// X-Forwarded-For is believed when resolving the client IP. With none,
+	// the client IP is always the peer address.
+	TrustedProxies []string `koanf:"trusted_proxies" validate:"omitempty,dive,cidr|ip"`
+	// DebugAddr is the separate listener internal metrics, including every
+	// circuit breaker's, are served on at /debug/vars. It defaults to
+	// loopback so they are never public; give each binary its own when
+	// several run on one host.
+	DebugAddr string `koanf:"debug_addr"`
 }
 
 type RedisConfig struct {
@@ -61,11 +92,18 @@ type ClickHouseConfig struct {
 
 type DatabaseConfig struct {
 	URL string `koanf:"url" validate:"required"`
+	// AutoMigrate applies pending migrations on startup; without it a
+	// service refuses to start on an outdated schema.
+	AutoMigrate bool `koanf:"auto_migrate"`
 }
 
 type LoggingConfig struct {
-	Level  string `koanf:"level" validate:"required,oneof=debug info warn error fatal panic"`
-	Pretty bool   `koanf:"pretty"`
+	Level               string   `koanf:"level" validate:"required,oneof=debug info warn error fatal panic"`
+	Pretty              bool     `koanf:"pretty"`
+	Redact              *bool    `koanf:"redact"`
+	RedactFields        []string `koanf:"redact_fields"`
+	RedactPayloadFields []string `koanf:"redact_payload_fields"`
+	RedactSalt          string   `koanf:"redact_salt"`
 }
 
 type AppConfig struct {
@@ -74,6 +112,119 @@ type AppConfig struct {
 	BatchSize     int    `koanf:"batch_size" validate:"required,min=10,max=10000"`
 }
 
+type RateLimitConfig struct {
+	Enabled      *bool `koanf:"enabled"`
+	AuthLimit    int   `koanf:"auth_limit" validate:"omitempty,min=1"`
+	AuthWindow   int   `koanf:"auth_window" validate:"omitempty,min=1"`
+	EventsLimit  int   `koanf:"events_limit" validate:"omitempty,min=1"`
+	EventsWindow int   `koanf:"events_window" validate:"omitempty,min=1"`
+	// Monthly event quotas per plan; 0 means unlimited. Unset quotas take
+	// the plan's default.
+	FreeMonthlyEvents       *int64 `koanf:"free_monthly_events" validate:"omitempty,min=0"`
+	ProMonthlyEvents        *int64 `koanf:"pro_monthly_events" validate:"omitempty,min=0"`
+	EnterpriseMonthlyEvents *int64 `koanf:"enterprise_monthly_events" validate:"omitempty,min=0"`
+}
+
+type EnrichmentConfig struct {
+	// GeoIPDatabase is the path to a MaxMind City .mmdb file; geoip enrichment
+	// is disabled without one.
+	GeoIPDatabase string `koanf:"geoip_database"`
+}
+
+type QueueConfig struct {
+	// Backend is redis or kafka; cmd/dev always uses an in-memory queue.
+	Backend      string   `koanf:"backend" validate:"omitempty,oneof=redis kafka"`
+	KafkaBrokers []string `koanf:"kafka_brokers"`
+	KafkaTopic   string   `koanf:"kafka_topic"`
+	// KafkaGroup is the processor's consumer group.
+	KafkaGroup string `koanf:"kafka_group"`
+	// The events service spills to a WAL in WALDir while the backend is
+	// unreachable.
+	WALDir          string `koanf:"wal_dir"`
+	WALSegmentBytes int64  `koanf:"wal_segment_bytes" validate:"omitempty,min=1"`
+	WALMaxBytes     int64  `koanf:"wal_max_bytes" validate:"omitempty,min=0"`
+	// WALReplayInterval is in seconds.
+	WALReplayInterval int `koanf:"wal_replay_interval" validate:"omitempty,min=1"`
+}
+
+// OIDCConfig enables login through OpenID Connect providers. Env variables
+// only nest one level, so each provider listed in SYNC_OIDC_PROVIDERS is read
+// from flat keys: SYNC_OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET and
+// optionally _SCOPES.
+type OIDCConfig struct {
+	Providers []string `koanf:"providers"`
+	// RedirectBaseURL is the auth service's public URL; providers redirect to
+	// <RedirectBaseURL>/api/v1/auth/oidc/<name>/callback.
+	RedirectBaseURL string `koanf:"redirect_base_url" validate:"required_with=Providers,omitempty,url"`
+	// ProviderConfigs is filled in from the flat keys by LoadConfig.
+	ProviderConfigs map[string]OIDCProviderConfig `koanf:"-"`
+}
+
+type OIDCProviderConfig struct {
+	Issuer       string   `validate:"required,url"`
+	ClientID     string   `validate:"required"`
+	ClientSecret string   `validate:"required"`
+	Scopes       []string `validate:"omitempty"`
+}
+
+type AccountConfig struct {
+	// ErasureDelay is how many hours after an account is deleted its data is
+	// erased.
+	ErasureDelay int `koanf:"erasure_delay" validate:"omitempty,min=1"`
+}
+
+type AuditConfig struct {
+	// RetentionDays is how long audit log entries are kept; 0 keeps them
+	// forever.
+	RetentionDays int `koanf:"retention_days" validate:"omitempty,min=1"`
+}
+
+type PasswordConfig struct {
+	MinLength int `koanf:"min_length" validate:"omitempty,min=1"`
+	MaxLength int `koanf:"max_length" validate:"omitempty,min=1"`
+	// BreachedList is the path to the Pwned Passwords SHA-1 list, either a
+	// single sorted file or a directory of per-prefix files; passwords are
+	// not checked against breaches without one.
+	BreachedList string `koanf:"breached_list"`
+	// Argon2id hashing parameters; memory is in KiB. Users whose stored hash
+	// uses other parameters are rehashed when they next sign in. The bounds
+	// keep hashes from being too cheap to crack or so costly that a burst of
+	// signins exhausts the auth service: every signin holds Argon2Memory
+	// while it hashes. Stored hashes above the maximums are rejected.
+	Argon2Memory      uint32 `koanf:"argon2_memory" validate:"min=7168,max=1048576"`
+	Argon2Iterations  uint32 `koanf:"argon2_iterations" validate:"min=1,max=10"`
+	Argon2Parallelism uint8  `koanf:"argon2_parallelism" validate:"min=1,max=16"`
+}
+
+type WebhookConfig struct {
+	MaxAttempts int `koanf:"max_attempts" validate:"omitempty,min=1"`
+	// Timeout is per delivery attempt, in seconds.
+	Timeout     int `koanf:"timeout" validate:"omitempty,min=1"`
+	Concurrency int `koanf:"concurrency" validate:"omitempty,min=1"`
+	// After BreakerThreshold consecutive failures an endpoint is skipped for
+	// BreakerCooldown seconds.
+	BreakerThreshold int `koanf:"breaker_threshold" validate:"omitempty,min=1"`
+	BreakerCooldown  int `koanf:"breaker_cooldown" validate:"omitempty,min=1"`
+	// AllowPrivateDestinations lets webhooks target loopback and private
+	// addresses. Only enable it for local development.
+	AllowPrivateDestinations bool `koanf:"allow_private_destinations"`
+}
+
+// ResilienceConfig applies to each backing service (Postgres, Redis)
+// separately: every one gets its own breaker.
+type ResilienceConfig struct {
+	// RetryAttempts includes the first attempt; delays are in milliseconds.
+	RetryAttempts  int `koanf:"retry_attempts" validate:"omitempty,min=1"`
+	RetryBaseDelay int `koanf:"retry_base_delay" validate:"omitempty,min=1"`
+	RetryMaxDelay  int `koanf:"retry_max_delay" validate:"omitempty,min=1"`
+	// After BreakerThreshold consecutive failures calls fail fast with 503
+	// for BreakerCooldown seconds.
+	BreakerThreshold int `koanf:"breaker_threshold" validate:"omitempty,min=1"`
+	BreakerCooldown  int `koanf:"breaker_cooldown" validate:"omitempty,min=1"`
+	// CallTimeout bounds each Postgres attempt, in milliseconds; 0 disables it.
+	CallTimeout int `koanf:"call_timeout" validate:"omitempty,min=0"`
+}
+
 type ObservabilityConfig struct {
 	ServiceName    string `koanf:"service_name" validate:"required"`
 	Environment    string `koanf:"environment" validate:"required,oneof=dev staging prod"`
@@ -106,7 +257,16 @@ func LoadConfig() (*Config, error) {
 	}
 
 	mainConfig := &Config{}
-	err = k.Unmarshal("", mainConfig)
+	err = k.UnmarshalWithConf("", mainConfig, koanf.UnmarshalConf{
+		DecoderConfig: &mapstructure.DecoderConfig{
+			// Comma-separated env values (SYNC_LOGGING_REDACT_FIELDS=a,b) decode into slices.
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToSliceHookFunc(","),
+			),
+			WeaklyTypedInput: true,
+		},
+	})
 	if err != nil {
 		tempLogger.Fatal().Err(err).Msg("could not unmarshal main config")
 	}
@@ -134,6 +294,9 @@ func LoadConfig() (*Config, error) {
 	if mainConfig.Server.EventsPort == 0 {
 		mainConfig.Server.EventsPort = 8083
 	}
+	if mainConfig.Server.GRPCPort == 0 {
+		mainConfig.Server.GRPCPort = 9083
+	}
 	if mainConfig.Server.ReadTimeout == 0 {
 		mainConfig.Server.ReadTimeout = 10
 	}
@@ -146,6 +309,28 @@ func LoadConfig() (*Config, error) {
 	if mainConfig.Server.CORSAllowedOrigins == nil {
 		mainConfig.Server.CORSAllowedOrigins = []string{"*"}
 	}
+	for _, origin := range mainConfig.Server.CORSManagementOrigins {
+		// Management routes allow credentials, which must never be granted
+		// to every origin.
+		if strings.TrimSpace(origin) == "*" {
+			tempLogger.Fatal().Msg("cors_management_origins cannot contain \"*\"")
+		}
+	}
+	if mainConfig.Server.CORSMaxAge == 0 {
+		mainConfig.Server.CORSMaxAge = 600
+	}
+	if mainConfig.Server.MaxBodyBytes == 0 {
+		mainConfig.Server.MaxBodyBytes = 1 << 20
+	}
+	if mainConfig.Server.MaxDecompressedBytes == 0 {
+		mainConfig.Server.MaxDecompressedBytes = 10 << 20
+	}
+	if mainConfig.Server.DebugAddr == "" {
+		mainConfig.Server.DebugAddr = "127.0.0.1:6060"
+	}
+	if mainConfig.Server.MaxImportBytes == 0 {
+		mainConfig.Server.MaxImportBytes = 1 << 30
+	}
 	// Redis DB default (commented out for Upstash URL mode)
 	// if mainConfig.Redis.DB == 0 {
 	// 	mainConfig.Redis.DB = 0
@@ -159,9 +344,102 @@ func LoadConfig() (*Config, error) {
 	if mainConfig.ClickHouse.ConnMaxLifetime == 0 {
 		mainConfig.ClickHouse.ConnMaxLifetime = 3600
 	}
+	if mainConfig.Queue.Backend == "" {
+		mainConfig.Queue.Backend = "redis"
+	}
+	if mainConfig.Queue.KafkaTopic == "" {
+		mainConfig.Queue.KafkaTopic = "sync.events"
+	}
+	if mainConfig.Queue.KafkaGroup == "" {
+		mainConfig.Queue.KafkaGroup = "sync-processor"
+	}
+	if mainConfig.Queue.WALDir == "" {
+		mainConfig.Queue.WALDir = "data/wal"
+	}
+	if mainConfig.Queue.WALSegmentBytes == 0 {
+		mainConfig.Queue.WALSegmentBytes = 16 << 20
+	}
+	if mainConfig.Queue.WALMaxBytes == 0 {
+		mainConfig.Queue.WALMaxBytes = 1 << 30
+	}
+	if mainConfig.Queue.WALReplayInterval == 0 {
+		mainConfig.Queue.WALReplayInterval = 1
+	}
+	if mainConfig.Resilience.RetryAttempts == 0 {
+		mainConfig.Resilience.RetryAttempts = 3
+	}
+	if mainConfig.Resilience.RetryBaseDelay == 0 {
+		mainConfig.Resilience.RetryBaseDelay = 50
+	}
+	if mainConfig.Resilience.RetryMaxDelay == 0 {
+		mainConfig.Resilience.RetryMaxDelay = 1000
+	}
+	if mainConfig.Resilience.BreakerThreshold == 0 {
+		mainConfig.Resilience.BreakerThreshold = 5
+	}
+	if mainConfig.Resilience.BreakerCooldown == 0 {
+		mainConfig.Resilience.BreakerCooldown = 10
+	}
+	if mainConfig.Webhook.MaxAttempts == 0 {
+		mainConfig.Webhook.MaxAttempts = 10
+	}
+	if mainConfig.Webhook.Timeout == 0 {
+		mainConfig.Webhook.Timeout = 10
+	}
+	if mainConfig.Webhook.Concurrency == 0 {
+		mainConfig.Webhook.Concurrency = 8
+	}
+	if mainConfig.Webhook.BreakerThreshold == 0 {
+		mainConfig.Webhook.BreakerThreshold = 5
+	}
+	if mainConfig.Webhook.BreakerCooldown == 0 {
+		mainConfig.Webhook.BreakerCooldown = 60
+	}
+	mainConfig.OIDC.ProviderConfigs = make(map[string]OIDCProviderConfig, len(mainConfig.OIDC.Providers))
+	for _, name := range mainConfig.OIDC.Providers {
+		name = strings.ToLower(strings.TrimSpace(name))
+		prefix := "oidc." + name + "_"
+		provider := OIDCProviderConfig{
+			Issuer:       k.String(prefix + "issuer"),
+			ClientID:     k.String(prefix + "client_id"),
+			ClientSecret: k.String(prefix + "client_secret"),
+		}
+		if scopes := k.String(prefix + "scopes"); scopes != "" {
+			provider.Scopes = strings.Split(scopes, ",")
+		}
+		if err := validator.New().Struct(provider); err != nil {
+			tempLogger.Fatal().Err(err).Str("provider", name).Msg("invalid OIDC provider config")
+		}
+		mainConfig.OIDC.ProviderConfigs[name] = provider
+	}
+	if mainConfig.Account.ErasureDelay == 0 {
+		mainConfig.Account.ErasureDelay = 72
+	}
+	if mainConfig.Password.MinLength == 0 {
+		mainConfig.Password.MinLength = 8
+	}
+	if mainConfig.Password.MaxLength == 0 {
+		mainConfig.Password.MaxLength = 128
+	}
+	if mainConfig.Password.Argon2Memory == 0 {
+		mainConfig.Password.Argon2Memory = 19 * 1024
+	}
+	if mainConfig.Password.Argon2Iterations == 0 {
+		mainConfig.Password.Argon2Iterations = 2
+	}
+	if mainConfig.Password.Argon2Parallelism == 0 {
+		mainConfig.Password.Argon2Parallelism = 1
+	}
+	if err := validator.New().Struct(mainConfig.Password); err != nil {
+		tempLogger.Fatal().Err(err).Msg("invalid password config")
+	}
 	if mainConfig.Logging.Level == "" {
 		mainConfig.Logging.Level = "info"
 	}
+	if mainConfig.Logging.Redact == nil {
+		redact := mainConfig.Primary.Env != "dev"
+		mainConfig.Logging.Redact = &redact
+	}
 	if mainConfig.App.TenantDefault == "" {
 		mainConfig.App.TenantDefault = "default"
 	}
@@ -171,6 +449,51 @@ func LoadConfig() (*Config, error) {
 	if mainConfig.App.BatchSize == 0 {
 		mainConfig.App.BatchSize = 100
 	}
+	if mainConfig.RateLimit.Enabled == nil {
+		enabled := true
+		mainConfig.RateLimit.Enabled = &enabled
+	}
+	if mainConfig.RateLimit.AuthLimit == 0 {
+		mainConfig.RateLimit.AuthLimit = 10
+	}
+	if mainConfig.RateLimit.AuthWindow == 0 {
+		mainConfig.RateLimit.AuthWindow = 60
+	}
+	if mainConfig.RateLimit.EventsLimit == 0 {
+		mainConfig.RateLimit.EventsLimit = 1000
+	}
+	if mainConfig.RateLimit.EventsWindow == 0 {
+		mainConfig.RateLimit.EventsWindow = 1
+	}
+	if mainConfig.RateLimit.FreeMonthlyEvents == nil {
+		free := int64(100000)
+		mainConfig.RateLimit.FreeMonthlyEvents = &free
+	}
+	if mainConfig.RateLimit.ProMonthlyEvents == nil {
+		pro := int64(10000000)
+		mainConfig.RateLimit.ProMonthlyEvents = &pro
+	}
+	if mainConfig.RateLimit.EnterpriseMonthlyEvents == nil {
+		enterprise := int64(0)
+		mainConfig.RateLimit.EnterpriseMonthlyEvents = &enterprise
+	}
 
 	return mainConfig, nil
 }
+
+// ResilienceOptions converts the config into the options shared by every
+// dependency; the db package adds per-dependency failure classification.
+func (c ResilienceConfig) ResilienceOptions() resilience.Options {
+	return resilience.Options{
+		Breaker: resilience.BreakerOptions{
+			Threshold: c.BreakerThreshold,
+			Cooldown:  time.Duration(c.BreakerCooldown) * time.Second,
+		},
+		Retry: resilience.RetryPolicy{
+			MaxAttempts: c.RetryAttempts,
+			BaseDelay:   time.Duration(c.RetryBaseDelay) * time.Millisecond,
+			MaxDelay:    time.Duration(c.RetryMaxDelay) * time.Millisecond,
+		},
+		Timeout: time.Duration(c.CallTimeout) * time.Millisecond,
+	}
+}
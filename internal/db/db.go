@@ -2,8 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/Vighnesh-V-H/sync/internal/resilience"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 )
@@ -11,6 +15,9 @@ import (
 type DB struct {
 	Pool   *pgxpool.Pool
 	Logger zerolog.Logger
+	// Guard is the Postgres circuit breaker and retry policy. Repositories
+	// route calls through it; it may be nil.
+	Guard *resilience.Dependency
 }
 
 func NewDB(databaseURL string, logger zerolog.Logger) (*DB, error) {
@@ -50,3 +57,42 @@ func (db *DB) Close() {
 func (db *DB) GetPool() *pgxpool.Pool {
 	return db.Pool
 }
+
+// PostgresDependency names Postgres's breaker.
+const PostgresDependency = "postgres"
+
+// NewPostgresGuard returns the PostgresDependency for DB.Guard. Only errors
+// that suggest the server is unreachable or overloaded trip its breaker or
+// are retried; missing rows and constraint violations are not.
+func NewPostgresGuard(opts resilience.Options) *resilience.Dependency {
+	opts.Breaker.IsFailure = IsUnavailable
+	opts.Retry.Retryable = IsUnavailable
+	return resilience.NewDependency(PostgresDependency, opts)
+}
+
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, pgx.ErrNoRows) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection exceptions, 53 insufficient resources and
+		// 57 operator intervention (e.g. admin shutdown).
+		switch pgErr.Code[:2] {
+		case "08", "53", "57":
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// UniqueViolation returns the name of the unique constraint or index err
+// violated, or "" if err is not a unique violation.
+func UniqueViolation(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"github.com/rs/zerolog"
+)
+
+// The schema ships inside every binary. The files stay plain goose SQL, so
+// `goose -dir ./internal/db/migrations` keeps working against the same
+// goose_db_version table.
+//
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+var ErrSchemaBehind = errors.New("database schema is behind; run `migrate up` or set SYNC_DATABASE_AUTO_MIGRATE=true")
+
+// Migrator applies the embedded migrations. Commands that change the schema
+// hold a Postgres advisory lock, so several instances starting at once
+// migrate one at a time and the rest find nothing left to do.
+type Migrator struct {
+	sqlDB    *sql.DB
+	provider *goose.Provider
+	log      zerolog.Logger
+}
+
+func NewMigrator(db *DB, logger zerolog.Logger) (*Migrator, error) {
+	migrations, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB := stdlib.OpenDBFromPool(db.Pool)
+	provider, err := goose.NewProvider(goose.DialectPostgres, sqlDB, migrations,
+		goose.WithSessionLocker(locker),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	return &Migrator{
+		sqlDB:    sqlDB,
+		provider: provider,
+		log:      logger.With().Str("component", "migrate").Logger(),
+	}, nil
+}
+
+// Close releases the migrator's connections; the pool itself stays open.
+func (m *Migrator) Close() error {
+	return m.sqlDB.Close()
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	m.logResults(results)
+	return err
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	m.logResults([]*goose.MigrationResult{result})
+	return err
+}
+
+// Redo rolls back the most recent migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) error {
+	if err := m.Down(ctx); err != nil {
+		return err
+	}
+	result, err := m.provider.UpByOne(ctx)
+	m.logResults([]*goose.MigrationResult{result})
+	return err
+}
+
+// Status writes one line per migration to w.
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		applied := "pending"
+		if s.State == goose.StateApplied {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%-20s %s\n", applied, s.Source.Path)
+	}
+	return nil
+}
+
+// Check returns ErrSchemaBehind if any embedded migration has not been
+// applied yet.
+func (m *Migrator) Check(ctx context.Context) error {
+	current, target, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return fmt.Errorf("%w (at version %d, binary expects %d)", ErrSchemaBehind, current, target)
+	}
+	return nil
+}
+
+// Prepare is run by every service on startup: with autoMigrate it applies
+// pending migrations, otherwise it refuses to continue on an old schema.
+func (m *Migrator) Prepare(ctx context.Context, autoMigrate bool) error {
+	if autoMigrate {
+		return m.Up(ctx)
+	}
+	return m.Check(ctx)
+}
+
+// PrepareSchema is called by every binary right after connecting. If args
+// (os.Args[1:]) are `migrate [up|down|status|redo]` it runs that command and
+// returns done=true so the binary exits; otherwise it prepares the schema
+// for serving.
+func PrepareSchema(ctx context.Context, db *DB, args []string, autoMigrate bool, logger zerolog.Logger) (done bool, err error) {
+	m, err := NewMigrator(db, logger)
+	if err != nil {
+		return false, err
+	}
+	defer m.Close()
+
+	if len(args) > 0 && args[0] == "migrate" {
+		command := "up"
+		if len(args) > 1 {
+			command = args[1]
+		}
+		return true, m.Run(ctx, command, os.Stdout)
+	}
+	return false, m.Prepare(ctx, autoMigrate)
+}
+
+// Run executes a `migrate` subcommand: up, down, status or redo.
+func (m *Migrator) Run(ctx context.Context, command string, w io.Writer) error {
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "redo":
+		return m.Redo(ctx)
+	case "status":
+		return m.Status(ctx, w)
+	default:
+		return fmt.Errorf("unknown migrate command %q (want up, down, status or redo)", command)
+	}
+}
+
+func (m *Migrator) logResults(results []*goose.MigrationResult) {
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		event := m.log.Info()
+		if r.Error != nil {
+			event = m.log.Error().Err(r.Error)
+		}
+		event.
+			Str("file", r.Source.Path).
+			Str("direction", r.Direction).
+			Dur("duration", r.Duration).
+			Msg("Migration finished")
+	}
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// TestEmbeddedMigrations checks every migration file is embedded and
+// understood by goose. No connection is made.
+func TestEmbeddedMigrations(t *testing.T) {
+	files, err := fs.Glob(embeddedMigrations, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	pool, err := pgxpool.New(t.Context(), "postgres://localhost:1/unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	m, err := NewMigrator(&DB{Pool: pool}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	defer m.Close()
+
+	if got := len(m.provider.ListSources()); got != len(files) {
+		t.Fatalf("goose found %d migrations, want %d", got, len(files))
+	}
+}
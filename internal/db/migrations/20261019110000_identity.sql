@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS persons (
+    id TEXT PRIMARY KEY,
+    api_key TEXT NOT NULL,
+    traits JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS persons_api_key_idx ON persons (api_key);
+
+CREATE TABLE IF NOT EXISTS identities (
+    api_key TEXT NOT NULL,
+    distinct_id TEXT NOT NULL,
+    person_id TEXT NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (api_key, distinct_id)
+);
+CREATE INDEX IF NOT EXISTS identities_person_id_idx ON identities (person_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS identities;
+DROP TABLE IF EXISTS persons;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS events (
+    id TEXT PRIMARY KEY,
+    api_key TEXT NOT NULL,
+    person_id TEXT,
+    distinct_id TEXT,
+    payload JSONB NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    processed_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS events_api_key_timestamp_idx ON events (api_key, timestamp);
+CREATE INDEX IF NOT EXISTS events_api_key_person_id_idx ON events (api_key, person_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS events;
+-- +goose StatementEnd
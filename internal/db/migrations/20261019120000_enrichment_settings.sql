@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS enrichment_settings (
+    api_key TEXT PRIMARY KEY,
+    enrichers TEXT[] NOT NULL DEFAULT '{}',
+    static_properties JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS enrichment_settings;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS rules (
+    id TEXT PRIMARY KEY,
+    api_key TEXT NOT NULL,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    condition TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    field TEXT NOT NULL DEFAULT '',
+    target TEXT NOT NULL DEFAULT '',
+    value TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS rules_api_key_position_idx ON rules (api_key, position);
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS routes TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events DROP COLUMN IF EXISTS routes;
+DROP TABLE IF EXISTS rules;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Emails are compared lower-cased and trimmed. Existing rows are normalized
+-- first; this fails if two accounts differ only in case, which must then be
+-- merged by hand.
+UPDATE users SET email = lower(btrim(email)) WHERE email <> lower(btrim(email));
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_normalized_key ON users ((lower(btrim(email))));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_email_normalized_key;
+-- +goose StatementEnd
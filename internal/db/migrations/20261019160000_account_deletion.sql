@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- An erasure request deletes everything stored under api_key once
+-- erase_after has passed, leaving a grace period for support to intervene.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    api_key TEXT NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    erase_after TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS erasure_requests_due_idx ON erasure_requests (erase_after) WHERE completed_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS erasure_requests;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- audit_log records security-relevant actions per account (api_key). It
+-- outlives account deletion and, being the record of who did what, cannot be
+-- changed: updates, deletes and truncation are rejected.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP NOT NULL,
+    api_key TEXT,
+    actor_id TEXT,
+    action TEXT NOT NULL,
+    target TEXT,
+    ip TEXT,
+    user_agent TEXT,
+    request_id TEXT,
+    outcome TEXT NOT NULL CHECK (outcome IN ('success', 'failure')),
+    status INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_log_api_key_idx ON audit_log (api_key, id DESC);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tokens carry the token_version they were issued at and are only accepted
+-- while it is current; bumping it revokes every token issued before.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+-- An email change is held here until the new address is confirmed.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS pending_email;
+ALTER TABLE users DROP COLUMN IF EXISTS token_version;
+-- +goose StatementEnd
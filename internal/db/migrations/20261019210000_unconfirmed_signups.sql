@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Signups used to be marked verified without confirming their email. Only
+-- accounts created or linked through an OIDC provider, which verified the
+-- address, keep the flag; the rest must confirm their email again.
+UPDATE users SET is_verified = false
+WHERE password IS NOT NULL AND password <> ''
+  AND NOT EXISTS (SELECT 1 FROM user_identities i WHERE i.user_id = users.id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- Accounts verified before cannot be told apart from those that never were,
+-- so nothing is restored.
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- mfa_challenge is the ID of the one MFA challenge that can still complete a
+-- signin; it is cleared once used. mfa_failures counts invalid codes since
+-- the last success, and reaching the limit sets mfa_locked_until.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_challenge TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_locked_until TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_failures;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_challenge;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Entries older than the retention period can be purged, but only through
+-- audit_log_purge: it marks its transaction with the cutoff, and deletes of
+-- anything else, like updates and truncation, are still rejected.
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+CREATE OR REPLACE FUNCTION audit_log_retention() RETURNS trigger AS $$
+BEGIN
+    IF COALESCE(current_setting('sync.audit_purge_before', true), '') <> ''
+        AND OLD.occurred_at < current_setting('sync.audit_purge_before')::timestamp THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_retention
+    BEFORE DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_retention();
+
+-- audit_log_purge deletes up to batch entries that occurred before cutoff
+-- and returns how many it deleted.
+CREATE OR REPLACE FUNCTION audit_log_purge(cutoff TIMESTAMP, batch INTEGER) RETURNS BIGINT AS $$
+DECLARE
+    purged BIGINT;
+BEGIN
+    PERFORM set_config('sync.audit_purge_before', cutoff::text, true);
+    DELETE FROM audit_log WHERE id IN (
+        SELECT id FROM audit_log WHERE occurred_at < cutoff ORDER BY occurred_at LIMIT batch
+    );
+    GET DIAGNOSTICS purged = ROW_COUNT;
+    PERFORM set_config('sync.audit_purge_before', '', true);
+    RETURN purged;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE INDEX IF NOT EXISTS audit_log_occurred_at_idx ON audit_log (occurred_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS audit_log_occurred_at_idx;
+DROP FUNCTION IF EXISTS audit_log_purge(TIMESTAMP, INTEGER);
+DROP TRIGGER IF EXISTS audit_log_retention ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_retention();
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/resilience"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func NewRedis(redisURL string, timeout time.Duration, logger zerolog.Logger) (*redis.Client, error) {
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+	}
+	opt.DialTimeout = timeout
+	opt.ReadTimeout = timeout
+	opt.WriteTimeout = timeout
+
+	client := redis.NewClient(opt)
+
+	pingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	logger.Info().Msg("Redis connection established")
+
+	return client, nil
+}
+
+// RedisDependency names Redis's breaker.
+const RedisDependency = "redis"
+
+// GuardRedis routes every command on client through the RedisDependency
+// circuit breaker, so all Redis users fail fast once Redis is known to be down
+// instead of each waiting out the client timeout. Commands are not retried:
+// many (INCR, SETNX) are not idempotent. redis.Nil and error replies from a
+// healthy server do not trip the breaker.
+func GuardRedis(client *redis.Client, opts resilience.BreakerOptions) *resilience.Breaker {
+	opts.IsFailure = IsRedisUnavailable
+	breaker := resilience.NewBreaker(RedisDependency, opts)
+	client.AddHook(redisGuard{breaker: breaker})
+	return breaker
+}
+
+func IsRedisUnavailable(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var replyErr redis.Error
+	return !errors.As(err, &replyErr)
+}
+
+type redisGuard struct {
+	breaker *resilience.Breaker
+}
+
+func (g redisGuard) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (g redisGuard) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := g.breaker.Do(ctx, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+		if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+			// Rejected by the breaker without running.
+			cmd.SetErr(err)
+		}
+		return err
+	}
+}
+
+func (g redisGuard) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := g.breaker.Do(ctx, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+		if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+		}
+		return err
+	}
+}
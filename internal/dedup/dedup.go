@@ -0,0 +1,19 @@
+// Package dedup remembers recently queued event IDs so a retried request
+// does not queue the same event twice.
+package dedup
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	// Claim marks id as seen for ttl and reports whether it was new.
+	Claim(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	// ClaimAll is Claim for several ids at once, reporting for each whether
+	// it was new.
+	ClaimAll(ctx context.Context, ttl time.Duration, ids ...string) ([]bool, error)
+	// Forget releases claimed ids, e.g. when queueing them failed, so a retry
+	// is not mistaken for a duplicate.
+	Forget(ctx context.Context, ids ...string) error
+}
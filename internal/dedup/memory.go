@@ -0,0 +1,70 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store for development and tests. Expired IDs
+// are swept lazily as new ones are added.
+type MemoryStore struct {
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		expiresAt: make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+func (s *MemoryStore) Claim(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	claimed, err := s.ClaimAll(ctx, ttl, id)
+	if err != nil {
+		return false, err
+	}
+	return claimed[0], nil
+}
+
+func (s *MemoryStore) ClaimAll(_ context.Context, ttl time.Duration, ids ...string) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	claimed := make([]bool, len(ids))
+	for i, id := range ids {
+		if exp, ok := s.expiresAt[id]; ok && now.Before(exp) {
+			continue
+		}
+		s.expiresAt[id] = now.Add(ttl)
+		claimed[i] = true
+	}
+	s.sweep(now)
+	return claimed, nil
+}
+
+func (s *MemoryStore) Forget(_ context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.expiresAt, id)
+	}
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for id, exp := range s.expiresAt {
+		if !now.Before(exp) {
+			delete(s.expiresAt, id)
+		}
+	}
+}
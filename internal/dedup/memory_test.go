@@ -0,0 +1,74 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreClaim(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if ok, _ := s.Claim(ctx, "a", time.Hour); !ok {
+		t.Fatal("first Claim should succeed")
+	}
+	if ok, _ := s.Claim(ctx, "a", time.Hour); ok {
+		t.Fatal("second Claim within the TTL should fail")
+	}
+
+	now = now.Add(time.Hour)
+	if ok, _ := s.Claim(ctx, "a", time.Hour); !ok {
+		t.Fatal("Claim after the TTL should succeed")
+	}
+}
+
+func TestMemoryStoreClaimAll(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	s.Claim(ctx, "a", time.Hour)
+	claimed, err := s.ClaimAll(ctx, time.Hour, "a", "b", "b")
+	if err != nil {
+		t.Fatalf("ClaimAll: %v", err)
+	}
+	if claimed[0] || !claimed[1] || claimed[2] {
+		t.Fatalf("ClaimAll = %v, want [false true false]", claimed)
+	}
+}
+
+func TestMemoryStoreForget(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	s.ClaimAll(ctx, time.Hour, "a", "b")
+	if err := s.Forget(ctx, "a"); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if ok, _ := s.Claim(ctx, "a", time.Hour); !ok {
+		t.Fatal("Claim after Forget should succeed")
+	}
+	if ok, _ := s.Claim(ctx, "b", time.Hour); ok {
+		t.Fatal("Claim of an ID not forgotten should fail")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.Claim(ctx, "old", time.Minute)
+	now = now.Add(2 * time.Minute)
+	s.Claim(ctx, "new", time.Minute)
+
+	if _, ok := s.expiresAt["old"]; ok {
+		t.Fatal("expired ID was not swept")
+	}
+	if _, ok := s.expiresAt["new"]; !ok {
+		t.Fatal("live ID was swept")
+	}
+}
@@ -0,0 +1,54 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps one "event_queued:<id>" key per event.
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Claim(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, redisKey(id), "1", ttl).Result()
+}
+
+func (s *RedisStore) ClaimAll(ctx context.Context, ttl time.Duration, ids ...string) ([]bool, error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.BoolCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.SetNX(ctx, redisKey(id), "1", ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	claimed := make([]bool, len(ids))
+	for i, cmd := range cmds {
+		claimed[i] = cmd.Val()
+	}
+	return claimed, nil
+}
+
+func (s *RedisStore) Forget(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = redisKey(id)
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func redisKey(id string) string {
+	return fmt.Sprintf("event_queued:%s", id)
+}
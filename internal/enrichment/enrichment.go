@@ -0,0 +1,83 @@
+// Package enrichment adds server-side facts to events in the processor
+// before they are stored.
+package enrichment
+
+import (
+	"context"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Names of the built-in enrichers, as used in per-project settings.
+const (
+	GeoIP            = "geoip"
+	UserAgent        = "user_agent"
+	UTM              = "utm"
+	StaticProperties = "static_properties"
+)
+
+// Builtin lists the enrichers shipped with sync. New projects get all of them.
+var Builtin = []string{GeoIP, UserAgent, UTM, StaticProperties}
+
+// Enricher adds properties to an event's payload. Implementations must not
+// overwrite values the client sent.
+type Enricher interface {
+	Name() string
+	Enrich(ctx context.Context, ev *models.Event, settings *models.EnrichmentSettings) error
+}
+
+// Registry runs enrichers in registration order.
+type Registry struct {
+	enrichers []Enricher
+	logger    zerolog.Logger
+}
+
+func NewRegistry(logger zerolog.Logger, enrichers ...Enricher) *Registry {
+	return &Registry{
+		enrichers: enrichers,
+		logger:    logger.With().Str("component", "enrichment").Logger(),
+	}
+}
+
+func (r *Registry) Register(e Enricher) {
+	r.enrichers = append(r.enrichers, e)
+}
+
+// Names lists the registered enrichers.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.enrichers))
+	for _, e := range r.enrichers {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// Apply runs every enricher enabled in settings. A failing enricher is logged
+// and skipped; enrichment never stops an event from being stored.
+func (r *Registry) Apply(ctx context.Context, ev *models.Event, settings *models.EnrichmentSettings) {
+	for _, e := range r.enrichers {
+		if !settings.IsEnabled(e.Name()) {
+			continue
+		}
+		if err := e.Enrich(ctx, ev, settings); err != nil {
+			r.logger.Warn().Err(err).
+				Str("enricher", e.Name()).
+				Str("event_id", ev.ID).
+				Msg("Enrichment failed")
+		}
+	}
+}
+
+// setDefault sets payload[key] unless the client already sent it.
+func setDefault(payload map[string]any, key string, value any) {
+	if _, exists := payload[key]; !exists {
+		payload[key] = value
+	}
+}
+
+// payloadContext returns the Segment-style "context" object of a payload, if any.
+func payloadContext(payload map[string]any) map[string]any {
+	ctx, _ := payload["context"].(map[string]any)
+	return ctx
+}
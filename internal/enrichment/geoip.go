@@ -0,0 +1,66 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// GeoIPEnricher resolves the client IP against a local MaxMind City database
+// (GeoLite2-City or GeoIP2-City) and adds a "$geo" object.
+type GeoIPEnricher struct {
+	reader *geoip2.Reader
+}
+
+func NewGeoIPEnricher(path string) (*GeoIPEnricher, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	return &GeoIPEnricher{reader: reader}, nil
+}
+
+func (e *GeoIPEnricher) Name() string { return GeoIP }
+
+func (e *GeoIPEnricher) Close() error {
+	return e.reader.Close()
+}
+
+func (e *GeoIPEnricher) Enrich(_ context.Context, ev *models.Event, _ *models.EnrichmentSettings) error {
+	ip := net.ParseIP(ev.Context.IP)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() {
+		return nil
+	}
+
+	record, err := e.reader.City(ip)
+	if err != nil {
+		return err
+	}
+	if record.Country.IsoCode == "" {
+		return nil
+	}
+
+	geo := map[string]any{
+		"country_code": record.Country.IsoCode,
+		"country":      record.Country.Names["en"],
+		"continent":    record.Continent.Code,
+		"latitude":     record.Location.Latitude,
+		"longitude":    record.Location.Longitude,
+	}
+	if name := record.City.Names["en"]; name != "" {
+		geo["city"] = name
+	}
+	if len(record.Subdivisions) > 0 {
+		geo["region"] = record.Subdivisions[0].Names["en"]
+		geo["region_code"] = record.Subdivisions[0].IsoCode
+	}
+	if record.Location.TimeZone != "" {
+		geo["timezone"] = record.Location.TimeZone
+	}
+
+	setDefault(ev.Payload, "$geo", geo)
+	return nil
+}
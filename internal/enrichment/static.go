@@ -0,0 +1,24 @@
+package enrichment
+
+import (
+	"context"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+)
+
+// StaticPropertiesEnricher adds the project's configured static properties,
+// such as an environment or app name, to every event.
+type StaticPropertiesEnricher struct{}
+
+func NewStaticPropertiesEnricher() *StaticPropertiesEnricher {
+	return &StaticPropertiesEnricher{}
+}
+
+func (e *StaticPropertiesEnricher) Name() string { return StaticProperties }
+
+func (e *StaticPropertiesEnricher) Enrich(_ context.Context, ev *models.Event, settings *models.EnrichmentSettings) error {
+	for key, value := range settings.StaticProperties {
+		setDefault(ev.Payload, key, value)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package enrichment
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/mssola/useragent"
+)
+
+// UserAgentEnricher parses the client's User-Agent into a "$device" object
+// with browser, OS and device type.
+type UserAgentEnricher struct{}
+
+func NewUserAgentEnricher() *UserAgentEnricher {
+	return &UserAgentEnricher{}
+}
+
+func (e *UserAgentEnricher) Name() string { return UserAgent }
+
+func (e *UserAgentEnricher) Enrich(_ context.Context, ev *models.Event, _ *models.EnrichmentSettings) error {
+	if ev.Context.UserAgent == "" {
+		return nil
+	}
+
+	ua := useragent.New(ev.Context.UserAgent)
+	browser, browserVersion := ua.Browser()
+	os := ua.OSInfo()
+
+	setDefault(ev.Payload, "$device", map[string]any{
+		"browser":         browser,
+		"browser_version": browserVersion,
+		"os":              os.Name,
+		"os_version":      os.Version,
+		"device_type":     deviceType(ua),
+	})
+	return nil
+}
+
+func deviceType(ua *useragent.UserAgent) string {
+	switch {
+	case ua.Bot():
+		return "bot"
+	case strings.Contains(ua.UA(), "iPad") || strings.Contains(ua.UA(), "Tablet"):
+		return "tablet"
+	case ua.Mobile():
+		return "mobile"
+	default:
+		return "desktop"
+	}
+}
@@ -0,0 +1,61 @@
+package enrichment
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+)
+
+var utmParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
+// UTMEnricher extracts campaign parameters from the page URL into "$utm" and
+// the referring host into "$referring_domain". The URL and referrer are read
+// from the payload's url/referrer fields or, for Segment messages, from
+// context.page.
+type UTMEnricher struct{}
+
+func NewUTMEnricher() *UTMEnricher {
+	return &UTMEnricher{}
+}
+
+func (e *UTMEnricher) Name() string { return UTM }
+
+func (e *UTMEnricher) Enrich(_ context.Context, ev *models.Event, _ *models.EnrichmentSettings) error {
+	pageURL, referrer := pageURLs(ev.Payload)
+
+	if u, err := url.Parse(pageURL); err == nil && pageURL != "" {
+		query := u.Query()
+		utm := map[string]any{}
+		for _, param := range utmParams {
+			if v := query.Get(param); v != "" {
+				utm[strings.TrimPrefix(param, "utm_")] = v
+			}
+		}
+		if len(utm) > 0 {
+			setDefault(ev.Payload, "$utm", utm)
+		}
+	}
+
+	if u, err := url.Parse(referrer); err == nil && u.Hostname() != "" {
+		setDefault(ev.Payload, "$referring_domain", strings.TrimPrefix(u.Hostname(), "www."))
+	}
+
+	return nil
+}
+
+func pageURLs(payload map[string]any) (string, string) {
+	pageURL, _ := payload["url"].(string)
+	referrer, _ := payload["referrer"].(string)
+
+	if page, ok := payloadContext(payload)["page"].(map[string]any); ok {
+		if pageURL == "" {
+			pageURL, _ = page["url"].(string)
+		}
+		if referrer == "" {
+			referrer, _ = page["referrer"].(string)
+		}
+	}
+	return pageURL, referrer
+}
@@ -0,0 +1,69 @@
+// Package erasure deletes the data of deleted accounts once their grace
+// period has passed.
+package erasure
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Store carries out erasure requests. *repositories.ErasureRepository
+// implements it.
+type Store interface {
+	// EraseNext erases the data of one due request and returns its api key,
+	// or "" if none is due.
+	EraseNext(ctx context.Context) (string, error)
+}
+
+// Worker polls for due erasure requests and carries them out. Several
+// workers may run against the same database.
+type Worker struct {
+	repo         Store
+	pollInterval time.Duration
+	logger       zerolog.Logger
+}
+
+func NewWorker(repo Store, pollInterval time.Duration, logger zerolog.Logger) *Worker {
+	return &Worker{
+		repo:         repo,
+		pollInterval: pollInterval,
+		logger:       logger.With().Str("component", "erasure_worker").Logger(),
+	}
+}
+
+// Run erases until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	w.logger.Info().Msg("Erasure worker started")
+
+	for {
+		if ctx.Err() != nil {
+			w.logger.Info().Msg("Erasure worker stopped")
+			return nil
+		}
+
+		apiKey, err := w.repo.EraseNext(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error().Err(err).Msg("Failed to erase account data")
+			}
+			sleep(ctx, w.pollInterval)
+			continue
+		}
+		if apiKey == "" {
+			sleep(ctx, w.pollInterval)
+			continue
+		}
+		w.logger.Info().Str("api_key", apiKey).Msg("Erased data of deleted account")
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
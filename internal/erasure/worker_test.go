@@ -0,0 +1,90 @@
+package erasure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type result struct {
+	apiKey string
+	err    error
+}
+
+// scriptedStore returns results in order, then reports nothing due and
+// closes idle.
+type scriptedStore struct {
+	mu      sync.Mutex
+	results []result
+	erased  []string
+	idle    chan struct{}
+}
+
+func newScriptedStore(results ...result) *scriptedStore {
+	return &scriptedStore{results: results, idle: make(chan struct{})}
+}
+
+func (s *scriptedStore) EraseNext(context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.results) == 0 {
+		select {
+		case <-s.idle:
+		default:
+			close(s.idle)
+		}
+		return "", nil
+	}
+	next := s.results[0]
+	s.results = s.results[1:]
+	if next.err == nil {
+		s.erased = append(s.erased, next.apiKey)
+	}
+	return next.apiKey, next.err
+}
+
+func runUntilIdle(t *testing.T, store *scriptedStore, pollInterval time.Duration) []string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewWorker(store, pollInterval, zerolog.Nop()).Run(ctx) }()
+
+	select {
+	case <-store.idle:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not get through the due requests")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.erased
+}
+
+func TestWorkerErasesDueRequestsBackToBack(t *testing.T) {
+	// An hour-long poll interval would time the test out if the worker
+	// waited between requests that were due.
+	store := newScriptedStore(result{apiKey: "key-1"}, result{apiKey: "key-2"})
+
+	erased := runUntilIdle(t, store, time.Hour)
+	if len(erased) != 2 || erased[0] != "key-1" || erased[1] != "key-2" {
+		t.Fatalf("erased %v, want key-1 and key-2", erased)
+	}
+}
+
+func TestWorkerRetriesAfterError(t *testing.T) {
+	store := newScriptedStore(result{err: errors.New("connection reset")}, result{apiKey: "key-1"})
+
+	erased := runUntilIdle(t, store, time.Millisecond)
+	if len(erased) != 1 || erased[0] != "key-1" {
+		t.Fatalf("erased %v, want key-1 after the failed attempt", erased)
+	}
+}
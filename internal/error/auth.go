@@ -6,4 +6,31 @@ var (
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrAPIKeyConflict means a newly generated api key is already taken;
+	// generate another and try again.
+	ErrAPIKeyConflict = errors.New("api key already in use")
+	// ErrEmailNotVerified rejects an OIDC login whose provider has not
+	// verified the email, which therefore cannot be matched to an account.
+	ErrEmailNotVerified = errors.New("email not verified by identity provider")
+	// ErrAccountNotVerified rejects an OIDC login matching an account whose
+	// email has not been confirmed, so is not known to belong to the same
+	// person.
+	ErrAccountNotVerified = errors.New("an account with this email exists but its email is not confirmed; sign in with its password and confirm it first")
+	// ErrInvalidEmailConfirmation rejects a confirmation token that is
+	// invalid, expired or for an email change since superseded.
+	ErrInvalidEmailConfirmation = errors.New("invalid or expired email confirmation")
+	// ErrEmailChangeUnavailable means no mailer is configured to confirm a
+	// new address with.
+	ErrEmailChangeUnavailable = errors.New("email changes are not available")
+
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidMFACode      = errors.New("invalid two-factor code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired two-factor challenge")
+	// ErrMFALocked rejects two-factor codes for an account that has had too
+	// many invalid ones recently.
+	ErrMFALocked = errors.New("too many invalid two-factor codes, try again later")
+
+	// ErrWeakPassword is wrapped with the rule a new password broke.
+	ErrWeakPassword = errors.New("password does not meet the password policy")
 )
@@ -0,0 +1,5 @@
+package errors
+
+import "errors"
+
+var ErrUnknownEnricher = errors.New("unknown enricher")
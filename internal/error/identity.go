@@ -0,0 +1,5 @@
+package errors
+
+import "errors"
+
+var ErrPersonNotFound = errors.New("person not found")
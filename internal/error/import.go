@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrUnsupportedImportFormat = errors.New("unsupported import format")
+	ErrImportNotFound          = errors.New("import not found")
+	ErrImportsStopped          = errors.New("imports are not being accepted while the service shuts down")
+)
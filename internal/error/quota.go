@@ -0,0 +1,7 @@
+package errors
+
+import "errors"
+
+var (
+	ErrQuotaExceeded = errors.New("monthly event quota exceeded")
+)
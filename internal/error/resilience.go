@@ -0,0 +1,7 @@
+package errors
+
+import "errors"
+
+// ErrDependencyUnavailable is returned without calling a dependency whose
+// circuit breaker is open.
+var ErrDependencyUnavailable = errors.New("service temporarily unavailable")
@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrInvalidWriteKey  = errors.New("invalid write key")
+	ErrWriteKeyNotFound = errors.New("write key not found")
+	ErrOriginNotAllowed = errors.New("origin not allowed for write key")
+)
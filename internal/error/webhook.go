@@ -0,0 +1,11 @@
+package errors
+
+import "errors"
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrWebhookDestination means a webhook URL points at a loopback,
+	// private, link-local or otherwise internal address.
+	ErrWebhookDestination = errors.New("webhook destination is not a public address")
+)
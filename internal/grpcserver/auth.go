@@ -0,0 +1,83 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type apiKeyContextKey struct{}
+
+// APIKeyFromContext returns the api key set by the auth interceptors.
+func APIKeyFromContext(ctx context.Context) (string, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey{}).(string)
+	return apiKey, ok && apiKey != ""
+}
+
+// UnaryAuthInterceptor applies the same token check as
+// middleware.AuthMiddleware to unary calls, reading the token from the
+// "authorization" metadata.
+func UnaryAuthInterceptor(secret string, sessions utils.Sessions) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, secret, sessions)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAuthInterceptor(secret string, sessions utils.Sessions) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), secret, sessions)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, secret string, sessions utils.Sessions) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata missing")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata missing")
+	}
+
+	tokenString, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization format, expected 'Bearer <token>'")
+	}
+
+	claims, err := utils.Authenticate(ctx, tokenString, secret, sessions)
+	switch {
+	case errors.Is(err, utils.ErrInvalidToken), errors.Is(err, utils.ErrMissingAPIKey):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, internalErrors.ErrDependencyUnavailable):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, "failed to check token")
+	}
+
+	return context.WithValue(ctx, apiKeyContextKey{}, claims.APIKey), nil
+}
@@ -0,0 +1,88 @@
+package grpcserver
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errRateLimited is returned by a rate limited stream's RecvMsg.
+var errRateLimited = status.Error(codes.ResourceExhausted, "Rate limit exceeded")
+
+// RateLimit is the gRPC counterpart of middleware.RateLimitMiddleware. It
+// limits per api key in the same Redis buckets as the HTTP rule with the
+// same Name, so switching transports does not raise a client's limit.
+type RateLimit struct {
+	Limiter *ratelimit.Limiter
+	Name    string
+	Limit   int
+	Window  time.Duration
+	Logger  zerolog.Logger
+}
+
+// allow reports whether apiKey may make another call and, if not, when to
+// retry. If Redis is unavailable the call is let through rather than failing
+// every call.
+func (r *RateLimit) allow(ctx context.Context, apiKey string) (bool, time.Duration) {
+	res, err := r.Limiter.Allow(ctx, ratelimit.Key(r.Name, "key:"+apiKey), r.Limit, r.Window)
+	if err != nil {
+		r.Logger.Warn().Err(err).Str("rule", r.Name).Msg("Rate limiter unavailable, allowing call")
+		return true, 0
+	}
+	if !res.Allowed {
+		r.Logger.Warn().Str("rule", r.Name).Str("api_key", apiKey).Msg("Rate limit exceeded")
+	}
+	return res.Allowed, res.Reset
+}
+
+func retryAfter(reset time.Duration) metadata.MD {
+	return metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+}
+
+// UnaryRateLimitInterceptor counts each unary call once, as the HTTP
+// middleware counts each request. It must run after the auth interceptor.
+func UnaryRateLimitInterceptor(limit *RateLimit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		apiKey, _ := APIKeyFromContext(ctx)
+		if ok, reset := limit.allow(ctx, apiKey); !ok {
+			_ = grpc.SetHeader(ctx, retryAfter(reset))
+			return nil, errRateLimited
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor counts every message received on a stream, so a
+// long-lived stream is held to the same rate as separate calls.
+func StreamRateLimitInterceptor(limit *RateLimit) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &rateLimitedStream{ServerStream: ss, limit: limit})
+	}
+}
+
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limit *RateLimit
+}
+
+// RecvMsg returns errRateLimited, dropping the message, once the client is
+// over its limit.
+func (s *rateLimitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	apiKey, _ := APIKeyFromContext(s.Context())
+	if ok, reset := s.limit.allow(s.Context(), apiKey); !ok {
+		s.SetTrailer(retryAfter(reset))
+		return errRateLimited
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	eventsv1 "github.com/Vighnesh-V-H/sync/proto/events/v1"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// defaultMaxStreamEvents bounds how many events one IngestStream call takes,
+// and so how many results it holds, before the server closes it.
+const defaultMaxStreamEvents = 10000
+
+// IngestServer implements eventsv1.IngestServiceServer on top of the same
+// EventService used by the REST handlers.
+type IngestServer struct {
+	eventsv1.UnimplementedIngestServiceServer
+
+	svc             *service.EventService
+	maxBatchSize    int
+	maxStreamEvents int
+	logger          zerolog.Logger
+}
+
+func NewIngestServer(svc *service.EventService, maxBatchSize int, logger zerolog.Logger) *IngestServer {
+	return &IngestServer{
+		svc:             svc,
+		maxBatchSize:    maxBatchSize,
+		maxStreamEvents: defaultMaxStreamEvents,
+		logger:          logger.With().Str("handler", "grpc_ingest").Logger(),
+	}
+}
+
+// NewServer builds a gRPC server with the auth interceptors, which check
+// tokens against sessions, and unless limit is nil the rate limit
+// interceptors installed, and the ingest service registered.
+func NewServer(ingest *IngestServer, secret string, sessions utils.Sessions, maxRecvBytes int, limit *RateLimit) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{UnaryAuthInterceptor(secret, sessions)}
+	stream := []grpc.StreamServerInterceptor{StreamAuthInterceptor(secret, sessions)}
+	if limit != nil {
+		unary = append(unary, UnaryRateLimitInterceptor(limit))
+		stream = append(stream, StreamRateLimitInterceptor(limit))
+	}
+
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(maxRecvBytes),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	eventsv1.RegisterIngestServiceServer(srv, ingest)
+	return srv
+}
+
+func (s *IngestServer) Ingest(ctx context.Context, req *eventsv1.IngestRequest) (*eventsv1.IngestResponse, error) {
+	apiKey, ok := APIKeyFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+
+	res, err := s.addEvent(ctx, apiKey, req.GetEvent())
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventsv1.IngestResponse{
+		Success: res.Success,
+		Message: res.Message,
+		EventId: res.EventID,
+	}, nil
+}
+
+func (s *IngestServer) IngestBatch(ctx context.Context, req *eventsv1.IngestBatchRequest) (*eventsv1.IngestBatchResponse, error) {
+	apiKey, ok := APIKeyFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+
+	if len(req.GetEvents()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "events are required")
+	}
+	if s.maxBatchSize > 0 && len(req.GetEvents()) > s.maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch exceeds %d events", s.maxBatchSize)
+	}
+
+	batch := &eventsv1.IngestBatchResponse{}
+	for i, ev := range req.GetEvents() {
+		s.record(batch, i, s.addEventResult(ctx, apiKey, ev))
+	}
+
+	s.logger.Info().
+		Str("api_key", apiKey).
+		Int32("accepted", batch.Accepted).
+		Int32("rejected", batch.Rejected).
+		Msg("Event batch ingested")
+
+	return batch, nil
+}
+
+func (s *IngestServer) IngestStream(stream grpc.ClientStreamingServer[eventsv1.IngestRequest, eventsv1.IngestBatchResponse]) error {
+	ctx := stream.Context()
+	apiKey, ok := APIKeyFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+
+	// The stream is closed early, with the results so far, once it reaches
+	// maxStreamEvents or the client is rate limited. Either way the client
+	// can tell from the results which events to resend.
+	batch := &eventsv1.IngestBatchResponse{}
+	closedEarly := false
+	for i := 0; ; i++ {
+		if i >= s.maxStreamEvents {
+			closedEarly = true
+			break
+		}
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errRateLimited) {
+			closedEarly = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+		s.record(batch, i, s.addEventResult(ctx, apiKey, req.GetEvent()))
+	}
+
+	s.logger.Info().
+		Str("api_key", apiKey).
+		Int32("accepted", batch.Accepted).
+		Int32("rejected", batch.Rejected).
+		Bool("closed_early", closedEarly).
+		Msg("Event stream ingested")
+
+	return stream.SendAndClose(batch)
+}
+
+func (s *IngestServer) addEvent(ctx context.Context, apiKey string, ev *eventsv1.Event) (*service.AddEventResponse, error) {
+	if ev.GetPayload() == nil {
+		return nil, status.Error(codes.InvalidArgument, "payload is required")
+	}
+
+	res, err := s.svc.AddEvent(ctx, apiKey, service.AddEventRequest{
+		Payload: ev.GetPayload().AsMap(),
+		Context: eventContext(ctx),
+	})
+	if errors.Is(err, internalErrors.ErrQuotaExceeded) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+		return nil, status.Error(codes.Unavailable, internalErrors.ErrDependencyUnavailable.Error())
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to add event")
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+
+	return res, nil
+}
+
+// eventContext is the gRPC counterpart of the REST handlers' client context:
+// the peer address and the user-agent header.
+func eventContext(ctx context.Context) models.EventContext {
+	var evCtx models.EventContext
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			evCtx.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			evCtx.UserAgent = ua[0]
+		}
+	}
+	return evCtx
+}
+
+func (s *IngestServer) addEventResult(ctx context.Context, apiKey string, ev *eventsv1.Event) *eventsv1.IngestResult {
+	res, err := s.addEvent(ctx, apiKey, ev)
+	if err != nil {
+		return &eventsv1.IngestResult{Error: status.Convert(err).Message()}
+	}
+	return &eventsv1.IngestResult{Accepted: true, EventId: res.EventID}
+}
+
+func (s *IngestServer) record(batch *eventsv1.IngestBatchResponse, index int, result *eventsv1.IngestResult) {
+	result.Index = int32(index)
+	batch.Results = append(batch.Results, result)
+	if result.Accepted {
+		batch.Accepted++
+	} else {
+		batch.Rejected++
+	}
+}
@@ -0,0 +1,150 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
+	"github.com/Vighnesh-V-H/sync/internal/repositories/repotest"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	eventsv1 "github.com/Vighnesh-V-H/sync/proto/events/v1"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const testSecret = "grpc-test-secret"
+
+// newTestClient serves ingest over an in-memory listener and returns a
+// client whose calls are authenticated as api key sync_key.
+func newTestClient(t *testing.T, ingest *IngestServer, limit *RateLimit) (eventsv1.IngestServiceClient, context.Context) {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(ingest, testSecret, nil, 1<<20, limit)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	token, err := utils.GenerateJWT("user-1", "a@example.com", "sync_key", 0, utils.JWTConfig{Secret: testSecret, Expiry: time.Hour})
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	return eventsv1.NewIngestServiceClient(conn), ctx
+}
+
+func newTestIngest() (*IngestServer, *repotest.EventStore) {
+	store := repotest.NewEventStore()
+	return NewIngestServer(service.NewEventService(store, nil, zerolog.Nop()), 100, zerolog.Nop()), store
+}
+
+func testEvent(t *testing.T) *eventsv1.Event {
+	t.Helper()
+	payload, err := structpb.NewStruct(map[string]any{"event": "signup"})
+	if err != nil {
+		t.Fatalf("NewStruct: %v", err)
+	}
+	return &eventsv1.Event{Payload: payload}
+}
+
+func newTestRateLimit(t *testing.T, limit int) *RateLimit {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &RateLimit{
+		Limiter: ratelimit.NewLimiter(client, zerolog.Nop()),
+		Name:    "events",
+		Limit:   limit,
+		Window:  time.Minute,
+		Logger:  zerolog.Nop(),
+	}
+}
+
+func TestIngestIsRateLimited(t *testing.T) {
+	ingest, _ := newTestIngest()
+	client, ctx := newTestClient(t, ingest, newTestRateLimit(t, 1))
+
+	if _, err := client.Ingest(ctx, &eventsv1.IngestRequest{Event: testEvent(t)}); err != nil {
+		t.Fatalf("first Ingest: %v", err)
+	}
+	var header metadata.MD
+	_, err := client.Ingest(ctx, &eventsv1.IngestRequest{Event: testEvent(t)}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("second Ingest = %v, want ResourceExhausted", err)
+	}
+	if len(header.Get("retry-after")) == 0 {
+		t.Fatalf("header = %v, want retry-after", header)
+	}
+}
+
+func TestIngestStreamClosesWhenRateLimited(t *testing.T) {
+	ingest, store := newTestIngest()
+	client, ctx := newTestClient(t, ingest, newTestRateLimit(t, 2))
+
+	stream, err := client.IngestStream(ctx)
+	if err != nil {
+		t.Fatalf("IngestStream: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		// Sends after the server has closed the stream fail with io.EOF.
+		if err := stream.Send(&eventsv1.IngestRequest{Event: testEvent(t)}); err != nil {
+			break
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv: %v", err)
+	}
+	if res.Accepted != 2 || len(res.Results) != 2 {
+		t.Fatalf("response = %+v, want 2 accepted results", res)
+	}
+	if n := len(store.Queued()); n != 2 {
+		t.Fatalf("queued %d events, want 2", n)
+	}
+}
+
+func TestIngestStreamIsCapped(t *testing.T) {
+	ingest, store := newTestIngest()
+	ingest.maxStreamEvents = 3
+	client, ctx := newTestClient(t, ingest, nil)
+
+	stream, err := client.IngestStream(ctx)
+	if err != nil {
+		t.Fatalf("IngestStream: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := stream.Send(&eventsv1.IngestRequest{Event: testEvent(t)}); err != nil {
+			break
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv: %v", err)
+	}
+	if res.Accepted != 3 || len(res.Results) != 3 {
+		t.Fatalf("response = %+v, want 3 accepted results", res)
+	}
+	if n := len(store.Queued()); n != 3 {
+		t.Fatalf("queued %d events, want 3", n)
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// The account routes run behind AuthMiddleware and act on the user the
+// token was issued to.
+
+func (h *AuthHandler) GetAccount(c *gin.Context) {
+	user, err := h.svc.Account(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		h.accountError(c, err, "Failed to fetch account")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AuthHandler) UpdateAccount(c *gin.Context) {
+	var req service.UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind account update request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.svc.UpdateAccount(c.Request.Context(), c.GetString("user_id"), req)
+	if err != nil {
+		h.accountError(c, err, "Failed to update account")
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req service.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind change password request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.ChangePassword(c.Request.Context(), c.GetString("user_id"), req); err != nil {
+		h.accountError(c, err, "Failed to change password")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ConfirmEmail is public: the token itself proves who the user is.
+func (h *AuthHandler) ConfirmEmail(c *gin.Context) {
+	var req service.ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.svc.ConfirmEmail(c.Request.Context(), req)
+	if err != nil {
+		h.accountError(c, err, "Failed to confirm email")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	var req service.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind delete account request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.DeleteAccount(c.Request.Context(), c.GetString("user_id"), req); err != nil {
+		h.accountError(c, err, "Failed to delete account")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) accountError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, internalErrors.ErrUserNotFound):
+		// Tokens outlive deleted accounts; treat them as no longer valid.
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account not found"})
+	case errors.Is(err, internalErrors.ErrInvalidCredentials):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+	case errors.Is(err, internalErrors.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, internalErrors.ErrUserAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+	case errors.Is(err, internalErrors.ErrInvalidEmailConfirmation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": internalErrors.ErrInvalidEmailConfirmation.Error()})
+	case errors.Is(err, internalErrors.ErrEmailChangeUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": internalErrors.ErrEmailChangeUnavailable.Error()})
+	case errors.Is(err, internalErrors.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": internalErrors.ErrInvalidMFACode.Error()})
+	case errors.Is(err, internalErrors.ErrInvalidMFAChallenge):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": internalErrors.ErrInvalidMFAChallenge.Error()})
+	case errors.Is(err, internalErrors.ErrMFALocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": internalErrors.ErrMFALocked.Error()})
+	case errors.Is(err, internalErrors.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": internalErrors.ErrMFAAlreadyEnabled.Error()})
+	case errors.Is(err, internalErrors.ErrMFANotEnrolled):
+		c.JSON(http.StatusBadRequest, gin.H{"error": internalErrors.ErrMFANotEnrolled.Error()})
+	case errors.Is(err, internalErrors.ErrDependencyUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+	default:
+		h.logger.Error().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg(msg)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+var auditCSVHeader = []string{"id", "occurred_at", "actor_id", "action", "target", "ip", "user_agent", "request_id", "outcome", "status"}
+
+type AuditHandler struct {
+	svc    *service.AuditService
+	logger zerolog.Logger
+}
+
+func NewAuditHandler(svc *service.AuditService, logger zerolog.Logger) *AuditHandler {
+	return &AuditHandler{
+		svc:    svc,
+		logger: logger.With().Str("handler", "audit").Logger(),
+	}
+}
+
+// ListAudit returns a page of the caller's audit log.
+func (h *AuditHandler) ListAudit(c *gin.Context) {
+	q, ok := h.bindQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.svc.List(c.Request.Context(), c.GetString("api_key"), q)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// ExportAudit streams every matching entry as CSV or, with format=jsonl, as
+// one JSON object per line.
+func (h *AuditHandler) ExportAudit(c *gin.Context) {
+	q, ok := h.bindQuery(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+	csvWriter := csv.NewWriter(c.Writer)
+	jsonEncoder := json.NewEncoder(c.Writer)
+
+	// The response starts with the first entry so that a failing query can
+	// still be answered with an error status.
+	started := false
+	start := func() error {
+		started = true
+		contentType := "text/csv"
+		if format == "jsonl" {
+			contentType = "application/x-ndjson"
+		}
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-%s.%s"`, time.Now().UTC().Format("20060102"), format))
+		c.Status(http.StatusOK)
+		if format == "csv" {
+			return csvWriter.Write(auditCSVHeader)
+		}
+		return nil
+	}
+
+	err := h.svc.Export(c.Request.Context(), c.GetString("api_key"), q, func(e *models.AuditEntry) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if format == "jsonl" {
+			return jsonEncoder.Encode(e)
+		}
+		return csvWriter.Write([]string{
+			strconv.FormatInt(e.ID, 10), e.OccurredAt.UTC().Format(time.RFC3339), e.ActorID, e.Action, e.Target,
+			e.IP, e.UserAgent, e.RequestID, e.Outcome, strconv.Itoa(e.Status),
+		})
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		csvWriter.Flush()
+		err = csvWriter.Error()
+	}
+	if err != nil {
+		if !started {
+			h.error(c, err)
+			return
+		}
+		// Too late to change the status; the client gets a truncated file.
+		h.logger.Error().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Audit export interrupted")
+	}
+}
+
+func (h *AuditHandler) bindQuery(c *gin.Context) (service.AuditQuery, bool) {
+	var q service.AuditQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return q, false
+	}
+	if err := validate.Struct(q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return q, false
+	}
+	return q, true
+}
+
+func (h *AuditHandler) error(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, internalErrors.ErrDependencyUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+	default:
+		h.logger.Error().Err(err).
+			Str("path", c.Request.URL.Path).
+			Str("ip", c.ClientIP()).
+			Msg("Audit request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
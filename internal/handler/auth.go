@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
 	"github.com/Vighnesh-V-H/sync/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -52,6 +54,29 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	res, err := h.svc.Signup(ctx, req)
+	if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Signup rejected, dependency unavailable")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+		return
+	}
+	if errors.Is(err, internalErrors.ErrWeakPassword) {
+		h.logger.Warn().
+			Str("email", req.Email).
+			Str("ip", c.ClientIP()).
+			Msg("Signup rejected, weak password")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, internalErrors.ErrUserAlreadyExists) {
+		h.logger.Warn().
+			Str("email", req.Email).
+			Str("ip", c.ClientIP()).
+			Msg("Signup rejected, email already registered")
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.logger.Error().Err(err).
 			Str("email", req.Email).
@@ -96,6 +121,18 @@ func (h *AuthHandler) Signin(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	res, err := h.svc.Signin(ctx, req)
+	if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Signin rejected, dependency unavailable")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+		return
+	}
+	if errors.Is(err, internalErrors.ErrUserNotFound) || errors.Is(err, internalErrors.ErrInvalidCredentials) {
+		// Unknown email and wrong password look the same to the caller.
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
 	if err != nil {
 		h.logger.Error().Err(err).
 			Str("email", req.Email).
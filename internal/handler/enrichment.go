@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type EnrichmentHandler struct {
+	svc    *service.EnrichmentService
+	logger zerolog.Logger
+}
+
+func NewEnrichmentHandler(svc *service.EnrichmentService, logger zerolog.Logger) *EnrichmentHandler {
+	return &EnrichmentHandler{
+		svc:    svc,
+		logger: logger.With().Str("handler", "enrichment").Logger(),
+	}
+}
+
+func (h *EnrichmentHandler) GetSettings(c *gin.Context) {
+	settings, err := h.svc.GetSettings(c.Request.Context(), c.GetString("api_key"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *EnrichmentHandler) UpdateSettings(c *gin.Context) {
+	var req service.UpdateEnrichmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind enrichment settings request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.svc.UpdateSettings(c.Request.Context(), c.GetString("api_key"), req)
+	if errors.Is(err, internalErrors.ErrUnknownEnricher) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
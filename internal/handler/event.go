@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
 	"github.com/Vighnesh-V-H/sync/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -41,6 +43,15 @@ func (h *EventHandler) AddEvent(c *gin.Context) {
 
 	var req service.AddEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			h.logger.Warn().
+				Int64("limit", maxErr.Limit).
+				Str("ip", c.ClientIP()).
+				Msg("Event request body too large")
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
 		h.logger.Warn().Err(err).
 			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
@@ -64,8 +75,26 @@ func (h *EventHandler) AddEvent(c *gin.Context) {
 		Str("ip", c.ClientIP()).
 		Msg("Processing event request")
 
+	req.Context = eventContext(c)
+
 	ctx := c.Request.Context()
 	res, err := h.svc.AddEvent(ctx, apiKey, req)
+	if errors.Is(err, internalErrors.ErrQuotaExceeded) {
+		h.logger.Warn().
+			Str("api_key", apiKey).
+			Str("ip", c.ClientIP()).
+			Msg("Event rejected, monthly quota exceeded")
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+		h.logger.Warn().Err(err).
+			Str("api_key", apiKey).
+			Str("ip", c.ClientIP()).
+			Msg("Event rejected, dependency unavailable")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+		return
+	}
 	if err != nil {
 		h.logger.Error().Err(err).
 			Str("api_key", apiKey).
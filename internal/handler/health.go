@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Vighnesh-V-H/sync/internal/resilience"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	required []string
+}
+
+// NewHealthHandler reports the service ready unless the breaker of one of
+// the required dependencies is open. Dependencies the service degrades
+// without, such as Redis for rate limiting, are reported but not required.
+func NewHealthHandler(required ...string) *HealthHandler {
+	return &HealthHandler{required: required}
+}
+
+// Live reports that the process is up.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready fails while a required dependency's circuit breaker is open, so a
+// load balancer stops sending traffic that would only be rejected.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	states := resilience.States()
+
+	code, status := http.StatusOK, "ok"
+	for _, name := range h.required {
+		if states[name] == resilience.StateOpen {
+			code, status = http.StatusServiceUnavailable, "unavailable"
+			break
+		}
+	}
+
+	c.JSON(code, gin.H{"status": status, "dependencies": states})
+}
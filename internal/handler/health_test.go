@@ -0,0 +1,45 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/resilience"
+	"github.com/gin-gonic/gin"
+)
+
+func openBreaker(name string) {
+	b := resilience.NewBreaker(name, resilience.BreakerOptions{Threshold: 1, Cooldown: time.Hour})
+	b.Do(context.Background(), func(context.Context) error { return errors.New("down") })
+}
+
+func ready(h *handler.HealthHandler) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ready", h.Ready)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	return rec.Code
+}
+
+func TestReadyFailsWhenRequiredDependencyIsOpen(t *testing.T) {
+	openBreaker("health_test_required")
+
+	if code := ready(handler.NewHealthHandler("health_test_required")); code != http.StatusServiceUnavailable {
+		t.Fatalf("ready = %d, want 503", code)
+	}
+}
+
+func TestReadyIgnoresOptionalDependencies(t *testing.T) {
+	openBreaker("health_test_optional")
+
+	if code := ready(handler.NewHealthHandler("health_test_missing")); code != http.StatusOK {
+		t.Fatalf("ready = %d, want 200", code)
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// IdentityHandler accepts identify and alias calls. Both are queued like any
+// other event; the processor applies them to the identity graph in order with
+// the events around them.
+type IdentityHandler struct {
+	identity *service.IdentityService
+	events   *service.EventService
+	logger   zerolog.Logger
+}
+
+func NewIdentityHandler(identity *service.IdentityService, events *service.EventService, logger zerolog.Logger) *IdentityHandler {
+	return &IdentityHandler{
+		identity: identity,
+		events:   events,
+		logger:   logger.With().Str("handler", "identity").Logger(),
+	}
+}
+
+func (h *IdentityHandler) Identify(c *gin.Context) {
+	var req service.IdentifyRequest
+	if !h.bind(c, &req) {
+		return
+	}
+	h.enqueue(c, req.Payload())
+}
+
+func (h *IdentityHandler) Alias(c *gin.Context) {
+	var req service.AliasRequest
+	if !h.bind(c, &req) {
+		return
+	}
+	h.enqueue(c, req.Payload())
+}
+
+func (h *IdentityHandler) GetPerson(c *gin.Context) {
+	apiKey := c.GetString("api_key")
+
+	person, err := h.identity.GetPerson(c.Request.Context(), apiKey, c.Param("distinct_id"))
+	if errors.Is(err, internalErrors.ErrPersonNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to fetch person")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+func (h *IdentityHandler) bind(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return false
+		}
+		h.logger.Warn().Err(err).
+			Str("path", c.Request.URL.Path).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind identity request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return false
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func (h *IdentityHandler) enqueue(c *gin.Context, payload map[string]any) {
+	apiKey := c.GetString("api_key")
+
+	res, err := h.events.AddEvent(c.Request.Context(), apiKey, service.AddEventRequest{
+		Payload: payload,
+		Context: eventContext(c),
+	})
+	if errors.Is(err, internalErrors.ErrQuotaExceeded) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Str("type", payload["type"].(string)).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to queue identity call")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type ImportHandler struct {
+	svc    *service.ImportService
+	logger zerolog.Logger
+}
+
+func NewImportHandler(svc *service.ImportService, logger zerolog.Logger) *ImportHandler {
+	return &ImportHandler{
+		svc:    svc,
+		logger: logger.With().Str("handler", "import").Logger(),
+	}
+}
+
+// StartImport streams an NDJSON or CSV body into a new import job. The format
+// comes from ?format= or the Content-Type; a CSV column mapping may be passed
+// as JSON in ?mapping= or the X-Import-Mapping header.
+func (h *ImportHandler) StartImport(c *gin.Context) {
+	apiKey := c.GetString("api_key")
+
+	format := importFormat(c)
+	if format == "" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "format must be ndjson or csv"})
+		return
+	}
+
+	var mapping *service.ImportMapping
+	if raw := firstNonEmpty(c.Query("mapping"), c.GetHeader("X-Import-Mapping")); raw != "" {
+		mapping = &service.ImportMapping{}
+		if err := json.Unmarshal([]byte(raw), mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid column mapping"})
+			return
+		}
+		if err := validate.Struct(mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	job, err := h.svc.StartImport(c.Request.Context(), apiKey, format, mapping, c.Request.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxErr):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+		case errors.Is(err, internalErrors.ErrUnsupportedImportFormat):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, internalErrors.ErrImportsStopped):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error().Err(err).
+				Str("ip", c.ClientIP()).
+				Msg("Failed to start import")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	audit.SetTarget(c.Request.Context(), job.ID)
+	c.JSON(http.StatusAccepted, gin.H{
+		"import_id": job.ID,
+		"status":    job.Status,
+	})
+}
+
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	res, err := h.svc.GetImport(c.Request.Context(), c.GetString("api_key"), c.Param("id"))
+	if errors.Is(err, internalErrors.ErrImportNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("import_id", c.Param("id")).
+			Msg("Failed to fetch import")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func importFormat(c *gin.Context) string {
+	switch strings.ToLower(c.Query("format")) {
+	case service.ImportFormatNDJSON, "jsonl":
+		return service.ImportFormatNDJSON
+	case service.ImportFormatCSV:
+		return service.ImportFormatCSV
+	case "":
+	default:
+		return ""
+	}
+
+	switch c.ContentType() {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return service.ImportFormatNDJSON
+	case "text/csv":
+		return service.ImportFormatCSV
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SigninMFA is the second signin step for users with MFA enabled: it
+// exchanges the challenge from Signin and a code for a token.
+func (h *AuthHandler) SigninMFA(c *gin.Context) {
+	var req service.MFASigninRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind MFA signin request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.svc.SigninMFA(c.Request.Context(), req)
+	if err != nil {
+		h.accountError(c, err, "MFA signin failed")
+		return
+	}
+
+	h.logger.Info().
+		Str("user_id", res.User.ID).
+		Str("ip", c.ClientIP()).
+		Msg("User MFA signin successful")
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	res, err := h.svc.EnrollMFA(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		h.accountError(c, err, "Failed to enroll MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind MFA verify request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.svc.VerifyMFA(c.Request.Context(), c.GetString("user_id"), req)
+	if err != nil {
+		h.accountError(c, err, "Failed to verify MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind MFA disable request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.DisableMFA(c.Request.Context(), c.GetString("user_id"), req); err != nil {
+		h.accountError(c, err, "Failed to disable MFA")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/oidc"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const (
+	oidcStateCookie = "sync_oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc/"
+	// oidcStateMaxAge matches how long the signed state itself is valid.
+	oidcStateMaxAge = 600
+)
+
+type OIDCHandler struct {
+	client *oidc.Client
+	svc    *service.AuthService
+	// secureCookies marks the state cookie Secure; off only for plain-HTTP
+	// development.
+	secureCookies bool
+	logger        zerolog.Logger
+}
+
+func NewOIDCHandler(client *oidc.Client, svc *service.AuthService, secureCookies bool, logger zerolog.Logger) *OIDCHandler {
+	return &OIDCHandler{
+		client:        client,
+		svc:           svc,
+		secureCookies: secureCookies,
+		logger:        logger.With().Str("handler", "oidc").Logger(),
+	}
+}
+
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.client.Providers()})
+}
+
+// Login redirects the browser to the provider, remembering the login's
+// state, nonce and PKCE verifier in a short-lived cookie.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	provider := c.Param("provider")
+
+	authURL, state, err := h.client.Begin(c.Request.Context(), provider)
+	if errors.Is(err, oidc.ErrUnknownProvider) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("provider", provider).
+			Msg("Failed to start OIDC login")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider unavailable"})
+		return
+	}
+
+	// Lax, so the cookie survives the top-level redirect back from the provider.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, oidcStateMaxAge, oidcCookiePath, "", h.secureCookies, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback finishes the login and responds like signin, with the user and
+// a sync token.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+
+	if errParam := c.Query("error"); errParam != "" {
+		h.logger.Warn().
+			Str("provider", provider).
+			Str("error", errParam).
+			Str("description", c.Query("error_description")).
+			Msg("Identity provider returned an error")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login was not completed at the identity provider"})
+		return
+	}
+
+	state, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", h.secureCookies, true)
+
+	id, err := h.client.Finish(c.Request.Context(), provider, state, c.Query("state"), c.Query("code"))
+	switch {
+	case errors.Is(err, oidc.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, oidc.ErrInvalidState), errors.Is(err, oidc.ErrInvalidToken):
+		h.logger.Warn().Err(err).
+			Str("provider", provider).
+			Str("ip", c.ClientIP()).
+			Msg("Rejected OIDC callback")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.logger.Error().Err(err).
+			Str("provider", provider).
+			Msg("Failed to finish OIDC login")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider unavailable"})
+		return
+	}
+
+	res, err := h.svc.OIDCSignin(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, internalErrors.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, internalErrors.ErrAccountNotVerified):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, internalErrors.ErrDependencyUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+		return
+	case err != nil:
+		h.logger.Error().Err(err).
+			Str("provider", provider).
+			Msg("OIDC signin failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
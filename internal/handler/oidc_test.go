@@ -0,0 +1,116 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/oidc"
+	"github.com/Vighnesh-V-H/sync/internal/oidc/oidctest"
+	"github.com/Vighnesh-V-H/sync/internal/password"
+	"github.com/Vighnesh-V-H/sync/internal/repositories/repotest"
+	"github.com/Vighnesh-V-H/sync/internal/routes"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type discardAudit struct{}
+
+func (discardAudit) Record(context.Context, *models.AuditEntry) {}
+
+func newOIDCServer(t *testing.T, user oidctest.User) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	log := zerolog.Nop()
+
+	provider := oidctest.New(t, user)
+	router := gin.New()
+	server := httptest.NewUnstartedServer(router)
+	baseURL := "http://" + server.Listener.Addr().String()
+
+	hasher := password.NewHasher(password.Params{Memory: 64, Iterations: 1, Parallelism: 1})
+	authSvc := service.NewAuthService(repotest.NewUserStore(), utils.JWTConfig{Secret: "test-secret", Expiry: time.Hour},
+		&password.Policy{MinLength: 8}, hasher, time.Hour, nil, log)
+	client := oidc.NewClient(map[string]oidc.ProviderConfig{
+		"mock": {Issuer: provider.Issuer(), ClientID: oidctest.ClientID, ClientSecret: oidctest.ClientSecret},
+	}, baseURL, "test-secret")
+	routes.SetupOIDCRoutes(router.Group("/api/v1"), handler.NewOIDCHandler(client, authSvc, false, log), discardAudit{})
+
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOIDCLoginFlow(t *testing.T) {
+	server := newOIDCServer(t, oidctest.User{Subject: "sub-1", Email: "ada@example.com", EmailVerified: true, Name: "Ada"})
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar}
+	res, err := browser.Get(server.URL + "/api/v1/auth/oidc/mock/login")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("login flow ended with status %d", res.StatusCode)
+	}
+
+	var body service.AuthResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	claims, err := utils.ParseJWT(body.Token, "test-secret")
+	if err != nil || claims.Email != "ada@example.com" || claims.APIKey == "" {
+		t.Fatalf("token claims %+v (%v)", claims, err)
+	}
+}
+
+func TestOIDCCallbackWithoutStateCookie(t *testing.T) {
+	server := newOIDCServer(t, oidctest.User{Subject: "sub-1", Email: "ada@example.com", EmailVerified: true})
+
+	// A browser without the state cookie, e.g. one lured to an attacker's
+	// callback URL, is turned away before the code is redeemed.
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := noRedirects.Get(server.URL + "/api/v1/auth/oidc/mock/login")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	res.Body.Close()
+	res, err = noRedirects.Get(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	res.Body.Close()
+
+	res, err = noRedirects.Get(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("callback: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("callback without state cookie: status %d, want 401", res.StatusCode)
+	}
+}
+
+func TestOIDCUnknownProvider(t *testing.T) {
+	server := newOIDCServer(t, oidctest.User{Subject: "sub-1"})
+
+	res, err := http.Get(server.URL + "/api/v1/auth/oidc/nope/login")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown provider: status %d, want 404", res.StatusCode)
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type RuleHandler struct {
+	svc    *service.RuleService
+	logger zerolog.Logger
+}
+
+func NewRuleHandler(svc *service.RuleService, logger zerolog.Logger) *RuleHandler {
+	return &RuleHandler{
+		svc:    svc,
+		logger: logger.With().Str("handler", "rule").Logger(),
+	}
+}
+
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	var req service.RuleRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	rule, err := h.svc.CreateRule(c.Request.Context(), c.GetString("api_key"), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	audit.SetTarget(c.Request.Context(), rule.ID)
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *RuleHandler) ListRules(c *gin.Context) {
+	list, err := h.svc.ListRules(c.Request.Context(), c.GetString("api_key"))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": list})
+}
+
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	var req service.RuleRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	rule, err := h.svc.UpdateRule(c.Request.Context(), c.GetString("api_key"), c.Param("id"), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	if err := h.svc.DeleteRule(c.Request.Context(), c.GetString("api_key"), c.Param("id")); err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *RuleHandler) DryRun(c *gin.Context) {
+	var req service.DryRunRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	res, err := h.svc.DryRun(c.Request.Context(), c.GetString("api_key"), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *RuleHandler) bind(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind rule request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return false
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func (h *RuleHandler) error(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidRule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, internalErrors.ErrRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).
+			Str("path", c.Request.URL.Path).
+			Str("ip", c.ClientIP()).
+			Msg("Rule request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	SegmentTrack    = "track"
+	SegmentIdentify = "identify"
+	SegmentPage     = "page"
+	SegmentScreen   = "screen"
+	SegmentGroup    = "group"
+	SegmentAlias    = "alias"
+)
+
+// segmentMessageNamespace scopes Segment messageIds per api key so two
+// tenants sending the same messageId never deduplicate each other.
+var segmentMessageNamespace = uuid.MustParse("0f1d9c2e-7c55-4b8e-9a0e-5f1f5d6a3c11")
+
+// SegmentHandler implements the Segment HTTP Tracking API so existing
+// Segment and RudderStack SDKs can send to sync unchanged. Each call is stored
+// as a sync event whose payload is the Segment message itself, with "type"
+// set to the call type.
+type SegmentHandler struct {
+	writeKeys *service.WriteKeyService
+	events    *service.EventService
+	logger    zerolog.Logger
+}
+
+func NewSegmentHandler(writeKeys *service.WriteKeyService, events *service.EventService, logger zerolog.Logger) *SegmentHandler {
+	return &SegmentHandler{
+		writeKeys: writeKeys,
+		events:    events,
+		logger:    logger.With().Str("handler", "segment").Logger(),
+	}
+}
+
+func (h *SegmentHandler) Track(c *gin.Context)    { h.single(c, SegmentTrack) }
+func (h *SegmentHandler) Identify(c *gin.Context) { h.single(c, SegmentIdentify) }
+func (h *SegmentHandler) Page(c *gin.Context)     { h.single(c, SegmentPage) }
+func (h *SegmentHandler) Screen(c *gin.Context)   { h.single(c, SegmentScreen) }
+func (h *SegmentHandler) Group(c *gin.Context)    { h.single(c, SegmentGroup) }
+func (h *SegmentHandler) Alias(c *gin.Context)    { h.single(c, SegmentAlias) }
+
+type segmentBatchRequest struct {
+	Batch    []map[string]any `json:"batch"`
+	Context  map[string]any   `json:"context"`
+	WriteKey string           `json:"writeKey"`
+}
+
+func (h *SegmentHandler) Batch(c *gin.Context) {
+	var req segmentBatchRequest
+	if !h.bind(c, &req) {
+		return
+	}
+	if len(req.Batch) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "batch is required"})
+		return
+	}
+
+	apiKey, ok := h.authenticate(c, req.WriteKey)
+	if !ok {
+		return
+	}
+
+	for i, msg := range req.Batch {
+		msgType, _ := msg["type"].(string)
+		if err := validateSegmentMessage(msgType, msg); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("batch[%d]: %v", i, err)})
+			return
+		}
+		// Batch-level context applies to every message that has none of its own.
+		if _, has := msg["context"]; !has && req.Context != nil {
+			msg["context"] = req.Context
+		}
+	}
+
+	// The batch is queued in one call so it is accepted or rejected whole;
+	// messageIds make a retried batch skip what was already queued.
+	events := make([]service.BatchEvent, len(req.Batch))
+	for i, msg := range req.Batch {
+		msgType, _ := msg["type"].(string)
+		events[i] = segmentEvent(c, apiKey, msgType, msg)
+	}
+	_, err := h.events.AddEvents(c.Request.Context(), apiKey, events)
+	if !h.respond(c, err, "batch") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *SegmentHandler) single(c *gin.Context, msgType string) {
+	var msg map[string]any
+	if !h.bind(c, &msg) {
+		return
+	}
+	if msg == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	writeKey, _ := msg["writeKey"].(string)
+	apiKey, ok := h.authenticate(c, writeKey)
+	if !ok {
+		return
+	}
+
+	if err := validateSegmentMessage(msgType, msg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ev := segmentEvent(c, apiKey, msgType, msg)
+	_, err := h.events.AddEventWithID(c.Request.Context(), apiKey, ev.ID, ev.AddEventRequest)
+	if !h.respond(c, err, msgType) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (h *SegmentHandler) bind(c *gin.Context, v any) bool {
+	// Segment SDKs do not always send Content-Type: application/json.
+	if err := json.NewDecoder(c.Request.Body).Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return false
+		}
+		h.logger.Warn().Err(err).
+			Str("path", c.Request.URL.Path).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to decode Segment request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return false
+	}
+	return true
+}
+
+// authenticate resolves the write key from HTTP Basic auth (the key is the
+// username, the password is empty) or from the writeKey body field that
+// analytics.js sends.
+func (h *SegmentHandler) authenticate(c *gin.Context, bodyWriteKey string) (string, bool) {
+	writeKey, _, ok := c.Request.BasicAuth()
+	if !ok || writeKey == "" {
+		writeKey = bodyWriteKey
+	}
+
+	wk, err := h.writeKeys.Resolve(c.Request.Context(), writeKey, requestOrigin(c))
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidWriteKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return "", false
+	case errors.Is(err, internalErrors.ErrOriginNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return "", false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return "", false
+	}
+
+	return wk.OwnerAPIKey, true
+}
+
+// segmentEvent turns a validated message into an event. Its ID is derived
+// from the messageId, so a resent message is recognised as a duplicate.
+func segmentEvent(c *gin.Context, apiKey string, msgType string, msg map[string]any) service.BatchEvent {
+	delete(msg, "writeKey")
+	msg["type"] = msgType
+
+	eventID := uuid.New().String()
+	if messageID, ok := msg["messageId"].(string); ok && messageID != "" {
+		eventID = uuid.NewSHA1(segmentMessageNamespace, []byte(apiKey+":"+messageID)).String()
+	}
+
+	return service.BatchEvent{
+		ID: eventID,
+		AddEventRequest: service.AddEventRequest{
+			Payload: msg,
+			Context: segmentEventContext(c, msg),
+		},
+	}
+}
+
+// respond writes the error response for a failed add and reports whether
+// the add succeeded.
+func (h *SegmentHandler) respond(c *gin.Context, err error, msgType string) bool {
+	if errors.Is(err, internalErrors.ErrQuotaExceeded) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return false
+	}
+	if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+		return false
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("type", msgType).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to add Segment event")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	return true
+}
+
+// segmentEventContext prefers context.ip and context.userAgent from the
+// message: server-side Segment SDKs use them to forward the end user's
+// details rather than their own.
+func segmentEventContext(c *gin.Context, msg map[string]any) models.EventContext {
+	evCtx := eventContext(c)
+	if msgCtx, ok := msg["context"].(map[string]any); ok {
+		if ip, ok := msgCtx["ip"].(string); ok && ip != "" {
+			evCtx.IP = ip
+		}
+		if ua, ok := msgCtx["userAgent"].(string); ok && ua != "" {
+			evCtx.UserAgent = ua
+		}
+	}
+	return evCtx
+}
+
+func validateSegmentMessage(msgType string, msg map[string]any) error {
+	hasString := func(key string) bool {
+		v, ok := msg[key].(string)
+		return ok && v != ""
+	}
+
+	switch msgType {
+	case SegmentTrack:
+		if !hasString("event") {
+			return errors.New("event is required")
+		}
+	case SegmentGroup:
+		if !hasString("groupId") {
+			return errors.New("groupId is required")
+		}
+	case SegmentAlias:
+		if !hasString("previousId") || !hasString("userId") {
+			return errors.New("previousId and userId are required")
+		}
+		return nil
+	case SegmentIdentify, SegmentPage, SegmentScreen:
+	default:
+		return fmt.Errorf("unsupported type %q", msgType)
+	}
+
+	if !hasString("userId") && !hasString("anonymousId") {
+		return errors.New("userId or anonymousId is required")
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const maxTrackBodyBytes = 64 << 10
+
+// transparentGIF is a 1x1 transparent GIF89a.
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// pixelReservedParams are query parameters of the pixel endpoint that are not event properties.
+var pixelReservedParams = map[string]struct{}{"wk": {}, "write_key": {}, "data": {}}
+
+type TrackHandler struct {
+	writeKeys *service.WriteKeyService
+	events    *service.EventService
+	logger    zerolog.Logger
+}
+
+func NewTrackHandler(writeKeys *service.WriteKeyService, events *service.EventService, logger zerolog.Logger) *TrackHandler {
+	return &TrackHandler{
+		writeKeys: writeKeys,
+		events:    events,
+		logger:    logger.With().Str("handler", "track").Logger(),
+	}
+}
+
+type TrackRequest struct {
+	WriteKey string         `json:"write_key"`
+	Payload  map[string]any `json:"payload"`
+}
+
+// Track accepts events from browsers authenticated by a public write key.
+// navigator.sendBeacon and simple CORS requests send the JSON body as
+// text/plain, so the body is decoded regardless of Content-Type.
+func (h *TrackHandler) Track(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxTrackBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	var req TrackRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("content_type", c.ContentType()).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to decode track request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.Payload == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "payload is required"})
+		return
+	}
+
+	key := firstNonEmpty(c.GetHeader("X-Write-Key"), c.Query("write_key"), req.WriteKey)
+	status, res, err := h.track(c, key, req.Payload)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(status, res)
+}
+
+// Pixel records an event encoded in the query string and always answers with
+// a 1x1 GIF so it can be embedded as an <img> in emails and no-JS pages. The
+// event is either base64url JSON in "data" or the remaining query parameters.
+func (h *TrackHandler) Pixel(c *gin.Context) {
+	payload, err := pixelPayload(c.Request.URL.Query())
+	if err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to decode pixel payload")
+		writePixel(c, http.StatusBadRequest)
+		return
+	}
+
+	key := firstNonEmpty(c.Query("wk"), c.Query("write_key"))
+	status, _, _ := h.track(c, key, payload)
+	writePixel(c, status)
+}
+
+func (h *TrackHandler) track(c *gin.Context, key string, payload map[string]any) (int, *service.AddEventResponse, error) {
+	ctx := c.Request.Context()
+
+	wk, err := h.writeKeys.Resolve(ctx, key, requestOrigin(c))
+	switch {
+	case errors.Is(err, internalErrors.ErrInvalidWriteKey):
+		h.logger.Warn().
+			Str("ip", c.ClientIP()).
+			Msg("Invalid write key")
+		return http.StatusUnauthorized, nil, err
+	case errors.Is(err, internalErrors.ErrOriginNotAllowed):
+		return http.StatusForbidden, nil, err
+	case err != nil:
+		return http.StatusInternalServerError, nil, errors.New("Internal server error")
+	}
+
+	res, err := h.events.AddEvent(ctx, wk.OwnerAPIKey, service.AddEventRequest{
+		Payload: payload,
+		Context: eventContext(c),
+	})
+	if errors.Is(err, internalErrors.ErrQuotaExceeded) {
+		return http.StatusTooManyRequests, nil, err
+	}
+	if errors.Is(err, internalErrors.ErrDependencyUnavailable) {
+		return http.StatusServiceUnavailable, nil, internalErrors.ErrDependencyUnavailable
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("write_key_id", wk.ID).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to add tracked event")
+		return http.StatusInternalServerError, nil, errors.New("Internal server error")
+	}
+
+	h.logger.Info().
+		Str("event_id", res.EventID).
+		Str("write_key_id", wk.ID).
+		Str("ip", c.ClientIP()).
+		Msg("Tracked event added successfully")
+
+	return http.StatusAccepted, res, nil
+}
+
+func pixelPayload(query url.Values) (map[string]any, error) {
+	if data := query.Get("data"); data != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(data)
+		if err != nil {
+			// Some encoders keep the padding.
+			raw, err = base64.URLEncoding.DecodeString(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, err
+		}
+		if payload == nil {
+			return nil, errors.New("data must be a JSON object")
+		}
+		return payload, nil
+	}
+
+	payload := make(map[string]any)
+	for k, v := range query {
+		if _, reserved := pixelReservedParams[k]; reserved || len(v) == 0 {
+			continue
+		}
+		if len(v) == 1 {
+			payload[k] = v[0]
+		} else {
+			payload[k] = v
+		}
+	}
+	if len(payload) == 0 {
+		return nil, errors.New("no event properties in query string")
+	}
+	return payload, nil
+}
+
+func writePixel(c *gin.Context, status int) {
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	c.Header("Pragma", "no-cache")
+	c.Data(status, "image/gif", transparentGIF)
+}
+
+// requestOrigin returns the Origin header, falling back to the origin of the
+// Referer for requests (such as image loads) that do not send one.
+func requestOrigin(c *gin.Context) string {
+	if origin := c.GetHeader("Origin"); origin != "" && origin != "null" {
+		return origin
+	}
+	ref, err := url.Parse(c.GetHeader("Referer"))
+	if err != nil || ref.Scheme == "" || ref.Host == "" {
+		return ""
+	}
+	return ref.Scheme + "://" + ref.Host
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// eventContext captures the client facts the processor enriches events with.
+func eventContext(c *gin.Context) models.EventContext {
+	return models.EventContext{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
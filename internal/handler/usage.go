@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type UsageHandler struct {
+	svc    *service.UsageService
+	logger zerolog.Logger
+}
+
+func NewUsageHandler(svc *service.UsageService, logger zerolog.Logger) *UsageHandler {
+	return &UsageHandler{
+		svc:    svc,
+		logger: logger.With().Str("handler", "usage").Logger(),
+	}
+}
+
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	apiKey := c.GetString("api_key")
+	if apiKey == "" {
+		h.logger.Error().
+			Str("ip", c.ClientIP()).
+			Msg("API key not found in context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	res, err := h.svc.GetUsage(c.Request.Context(), apiKey)
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to fetch usage")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type WebhookHandler struct {
+	svc    *service.WebhookService
+	logger zerolog.Logger
+}
+
+func NewWebhookHandler(svc *service.WebhookService, logger zerolog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		svc:    svc,
+		logger: logger.With().Str("handler", "webhook").Logger(),
+	}
+}
+
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req service.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind webhook request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wh, err := h.svc.CreateWebhook(c.Request.Context(), c.GetString("api_key"), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	audit.SetTarget(c.Request.Context(), wh.ID)
+	c.JSON(http.StatusCreated, wh)
+}
+
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.svc.ListWebhooks(c.Request.Context(), c.GetString("api_key"))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.svc.DeleteWebhook(c.Request.Context(), c.GetString("api_key"), c.Param("id")); err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	deliveries, err := h.svc.ListDeliveries(c.Request.Context(), c.GetString("api_key"), c.Param("id"), limit)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	delivery, err := h.svc.Replay(c.Request.Context(), c.GetString("api_key"), c.Param("id"), c.Param("delivery_id"))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) error(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, internalErrors.ErrWebhookNotFound), errors.Is(err, internalErrors.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, internalErrors.ErrWebhookDestination):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error().Err(err).
+			Str("path", c.Request.URL.Path).
+			Str("ip", c.ClientIP()).
+			Msg("Webhook request failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type WriteKeyHandler struct {
+	svc    *service.WriteKeyService
+	logger zerolog.Logger
+}
+
+func NewWriteKeyHandler(svc *service.WriteKeyService, logger zerolog.Logger) *WriteKeyHandler {
+	return &WriteKeyHandler{
+		svc:    svc,
+		logger: logger.With().Str("handler", "write_key").Logger(),
+	}
+}
+
+func (h *WriteKeyHandler) CreateWriteKey(c *gin.Context) {
+	apiKey := c.GetString("api_key")
+
+	var req service.CreateWriteKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to bind write key request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wk, err := h.svc.CreateWriteKey(c.Request.Context(), apiKey, req)
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to create write key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	audit.SetTarget(c.Request.Context(), wk.ID)
+	c.JSON(http.StatusCreated, wk)
+}
+
+func (h *WriteKeyHandler) ListWriteKeys(c *gin.Context) {
+	keys, err := h.svc.ListWriteKeys(c.Request.Context(), c.GetString("api_key"))
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to list write keys")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"write_keys": keys})
+}
+
+func (h *WriteKeyHandler) RevokeWriteKey(c *gin.Context) {
+	err := h.svc.RevokeWriteKey(c.Request.Context(), c.GetString("api_key"), c.Param("id"))
+	if errors.Is(err, internalErrors.ErrWriteKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Str("ip", c.ClientIP()).
+			Msg("Failed to revoke write key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
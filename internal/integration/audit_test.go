@@ -0,0 +1,137 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/integration"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/rs/zerolog"
+)
+
+func TestAuditLog(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("ada@example.com", "correct-horse-battery")
+	h.Do(http.MethodPost, "/api/v1/auth/signin", "", map[string]string{"email": "ada@example.com", "password": "wrong-password"})
+	token := h.Signin("ada@example.com", "correct-horse-battery")
+	apiKey := integration.APIKey(t, token)
+
+	created := h.Do(http.MethodPost, "/api/v1/write-keys", token, map[string]any{"allowed_origins": []string{"https://example.com"}})
+	keyID, _ := created.Body["id"].(string)
+	if created.Status != http.StatusCreated || keyID == "" {
+		t.Fatalf("create write key: %d %v", created.Status, created.Body)
+	}
+
+	// Another account's actions stay out of this one's log.
+	h.NewUser()
+
+	res := h.Do(http.MethodGet, "/api/v1/audit", token, nil)
+	entries, _ := res.Body["entries"].([]any)
+	if res.Status != http.StatusOK || len(entries) != 4 {
+		t.Fatalf("GET /audit: %d %v, want 4 entries", res.Status, res.Body)
+	}
+	var actions []string
+	for _, e := range entries {
+		entry := e.(map[string]any)
+		actions = append(actions, entry["action"].(string)+":"+entry["outcome"].(string))
+		if entry["request_id"] == "" || entry["ip"] == "" {
+			t.Errorf("entry %v missing request metadata", entry)
+		}
+	}
+	want := []string{"write_key.create:success", "auth.signin:success", "auth.signin:failure", "auth.signup:success"}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Fatalf("actions %v, want %v", actions, want)
+		}
+	}
+	if target := entries[0].(map[string]any)["target"]; target != keyID {
+		t.Fatalf("write key entry target %v, want %s", target, keyID)
+	}
+
+	res = h.Do(http.MethodGet, "/api/v1/audit?action=auth.signin&outcome=failure", token, nil)
+	if entries, _ := res.Body["entries"].([]any); len(entries) != 1 {
+		t.Fatalf("filtered audit log: %v, want the failed signin", res.Body)
+	}
+
+	res = h.Do(http.MethodGet, "/api/v1/audit?limit=3", token, nil)
+	next, _ := res.Body["next_before"].(float64)
+	if next == 0 {
+		t.Fatalf("first page: %v, want next_before", res.Body)
+	}
+	res = h.Do(http.MethodGet, "/api/v1/audit?limit=3&before="+strconv.FormatInt(int64(next), 10), token, nil)
+	if entries, _ := res.Body["entries"].([]any); len(entries) != 1 || res.Body["next_before"] != nil {
+		t.Fatalf("second page: %v, want the last entry", res.Body)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, h.Server.URL+"/api/v1/audit/export", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	exp, err := h.Server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	defer exp.Body.Close()
+	rows, err := csv.NewReader(exp.Body).ReadAll()
+	if exp.StatusCode != http.StatusOK || err != nil || len(rows) != 5 || rows[0][0] != "id" {
+		body, _ := io.ReadAll(exp.Body)
+		t.Fatalf("export: %d %v %v %s", exp.StatusCode, rows, err, body)
+	}
+
+	if _, err := h.DB.Pool.Exec(context.Background(), `UPDATE audit_log SET outcome = 'success' WHERE api_key = $1`, apiKey); err == nil {
+		t.Fatal("updating the audit log succeeded, want it rejected")
+	}
+	if _, err := h.DB.Pool.Exec(context.Background(), `DELETE FROM audit_log`); err == nil {
+		t.Fatal("deleting from the audit log succeeded, want it rejected")
+	}
+}
+
+func TestAuditLogRecordsRejectedRequests(t *testing.T) {
+	h := integration.New(t)
+
+	res := h.Do(http.MethodPost, "/api/v1/write-keys", "", map[string]any{"allowed_origins": []string{"https://example.com"}})
+	if res.Status != http.StatusUnauthorized {
+		t.Fatalf("create write key without token: %d, want 401", res.Status)
+	}
+
+	var rejected int
+	if err := h.DB.Pool.QueryRow(context.Background(), `
+		SELECT count(*) FROM audit_log
+		WHERE action = 'write_key.create' AND outcome = 'failure' AND status = 401 AND api_key IS NULL
+	`).Scan(&rejected); err != nil || rejected != 1 {
+		t.Fatalf("recorded %d rejected requests, %v; want 1", rejected, err)
+	}
+}
+
+func TestAuditLogPurge(t *testing.T) {
+	h := integration.New(t)
+	ctx := context.Background()
+	repo := repositories.NewAuditRepository(h.DB, zerolog.Nop())
+
+	h.NewUser()
+	h.NewUser()
+	var total int64
+	if err := h.DB.Pool.QueryRow(ctx, `SELECT count(*) FROM audit_log`).Scan(&total); err != nil || total == 0 {
+		t.Fatalf("audit log has %d entries, %v", total, err)
+	}
+
+	if purged, err := repo.PurgeAuditEntries(ctx, time.Now().Add(-time.Hour), 100); err != nil || purged != 0 {
+		t.Fatalf("purge of entries older than an hour = %d, %v; want none", purged, err)
+	}
+	if purged, err := repo.PurgeAuditEntries(ctx, time.Now().Add(time.Minute), 1); err != nil || purged != 1 {
+		t.Fatalf("purge with batch of 1 = %d, %v; want 1", purged, err)
+	}
+	if purged, err := repo.PurgeAuditEntries(ctx, time.Now().Add(time.Minute), 100); err != nil || purged != total-1 {
+		t.Fatalf("purge = %d, %v; want the remaining %d", purged, err, total-1)
+	}
+
+	// Outside a purge, deletes are still rejected.
+	h.NewUser()
+	if _, err := h.DB.Pool.Exec(ctx, `DELETE FROM audit_log`); err == nil {
+		t.Fatal("deleting from the audit log succeeded, want it rejected")
+	}
+}
@@ -0,0 +1,225 @@
+package integration_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/integration"
+)
+
+func TestSignupAndSignin(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("ada@example.com", "correct-horse-battery")
+	token := h.Signin("ada@example.com", "correct-horse-battery")
+
+	if apiKey := integration.APIKey(t, token); apiKey == "" {
+		t.Fatal("token carries no api key")
+	}
+
+	// The token authenticates management routes.
+	if res := h.Do(http.MethodGet, "/api/v1/usage", token, nil); res.Status != http.StatusOK {
+		t.Fatalf("GET /usage with token: %d %v", res.Status, res.Body)
+	}
+}
+
+func TestSignupRejectsDuplicateEmail(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("ada@example.com", "correct-horse-battery")
+	res := h.Do(http.MethodPost, "/api/v1/auth/signup", "", map[string]string{
+		"email":    "ada@example.com",
+		"password": "another-password",
+		"name":     "Ada Again",
+	})
+	if res.Status != http.StatusConflict {
+		t.Fatalf("second signup with the same email: status %d, want 409", res.Status)
+	}
+}
+
+func TestSignupNormalizesEmail(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("Ada@Example.com", "correct-horse-battery")
+	res := h.Do(http.MethodPost, "/api/v1/auth/signup", "", map[string]string{
+		"email":    "ada@example.COM",
+		"password": "another-password",
+		"name":     "Ada Again",
+	})
+	if res.Status != http.StatusConflict {
+		t.Fatalf("signup differing only in case: status %d, want 409", res.Status)
+	}
+
+	h.Signin("ADA@example.com", "correct-horse-battery")
+}
+
+func TestConcurrentSignupsWithSameEmail(t *testing.T) {
+	h := integration.New(t)
+
+	const attempts = 8
+	statuses := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses <- h.Do(http.MethodPost, "/api/v1/auth/signup", "", map[string]string{
+				"email":    "ada@example.com",
+				"password": "correct-horse-battery",
+				"name":     "Ada",
+			}).Status
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	created := 0
+	for status := range statuses {
+		switch status {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("concurrent signup: unexpected status %d", status)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d concurrent signups succeeded, want exactly 1", created)
+	}
+}
+
+func TestSigninRejectsWrongPassword(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("ada@example.com", "correct-horse-battery")
+	res := h.Do(http.MethodPost, "/api/v1/auth/signin", "", map[string]string{
+		"email":    "ada@example.com",
+		"password": "wrong-password",
+	})
+	if res.Status != http.StatusUnauthorized {
+		t.Fatalf("signin with the wrong password: status %d, want 401: %v", res.Status, res.Body)
+	}
+}
+
+func TestSignupValidation(t *testing.T) {
+	h := integration.New(t)
+
+	res := h.Do(http.MethodPost, "/api/v1/auth/signup", "", map[string]string{
+		"email":    "not-an-email",
+		"password": "short",
+	})
+	if res.Status != http.StatusBadRequest {
+		t.Fatalf("invalid signup: status %d, want 400", res.Status)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	h := integration.New(t)
+
+	if res := h.Do(http.MethodGet, "/readyz", "", nil); res.Status != http.StatusOK {
+		t.Fatalf("GET /readyz: %d %v", res.Status, res.Body)
+	}
+}
+
+func TestAccountRoutes(t *testing.T) {
+	h := integration.New(t)
+	token := h.NewUser()
+
+	if res := h.Do(http.MethodGet, "/api/v1/auth/me", "", nil); res.Status != http.StatusUnauthorized {
+		t.Fatalf("GET /auth/me without token: %d, want 401", res.Status)
+	}
+
+	res := h.Do(http.MethodGet, "/api/v1/auth/me", token, nil)
+	if res.Status != http.StatusOK || res.Body["api_key"] != integration.APIKey(t, token) {
+		t.Fatalf("GET /auth/me: %d %v", res.Status, res.Body)
+	}
+
+	res = h.Do(http.MethodPatch, "/api/v1/auth/me", token, map[string]string{"email": "Renamed@Example.com"})
+	if res.Status != http.StatusForbidden {
+		t.Fatalf("PATCH /auth/me email without current password: %d, want 403", res.Status)
+	}
+	res = h.Do(http.MethodPatch, "/api/v1/auth/me", token, map[string]string{
+		"email":            "Renamed@Example.com",
+		"current_password": "correct-horse-battery",
+	})
+	if res.Status != http.StatusOK {
+		t.Fatalf("PATCH /auth/me: %d %v", res.Status, res.Body)
+	}
+	user, _ := res.Body["user"].(map[string]any)
+	if user["email"] == "renamed@example.com" || user["pending_email"] != "renamed@example.com" {
+		t.Fatalf("PATCH /auth/me returned %v, want the normalized email pending confirmation", user)
+	}
+
+	h.ConfirmEmail(token, "renamed@example.com")
+	res = h.Do(http.MethodGet, "/api/v1/auth/me", token, nil)
+	if res.Body["email"] != "renamed@example.com" || res.Body["is_verified"] != true {
+		t.Fatalf("GET /auth/me after confirming: %v, want the new, verified email", res.Body)
+	}
+
+	res = h.Do(http.MethodPost, "/api/v1/auth/change-password", token, map[string]string{
+		"current_password": "wrong-password",
+		"new_password":     "a-new-password",
+	})
+	if res.Status != http.StatusForbidden {
+		t.Fatalf("change-password with wrong current password: %d, want 403", res.Status)
+	}
+	res = h.Do(http.MethodPost, "/api/v1/auth/change-password", token, map[string]string{
+		"current_password": "correct-horse-battery",
+		"new_password":     "a-new-password",
+	})
+	if res.Status != http.StatusNoContent {
+		t.Fatalf("change-password: %d %v", res.Status, res.Body)
+	}
+	h.Signin("renamed@example.com", "a-new-password")
+
+	waitRevoked(t, h, token)
+}
+
+// waitRevoked waits for token to stop working, which can take as long as
+// each process caches token versions.
+func waitRevoked(t *testing.T, h *integration.Harness, token string) {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for h.Do(http.MethodGet, "/api/v1/auth/me", token, nil).Status != http.StatusUnauthorized {
+		if time.Now().After(deadline) {
+			t.Fatal("revoked token still works")
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	h := integration.New(t)
+	token := h.NewUser()
+	apiKey := integration.APIKey(t, token)
+
+	if res := h.Do(http.MethodPost, "/api/v1/write-keys", token, map[string]any{"allowed_origins": []string{"https://example.com"}}); res.Status != http.StatusCreated {
+		t.Fatalf("create write key: %d %v", res.Status, res.Body)
+	}
+
+	if res := h.Do(http.MethodDelete, "/api/v1/auth/me", token, nil); res.Status != http.StatusBadRequest {
+		t.Fatalf("DELETE /auth/me without current password: %d, want 400", res.Status)
+	}
+	if res := h.Do(http.MethodDelete, "/api/v1/auth/me", token, map[string]string{"current_password": "correct-horse-battery"}); res.Status != http.StatusNoContent {
+		t.Fatalf("DELETE /auth/me: %d %v", res.Status, res.Body)
+	}
+	waitRevoked(t, h, token)
+
+	var activeKeys, erasures int
+	ctx := context.Background()
+	if err := h.DB.Pool.QueryRow(ctx, `
+		SELECT count(*) FROM write_keys wk JOIN users u ON u.id = wk.user_id
+		WHERE u.api_key = $1 AND wk.revoked_at IS NULL
+	`, apiKey).Scan(&activeKeys); err != nil {
+		t.Fatalf("count write keys: %v", err)
+	}
+	if err := h.DB.Pool.QueryRow(ctx, `SELECT count(*) FROM erasure_requests WHERE api_key = $1`, apiKey).Scan(&erasures); err != nil {
+		t.Fatalf("count erasure requests: %v", err)
+	}
+	if activeKeys != 0 || erasures != 1 {
+		t.Fatalf("after delete: %d active write keys, %d erasure requests; want 0 and 1", activeKeys, erasures)
+	}
+}
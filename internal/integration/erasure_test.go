@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Vighnesh-V-H/sync/internal/integration"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/rs/zerolog"
+)
+
+func deleteAccount(t *testing.T, h *integration.Harness, token string) {
+	t.Helper()
+	res := h.Do(http.MethodDelete, "/api/v1/auth/me", token, map[string]string{"current_password": "correct-horse-battery"})
+	if res.Status != http.StatusNoContent {
+		t.Fatalf("DELETE /auth/me: %d %v", res.Status, res.Body)
+	}
+}
+
+func TestEraseNextErasesDueRequests(t *testing.T) {
+	h := integration.New(t)
+	ctx := context.Background()
+	repo := repositories.NewErasureRepository(h.DB, zerolog.Nop())
+
+	due, notDue := h.NewUser(), h.NewUser()
+	dueKey, notDueKey := integration.APIKey(t, due), integration.APIKey(t, notDue)
+	h.WaitForEvent(h.PostEvent(due, map[string]any{"event": "a"}))
+	h.WaitForEvent(h.PostEvent(notDue, map[string]any{"event": "b"}))
+	deleteAccount(t, h, due)
+	deleteAccount(t, h, notDue)
+
+	if apiKey, err := repo.EraseNext(ctx); err != nil || apiKey != "" {
+		t.Fatalf("EraseNext before any request is due = %q, %v; want nothing", apiKey, err)
+	}
+
+	if _, err := h.DB.Pool.Exec(ctx, `
+		UPDATE erasure_requests SET erase_after = now() - interval '1 minute' WHERE api_key = $1
+	`, dueKey); err != nil {
+		t.Fatalf("make erasure due: %v", err)
+	}
+
+	apiKey, err := repo.EraseNext(ctx)
+	if err != nil || apiKey != dueKey {
+		t.Fatalf("EraseNext = %q, %v; want %q", apiKey, err, dueKey)
+	}
+	if n := h.CountEvents(dueKey); n != 0 {
+		t.Fatalf("%d events left after erasure", n)
+	}
+	if n := h.CountEvents(notDueKey); n != 1 {
+		t.Fatalf("erasure not yet due removed events: %d left, want 1", n)
+	}
+
+	var completed bool
+	if err := h.DB.Pool.QueryRow(ctx, `
+		SELECT completed_at IS NOT NULL FROM erasure_requests WHERE api_key = $1
+	`, dueKey).Scan(&completed); err != nil || !completed {
+		t.Fatalf("erasure request completed = %v, %v; want true", completed, err)
+	}
+
+	if apiKey, err := repo.EraseNext(ctx); err != nil || apiKey != "" {
+		t.Fatalf("second EraseNext = %q, %v; want nothing", apiKey, err)
+	}
+}
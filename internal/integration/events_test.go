@@ -0,0 +1,98 @@
+package integration_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Vighnesh-V-H/sync/internal/integration"
+)
+
+func TestEventIsProcessedAndStored(t *testing.T) {
+	h := integration.New(t)
+	token := h.NewUser()
+
+	id := h.PostEvent(token, map[string]any{"event": "signup", "distinct_id": "user-1"})
+	ev := h.WaitForEvent(id)
+
+	if ev.APIKey != integration.APIKey(t, token) {
+		t.Fatalf("stored api key = %q", ev.APIKey)
+	}
+	if ev.Payload["event"] != "signup" {
+		t.Fatalf("stored payload = %v", ev.Payload)
+	}
+	if ev.DistinctID != "user-1" || ev.PersonID == "" {
+		t.Fatalf("event not resolved to a person: distinct_id=%q person_id=%q", ev.DistinctID, ev.PersonID)
+	}
+}
+
+func TestEventsAreIsolatedPerTenant(t *testing.T) {
+	h := integration.New(t)
+	alice, bob := h.NewUser(), h.NewUser()
+
+	h.WaitForEvent(h.PostEvent(alice, map[string]any{"event": "a"}))
+	h.WaitForEvent(h.PostEvent(alice, map[string]any{"event": "b"}))
+	h.WaitForEvent(h.PostEvent(bob, map[string]any{"event": "c"}))
+
+	if n := h.CountEvents(integration.APIKey(t, alice)); n != 2 {
+		t.Fatalf("alice has %d events, want 2", n)
+	}
+	if n := h.CountEvents(integration.APIKey(t, bob)); n != 1 {
+		t.Fatalf("bob has %d events, want 1", n)
+	}
+}
+
+func TestIdentifyMergesAnonymousVisitor(t *testing.T) {
+	h := integration.New(t)
+	token := h.NewUser()
+
+	anonEvent := h.PostEvent(token, map[string]any{"event": "page_view", "anonymous_id": "anon-1"})
+	h.WaitForEvent(anonEvent)
+
+	res := h.Do(http.MethodPost, "/api/v1/identify", token, map[string]any{
+		"distinct_id":  "user-1",
+		"anonymous_id": "anon-1",
+		"traits":       map[string]any{"plan": "pro"},
+	})
+	if res.Status != http.StatusAccepted {
+		t.Fatalf("identify: %d %v", res.Status, res.Body)
+	}
+	identifyEvent, _ := res.Body["event_id"].(string)
+	h.WaitForEvent(identifyEvent)
+
+	res = h.Do(http.MethodGet, "/api/v1/persons/anon-1", token, nil)
+	if res.Status != http.StatusOK {
+		t.Fatalf("get person: %d %v", res.Status, res.Body)
+	}
+	traits, _ := res.Body["traits"].(map[string]any)
+	if traits["plan"] != "pro" {
+		t.Fatalf("person traits = %v", res.Body["traits"])
+	}
+	if ids, _ := res.Body["distinct_ids"].([]any); len(ids) != 2 {
+		t.Fatalf("person distinct_ids = %v, want anon-1 and user-1", res.Body["distinct_ids"])
+	}
+}
+
+func TestEventRequiresAuthentication(t *testing.T) {
+	h := integration.New(t)
+
+	res := h.Do(http.MethodPost, "/api/v1/event/add", "", map[string]any{"payload": map[string]any{"event": "x"}})
+	if res.Status != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated event: status %d, want 401", res.Status)
+	}
+}
+
+func TestUsageCountsAcceptedEvents(t *testing.T) {
+	h := integration.New(t)
+	token := h.NewUser()
+
+	h.PostEvent(token, map[string]any{"event": "a"})
+	h.PostEvent(token, map[string]any{"event": "b"})
+
+	res := h.Do(http.MethodGet, "/api/v1/usage", token, nil)
+	if res.Status != http.StatusOK {
+		t.Fatalf("get usage: %d %v", res.Status, res.Body)
+	}
+	if used, _ := res.Body["used"].(float64); used != 2 {
+		t.Fatalf("usage = %v, want 2 events used", res.Body)
+	}
+}
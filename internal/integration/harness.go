@@ -0,0 +1,378 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/app"
+	"github.com/Vighnesh-V-H/sync/internal/config"
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/oidc/oidctest"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const JWTSecret = "integration-test-secret"
+
+// Harness is one test's copy of the auth and events services, wired by
+// package app exactly as cmd/auth, cmd/events and cmd/processor wire them
+// and served from a single httptest server, with the processor draining the
+// queue in the background.
+type Harness struct {
+	t testing.TB
+
+	Config *config.Config
+	DB     *db.DB
+	Redis  *redis.Client
+	Server *httptest.Server
+	Events *repositories.EventRepository
+	// OIDC is a mock provider registered as "mock"; set its user before
+	// logging in through /auth/oidc/mock/login.
+	OIDC *oidctest.Provider
+}
+
+// New starts a harness on a freshly migrated database. Everything is torn
+// down when the test ends.
+func New(t testing.TB) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	log := zerolog.Nop()
+	ctx := context.Background()
+
+	databaseURL := createDatabase(t, postgresURL(t))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	baseURL := "http://" + listener.Addr().String()
+	oidcProvider := oidctest.New(t, oidctest.User{})
+
+	// The services are configured through the environment, as in
+	// production. Rate limiting is off so tests can sign in freely.
+	for key, value := range map[string]string{
+		"SYNC_PRIMARY_ENV":             "dev",
+		"SYNC_JWT_SECRET":              JWTSecret,
+		"SYNC_DATABASE_URL":            databaseURL,
+		"SYNC_REDIS_URL":               redisURL(t),
+		"SYNC_RATELIMIT_ENABLED":       "false",
+		"SYNC_OIDC_PROVIDERS":          "mock",
+		"SYNC_OIDC_REDIRECT_BASE_URL":  baseURL,
+		"SYNC_OIDC_MOCK_ISSUER":        oidcProvider.Issuer(),
+		"SYNC_OIDC_MOCK_CLIENT_ID":     oidctest.ClientID,
+		"SYNC_OIDC_MOCK_CLIENT_SECRET": oidctest.ClientSecret,
+	} {
+		t.Setenv(key, value)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	database, err := db.NewDB(cfg.Database.URL, log)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(database.Close)
+	database.Guard = db.NewPostgresGuard(cfg.Resilience.ResilienceOptions())
+	if _, err := db.PrepareSchema(ctx, database, nil, true, log); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	redisClient, err := db.NewRedis(cfg.Redis.URL, time.Duration(cfg.Redis.Timeout)*time.Second, log)
+	if err != nil {
+		t.Fatalf("connect to test redis: %v", err)
+	}
+	t.Cleanup(func() { redisClient.Close() })
+	if err := redisClient.FlushDB(ctx).Err(); err != nil {
+		t.Fatalf("flush test redis: %v", err)
+	}
+
+	eventQueue := queue.NewRedisQueue(redisClient, queue.DefaultRedisKey)
+	eventRepo := repositories.NewEventRepository(database, dedup.NewRedisStore(redisClient), eventQueue, log)
+	usageSvc := service.NewUsageService(repositories.NewUsageRepository(database, redisClient, log), app.UsageQuotas(cfg), log)
+	auditSvc := service.NewAuditService(repositories.NewAuditRepository(database, log), log)
+
+	router, err := app.NewRouter(cfg)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	app.SetupAuthAPI(router, cfg, app.AuthAPI{DB: database, Audit: auditSvc}, log)
+	app.SetupEventsAPI(router, cfg, app.EventsAPI{
+		DB:       database,
+		Sessions: app.NewSessions(database, log),
+		Events:   eventRepo,
+		Audit:    auditSvc,
+		Usage:    usageSvc,
+		Imports:  service.NewImportService(eventRepo, repositories.NewImportRepository(redisClient, log), usageSvc, cfg.App.BatchSize, log),
+	}, log)
+
+	server := httptest.NewUnstartedServer(router)
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	processing, err := app.NewProcessing(cfg, database, eventRepo, log)
+	if err != nil {
+		t.Fatalf("NewProcessing: %v", err)
+	}
+	procCtx, stopProc := context.WithCancel(ctx)
+	procDone := make(chan struct{})
+	go func() {
+		defer close(procDone)
+		processing.Run(procCtx)
+	}()
+	t.Cleanup(func() {
+		stopProc()
+		<-procDone
+		processing.Close()
+	})
+
+	return &Harness{
+		t:      t,
+		Config: cfg,
+		DB:     database,
+		Redis:  redisClient,
+		Server: server,
+		Events: eventRepo,
+		OIDC:   oidcProvider,
+	}
+}
+
+// createDatabase creates a uniquely named database on the server at adminURL,
+// dropped again when the test ends, and returns its URL.
+func createDatabase(t testing.TB, adminURL string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, adminURL)
+	if err != nil {
+		t.Fatalf("connect to Postgres: %v", err)
+	}
+	defer admin.Close(ctx)
+
+	name := "sync_test_" + uuid.NewString()[:8]
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("create test database: %v", err)
+	}
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(ctx, adminURL)
+		if err != nil {
+			return
+		}
+		defer conn.Close(ctx)
+		conn.Exec(ctx, "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)")
+	})
+
+	u, err := url.Parse(adminURL)
+	if err != nil {
+		t.Fatalf("parse database URL: %v", err)
+	}
+	u.Path = "/" + name
+	return u.String()
+}
+
+// Response is a decoded JSON response.
+type Response struct {
+	Status int
+	Body   map[string]any
+}
+
+// Do sends a JSON request to the harness server. token, if set, is sent as a
+// bearer token; body is marshalled unless it is already a string.
+func (h *Harness) Do(method string, path string, token string, body any) Response {
+	h.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			h.t.Fatalf("marshal request body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, h.Server.URL+path, reader)
+	if err != nil {
+		h.t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := h.Server.Client().Do(req)
+	if err != nil {
+		h.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	out := Response{Status: res.StatusCode}
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		h.t.Fatalf("read response: %v", err)
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &out.Body); err != nil {
+			h.t.Fatalf("%s %s returned non-JSON body %q", method, path, raw)
+		}
+	}
+	return out
+}
+
+// Signup registers a user and fails the test unless it succeeds.
+func (h *Harness) Signup(email string, password string) Response {
+	h.t.Helper()
+	res := h.Do(http.MethodPost, "/api/v1/auth/signup", "", service.SignupRequest{
+		Email:    email,
+		Password: password,
+		Name:     "Test User",
+	})
+	if res.Status != http.StatusCreated {
+		h.t.Fatalf("signup %s: status %d: %v", email, res.Status, res.Body)
+	}
+	return res
+}
+
+// Signin returns the user's JWT and fails the test unless signin succeeds.
+func (h *Harness) Signin(email string, password string) string {
+	h.t.Helper()
+	res := h.Do(http.MethodPost, "/api/v1/auth/signin", "", service.SigninRequest{
+		Email:    email,
+		Password: password,
+	})
+	if res.Status != http.StatusOK {
+		h.t.Fatalf("signin %s: status %d: %v", email, res.Status, res.Body)
+	}
+	token, _ := res.Body["token"].(string)
+	if token == "" {
+		h.t.Fatalf("signin %s returned no token: %v", email, res.Body)
+	}
+	return token
+}
+
+// ConfirmEmail follows the confirmation link that would be mailed to email
+// for the user token was issued to, failing the test unless it is accepted.
+func (h *Harness) ConfirmEmail(token string, email string) {
+	h.t.Helper()
+	claims, err := utils.ParseJWT(token, JWTSecret)
+	if err != nil {
+		h.t.Fatalf("decode token: %v", err)
+	}
+	confirmation, err := utils.GenerateEmailConfirmation(claims.UserID, email, utils.JWTConfig{Secret: JWTSecret, Expiry: time.Hour})
+	if err != nil {
+		h.t.Fatalf("generate email confirmation: %v", err)
+	}
+	if res := h.Do(http.MethodPost, "/api/v1/auth/confirm-email", "", map[string]string{"token": confirmation}); res.Status != http.StatusOK {
+		h.t.Fatalf("confirm %s: status %d: %v", email, res.Status, res.Body)
+	}
+}
+
+// NewUser signs up a user with a unique email and returns their token.
+func (h *Harness) NewUser() string {
+	h.t.Helper()
+	email := fmt.Sprintf("user-%s@example.com", uuid.NewString()[:8])
+	h.Signup(email, "correct-horse-battery")
+	return h.Signin(email, "correct-horse-battery")
+}
+
+// PostEvent sends payload to /event/add and returns the event ID, failing
+// the test unless it is accepted.
+func (h *Harness) PostEvent(token string, payload map[string]any) string {
+	h.t.Helper()
+	res := h.Do(http.MethodPost, "/api/v1/event/add", token, service.AddEventRequest{Payload: payload})
+	if res.Status != http.StatusAccepted {
+		h.t.Fatalf("post event: status %d: %v", res.Status, res.Body)
+	}
+	id, _ := res.Body["event_id"].(string)
+	return id
+}
+
+// WaitForEvent waits for the processor to store the event and returns it.
+func (h *Harness) WaitForEvent(id string) *models.Event {
+	h.t.Helper()
+	ctx := context.Background()
+	deadline := time.Now().Add(10 * time.Second)
+
+	for {
+		ev := &models.Event{ID: id}
+		err := h.DB.Pool.QueryRow(ctx, `
+			SELECT api_key, COALESCE(person_id, ''), COALESCE(distinct_id, ''), payload, timestamp, routes
+			FROM events WHERE id = $1
+		`, id).Scan(&ev.APIKey, &ev.PersonID, &ev.DistinctID, &ev.Payload, &ev.Timestamp, &ev.Routes)
+		if err == nil {
+			return ev
+		}
+		if err != pgx.ErrNoRows {
+			h.t.Fatalf("query event %s: %v", id, err)
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("event %s was not processed within 10s", id)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// CountEvents returns how many processed events are stored for apiKey.
+func (h *Harness) CountEvents(apiKey string) int {
+	h.t.Helper()
+	var n int
+	if err := h.DB.Pool.QueryRow(context.Background(), `SELECT count(*) FROM events WHERE api_key = $1`, apiKey).Scan(&n); err != nil {
+		h.t.Fatalf("count events: %v", err)
+	}
+	return n
+}
+
+// OIDCLogin logs in through the mock provider as user and returns the
+// callback's response.
+func (h *Harness) OIDCLogin(user oidctest.User) Response {
+	h.t.Helper()
+	h.OIDC.SetUser(user)
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar}
+	res, err := browser.Get(h.Server.URL + "/api/v1/auth/oidc/mock/login")
+	if err != nil {
+		h.t.Fatalf("OIDC login: %v", err)
+	}
+	defer res.Body.Close()
+
+	out := Response{Status: res.StatusCode}
+	if err := json.NewDecoder(res.Body).Decode(&out.Body); err != nil {
+		h.t.Fatalf("decode OIDC login response: %v", err)
+	}
+	return out
+}
+
+// APIKey returns the api key a token was issued for.
+func APIKey(t testing.TB, token string) string {
+	t.Helper()
+	apiKey, err := utils.APIKeyFromJWT(token, JWTSecret)
+	if err != nil {
+		t.Fatalf("decode token: %v", err)
+	}
+	return apiKey
+}
@@ -0,0 +1,12 @@
+package integration_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Vighnesh-V-H/sync/internal/integration"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(integration.Main(m))
+}
@@ -0,0 +1,87 @@
+package integration_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/integration"
+	"github.com/Vighnesh-V-H/sync/internal/totp"
+)
+
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatalf("totp.Code: %v", err)
+	}
+	return code
+}
+
+func TestMFASignin(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("ada@example.com", "correct-horse-battery")
+	token := h.Signin("ada@example.com", "correct-horse-battery")
+	apiKey := integration.APIKey(t, token)
+
+	enroll := h.Do(http.MethodPost, "/api/v1/auth/mfa/enroll", token, nil)
+	secret, _ := enroll.Body["secret"].(string)
+	if enroll.Status != http.StatusOK || secret == "" {
+		t.Fatalf("POST /auth/mfa/enroll: %d %v", enroll.Status, enroll.Body)
+	}
+
+	step := totp.Step(time.Now())
+	if res := h.Do(http.MethodPost, "/api/v1/auth/mfa/verify", token, map[string]string{"code": "not-a-code"}); res.Status != http.StatusUnauthorized {
+		t.Fatalf("verify with wrong code: %d, want 401", res.Status)
+	}
+	verify := h.Do(http.MethodPost, "/api/v1/auth/mfa/verify", token, map[string]string{"code": totpCode(t, secret, step)})
+	recovery, _ := verify.Body["recovery_codes"].([]any)
+	if verify.Status != http.StatusOK || len(recovery) == 0 {
+		t.Fatalf("POST /auth/mfa/verify: %d %v", verify.Status, verify.Body)
+	}
+
+	newChallenge := func() string {
+		t.Helper()
+		signin := h.Do(http.MethodPost, "/api/v1/auth/signin", "", map[string]string{
+			"email":    "ada@example.com",
+			"password": "correct-horse-battery",
+		})
+		challenge, _ := signin.Body["mfa_token"].(string)
+		if signin.Status != http.StatusOK || signin.Body["mfa_required"] != true || challenge == "" || signin.Body["token"] != nil || signin.Body["user"] != nil {
+			t.Fatalf("signin with MFA: %d %v, want only a challenge", signin.Status, signin.Body)
+		}
+		return challenge
+	}
+	challenge := newChallenge()
+	// The challenge is not accepted in place of a token.
+	if res := h.Do(http.MethodGet, "/api/v1/auth/me", challenge, nil); res.Status != http.StatusUnauthorized {
+		t.Fatalf("GET /auth/me with challenge: %d, want 401", res.Status)
+	}
+
+	// The enrollment step is spent.
+	if res := h.Do(http.MethodPost, "/api/v1/auth/signin/mfa", "", map[string]string{"mfa_token": challenge, "code": totpCode(t, secret, step)}); res.Status != http.StatusUnauthorized {
+		t.Fatalf("MFA signin with spent code: %d, want 401", res.Status)
+	}
+	res := h.Do(http.MethodPost, "/api/v1/auth/signin/mfa", "", map[string]string{"mfa_token": challenge, "code": totpCode(t, secret, step+1)})
+	token, _ = res.Body["token"].(string)
+	if res.Status != http.StatusOK || integration.APIKey(t, token) != apiKey {
+		t.Fatalf("MFA signin: %d %v", res.Status, res.Body)
+	}
+
+	code, _ := recovery[0].(string)
+	if res := h.Do(http.MethodPost, "/api/v1/auth/signin/mfa", "", map[string]string{"mfa_token": challenge, "code": code}); res.Status != http.StatusUnauthorized {
+		t.Fatalf("MFA signin with used challenge: %d, want 401", res.Status)
+	}
+	if res := h.Do(http.MethodPost, "/api/v1/auth/signin/mfa", "", map[string]string{"mfa_token": newChallenge(), "code": code}); res.Status != http.StatusOK {
+		t.Fatalf("MFA signin with recovery code: %d %v", res.Status, res.Body)
+	}
+	if res := h.Do(http.MethodPost, "/api/v1/auth/signin/mfa", "", map[string]string{"mfa_token": newChallenge(), "code": code}); res.Status != http.StatusUnauthorized {
+		t.Fatalf("MFA signin with used recovery code: %d, want 401", res.Status)
+	}
+
+	if res := h.Do(http.MethodDelete, "/api/v1/auth/mfa", token, map[string]any{"code": recovery[1]}); res.Status != http.StatusNoContent {
+		t.Fatalf("DELETE /auth/mfa: %d %v", res.Status, res.Body)
+	}
+	h.Signin("ada@example.com", "correct-horse-battery")
+}
@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Vighnesh-V-H/sync/internal/integration"
+	"github.com/Vighnesh-V-H/sync/internal/oidc/oidctest"
+)
+
+func TestOIDCLoginLinksExistingAccount(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("ada@example.com", "correct-horse-battery")
+	password := h.Signin("ada@example.com", "correct-horse-battery")
+	apiKey := integration.APIKey(t, password)
+
+	// Until the account confirms its email, nothing says its owner is the
+	// one logging in at the provider.
+	res := h.OIDCLogin(oidctest.User{Subject: "sub-1", Email: "Ada@Example.com", EmailVerified: true})
+	if res.Status != http.StatusConflict {
+		t.Fatalf("OIDC login to unconfirmed account: %d %v, want 409", res.Status, res.Body)
+	}
+
+	h.ConfirmEmail(password, "ada@example.com")
+	res = h.OIDCLogin(oidctest.User{Subject: "sub-1", Email: "Ada@Example.com", EmailVerified: true})
+	if res.Status != http.StatusOK {
+		t.Fatalf("OIDC login: %d %v", res.Status, res.Body)
+	}
+	token, _ := res.Body["token"].(string)
+	if got := integration.APIKey(t, token); got != apiKey {
+		t.Fatalf("OIDC login signed in to %s, want the existing account %s", got, apiKey)
+	}
+
+	// The link holds even after the provider-side email changes.
+	res = h.OIDCLogin(oidctest.User{Subject: "sub-1", Email: "ada@elsewhere.com", EmailVerified: true})
+	token, _ = res.Body["token"].(string)
+	if res.Status != http.StatusOK || integration.APIKey(t, token) != apiKey {
+		t.Fatalf("second OIDC login: %d %v", res.Status, res.Body)
+	}
+}
+
+func TestOIDCLoginCreatesAccount(t *testing.T) {
+	h := integration.New(t)
+
+	res := h.OIDCLogin(oidctest.User{Subject: "sub-2", Email: "grace@example.com", EmailVerified: true, Name: "Grace"})
+	if res.Status != http.StatusOK {
+		t.Fatalf("OIDC login: %d %v", res.Status, res.Body)
+	}
+	token, _ := res.Body["token"].(string)
+	if me := h.Do(http.MethodGet, "/api/v1/auth/me", token, nil); me.Status != http.StatusOK || me.Body["email"] != "grace@example.com" {
+		t.Fatalf("GET /auth/me: %d %v", me.Status, me.Body)
+	}
+}
+
+func TestOIDCLoginRejectsUnverifiedEmail(t *testing.T) {
+	h := integration.New(t)
+
+	h.Signup("ada@example.com", "correct-horse-battery")
+	res := h.OIDCLogin(oidctest.User{Subject: "attacker", Email: "ada@example.com", EmailVerified: false})
+	if res.Status != http.StatusForbidden {
+		t.Fatalf("OIDC login with unverified email: %d, want 403", res.Status)
+	}
+}
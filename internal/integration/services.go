@@ -0,0 +1,317 @@
+// Package integration runs the real repositories, services and handlers
+// against throwaway Postgres and Redis instances for end-to-end tests.
+//
+// The backing services are found, in order of preference:
+//
+//   - SYNC_TEST_DATABASE_URL / SYNC_TEST_REDIS_URL, for CI or a local
+//     docker compose. A fresh database is created on that server for each
+//     test; the Redis database is flushed, so never point it at real data.
+//   - Containers started with the docker CLI.
+//   - initdb/pg_ctl and redis-server binaries on PATH.
+//   - For Redis only, an in-process miniredis server.
+//
+// Tests are skipped when none is available, and always under -short. A
+// backend that is found but fails to start, such as docker with its daemon
+// down, fails them instead: a broken setup must not pass as a skipped run.
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const startTimeout = time.Minute
+
+// backingService is a started backing service; stop tears it down. missing
+// means no way to start one was found, as opposed to starting one failing.
+type backingService struct {
+	url     string
+	stop    func()
+	err     error
+	missing bool
+}
+
+var (
+	postgresOnce sync.Once
+	postgresSvc  backingService
+	redisOnce    sync.Once
+	redisSvc     backingService
+
+	stopMu sync.Mutex
+	stops  []func()
+)
+
+// Main runs the package's tests and then stops any services they started.
+// Call it from TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(integration.Main(m)) }
+func Main(m *testing.M) int {
+	code := m.Run()
+
+	stopMu.Lock()
+	defer stopMu.Unlock()
+	for i := len(stops) - 1; i >= 0; i-- {
+		stops[i]()
+	}
+	return code
+}
+
+func onStop(stop func()) {
+	stopMu.Lock()
+	stops = append(stops, stop)
+	stopMu.Unlock()
+}
+
+// postgresURL returns an admin connection URL for a running Postgres server,
+// skipping the test if none can be found and failing it if one cannot be
+// started.
+func postgresURL(t testing.TB) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("integration test skipped in -short mode")
+	}
+
+	postgresOnce.Do(func() {
+		if url := os.Getenv("SYNC_TEST_DATABASE_URL"); url != "" {
+			postgresSvc = backingService{url: url}
+			return
+		}
+		switch {
+		case hasCommand("docker"):
+			postgresSvc = startPostgresContainer()
+		case hasCommand("initdb") && hasCommand("pg_ctl"):
+			postgresSvc = startPostgresBinary()
+		default:
+			postgresSvc = backingService{
+				err:     fmt.Errorf("set SYNC_TEST_DATABASE_URL, or install docker or Postgres"),
+				missing: true,
+			}
+		}
+		if postgresSvc.err == nil && postgresSvc.stop != nil {
+			onStop(postgresSvc.stop)
+		}
+	})
+
+	if postgresSvc.missing {
+		t.Skipf("Postgres unavailable: %v", postgresSvc.err)
+	}
+	if postgresSvc.err != nil {
+		t.Fatalf("start Postgres: %v", postgresSvc.err)
+	}
+	return postgresSvc.url
+}
+
+// redisURL is postgresURL for Redis.
+func redisURL(t testing.TB) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("integration test skipped in -short mode")
+	}
+
+	redisOnce.Do(func() {
+		if url := os.Getenv("SYNC_TEST_REDIS_URL"); url != "" {
+			redisSvc = backingService{url: url}
+			return
+		}
+		switch {
+		case hasCommand("docker"):
+			redisSvc = startRedisContainer()
+		case hasCommand("redis-server"):
+			redisSvc = startRedisBinary()
+		default:
+			redisSvc = startMiniredis()
+		}
+		if redisSvc.err == nil && redisSvc.stop != nil {
+			onStop(redisSvc.stop)
+		}
+	})
+
+	if redisSvc.err != nil {
+		t.Fatalf("start Redis: %v", redisSvc.err)
+	}
+	return redisSvc.url
+}
+
+func startPostgresContainer() backingService {
+	addr, stop, err := runContainer("postgres:17-alpine", "5432/tcp",
+		"-e", "POSTGRES_USER=sync",
+		"-e", "POSTGRES_PASSWORD=sync",
+		"-e", "POSTGRES_DB=sync",
+	)
+	if err != nil {
+		return backingService{err: err}
+	}
+	url := fmt.Sprintf("postgres://sync:sync@%s/sync?sslmode=disable", addr)
+	if err := waitFor(func(ctx context.Context) error { return pingPostgres(ctx, url) }); err != nil {
+		stop()
+		return backingService{err: fmt.Errorf("postgres container did not become ready: %w", err)}
+	}
+	return backingService{url: url, stop: stop}
+}
+
+func startRedisContainer() backingService {
+	addr, stop, err := runContainer("redis:8.0-alpine", "6379/tcp")
+	if err != nil {
+		return backingService{err: err}
+	}
+	url := fmt.Sprintf("redis://%s/0", addr)
+	if err := waitFor(func(ctx context.Context) error { return pingRedis(ctx, url) }); err != nil {
+		stop()
+		return backingService{err: fmt.Errorf("redis container did not become ready: %w", err)}
+	}
+	return backingService{url: url, stop: stop}
+}
+
+// runContainer starts image with port published on a random loopback port
+// and returns that address.
+func runContainer(image string, port string, args ...string) (string, func(), error) {
+	runArgs := append([]string{"run", "-d", "--rm", "-p", "127.0.0.1::" + strings.TrimSuffix(port, "/tcp")}, args...)
+	out, err := command("docker", append(runArgs, image)...)
+	if err != nil {
+		return "", nil, err
+	}
+	id := strings.TrimSpace(out)
+	stop := func() { command("docker", "rm", "-f", id) }
+
+	out, err = command("docker", "port", id, port)
+	if err != nil {
+		stop()
+		return "", nil, err
+	}
+	// One line per address family; the first is the IPv4 binding.
+	addr := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	return addr, stop, nil
+}
+
+func startPostgresBinary() backingService {
+	dir, err := os.MkdirTemp("", "sync-test-postgres-*")
+	if err != nil {
+		return backingService{err: err}
+	}
+	data := filepath.Join(dir, "data")
+	if _, err := command("initdb", "-D", data, "-U", "sync", "--auth=trust", "--no-sync"); err != nil {
+		os.RemoveAll(dir)
+		return backingService{err: err}
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return backingService{err: err}
+	}
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	if _, err := command("pg_ctl", "-D", data, "-o", opts, "-l", filepath.Join(dir, "postgres.log"), "-w", "start"); err != nil {
+		os.RemoveAll(dir)
+		return backingService{err: err}
+	}
+
+	stop := func() {
+		command("pg_ctl", "-D", data, "-m", "immediate", "stop")
+		os.RemoveAll(dir)
+	}
+	return backingService{url: fmt.Sprintf("postgres://sync@127.0.0.1:%d/postgres?sslmode=disable", port), stop: stop}
+}
+
+func startRedisBinary() backingService {
+	port, err := freePort()
+	if err != nil {
+		return backingService{err: err}
+	}
+	cmd := exec.Command("redis-server", "--port", fmt.Sprint(port), "--bind", "127.0.0.1", "--save", "", "--appendonly", "no")
+	if err := cmd.Start(); err != nil {
+		return backingService{err: err}
+	}
+	stop := func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+
+	url := fmt.Sprintf("redis://127.0.0.1:%d/0", port)
+	if err := waitFor(func(ctx context.Context) error { return pingRedis(ctx, url) }); err != nil {
+		stop()
+		return backingService{err: err}
+	}
+	return backingService{url: url, stop: stop}
+}
+
+func startMiniredis() backingService {
+	srv := miniredis.NewMiniRedis()
+	if err := srv.Start(); err != nil {
+		return backingService{err: err}
+	}
+	return backingService{url: "redis://" + srv.Addr() + "/0", stop: srv.Close}
+}
+
+func pingPostgres(ctx context.Context, url string) error {
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	return conn.Ping(ctx)
+}
+
+func pingRedis(ctx context.Context, url string) error {
+	opt, err := redis.ParseURL(url)
+	if err != nil {
+		return err
+	}
+	client := redis.NewClient(opt)
+	defer client.Close()
+	return client.Ping(ctx).Err()
+}
+
+// waitFor retries check until it succeeds or startTimeout passes.
+func waitFor(check func(ctx context.Context) error) error {
+	deadline := time.Now().Add(startTimeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := check(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+func command(name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
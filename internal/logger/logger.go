@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/pkgerrors"
@@ -16,6 +17,7 @@ type Config struct {
 	ServiceName string
 	Environment string
 	IsProd      bool
+	Redaction   RedactionConfig
 }
 
 func New(cfg Config) zerolog.Logger {
@@ -42,6 +44,9 @@ func New(cfg Config) zerolog.Logger {
 	} else {
 		writer = os.Stdout
 	}
+	if cfg.Redaction.Enabled {
+		writer = NewRedactWriter(writer, cfg.Redaction)
+	}
 
 	logger := zerolog.New(writer).
 		Level(logLevel).
@@ -58,6 +63,17 @@ func New(cfg Config) zerolog.Logger {
 	return logger
 }
 
+// RedactionFromFields builds a RedactionConfig on top of the defaults, adding
+// any extra denylisted and payload fields from configuration.
+func RedactionFromFields(enabled bool, denyFields, payloadFields []string, salt string) RedactionConfig {
+	r := DefaultRedactionConfig()
+	r.Enabled = enabled
+	r.DenyFields = append(r.DenyFields, denyFields...)
+	r.PayloadFields = append(r.PayloadFields, payloadFields...)
+	r.HashSalt = salt
+	return r
+}
+
 func WithContext(logger zerolog.Logger, context map[string]any) zerolog.Logger {
 	if context == nil {
 		return logger
@@ -65,9 +81,17 @@ func WithContext(logger zerolog.Logger, context map[string]any) zerolog.Logger {
 	return logger.With().Fields(context).Logger()
 }
 
-func NewPgxLogger(level zerolog.Level) zerolog.Logger {
+// NewPgxLogger returns the logger for pgx's query tracer. Its entries pass
+// through the same redaction as the application's when that is enabled, and
+// query arguments are always dropped: they are bare values, such as emails and
+// password hashes, that no field name identifies.
+func NewPgxLogger(level zerolog.Level, redaction RedactionConfig) zerolog.Logger {
+	return newPgxLogger(os.Stdout, level, redaction)
+}
+
+func newPgxLogger(out io.Writer, level zerolog.Level, redaction RedactionConfig) zerolog.Logger {
 	writer := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
+		Out:        out,
 		TimeFormat: "2006-01-02 15:04:05",
 		FormatFieldValue: func(i any) string {
 			switch v := i.(type) {
@@ -89,7 +113,13 @@ func NewPgxLogger(level zerolog.Level) zerolog.Logger {
 		},
 	}
 
-	return zerolog.New(writer).
+	pgxRedaction := RedactionConfig{}
+	if redaction.Enabled {
+		pgxRedaction = redaction
+	}
+	pgxRedaction.DenyFields = append(slices.Clone(pgxRedaction.DenyFields), "args")
+
+	return zerolog.New(NewRedactWriter(writer, pgxRedaction)).
 		Level(level).
 		With().
 		Timestamp().
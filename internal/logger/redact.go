@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+var errNotObject = errors.New("log entry is not a JSON object")
+
+type RedactionConfig struct {
+	Enabled bool
+	// DenyFields are replaced entirely, wherever they appear in the entry.
+	DenyFields []string
+	// APIKeyFields are masked down to their prefix, e.g. "sync_AbCd****".
+	APIKeyFields []string
+	// EmailFields are replaced by a salted SHA-256 hash so they can still be correlated.
+	EmailFields []string
+	// PayloadFields are scrubbed inside event payloads (the "payload" field) only.
+	PayloadFields []string
+	HashSalt      string
+}
+
+func DefaultRedactionConfig() RedactionConfig {
+	return RedactionConfig{
+		Enabled:      true,
+		DenyFields:   []string{"password", "token", "secret", "authorization", "name"},
+		APIKeyFields: []string{"api_key"},
+		EmailFields:  []string{"email"},
+	}
+}
+
+// RedactWriter rewrites every JSON log entry before it reaches the wrapped writer.
+// zerolog hooks only see the message and cannot alter fields that were already
+// encoded, so redaction is applied at the writer level where the whole entry is available.
+// Entries that mention none of the configured field names are passed through
+// without being parsed.
+type RedactWriter struct {
+	out      io.Writer
+	deny     map[string]struct{}
+	apiKeys  map[string]struct{}
+	emails   map[string]struct{}
+	payload  map[string]struct{}
+	needles  [][]byte
+	hashSalt string
+}
+
+func NewRedactWriter(out io.Writer, cfg RedactionConfig) *RedactWriter {
+	w := &RedactWriter{
+		out:      out,
+		deny:     fieldSet(cfg.DenyFields),
+		apiKeys:  fieldSet(cfg.APIKeyFields),
+		emails:   fieldSet(cfg.EmailFields),
+		payload:  fieldSet(cfg.PayloadFields),
+		hashSalt: cfg.HashSalt,
+	}
+	for _, set := range []map[string]struct{}{w.deny, w.apiKeys, w.emails, w.payload} {
+		for f := range set {
+			w.needles = append(w.needles, []byte(`"`+f+`"`))
+		}
+	}
+	return w
+}
+
+func fieldSet(fields []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f != "" {
+			set[f] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (w *RedactWriter) Write(p []byte) (int, error) {
+	if !w.mayContain(p) {
+		return w.out.Write(p)
+	}
+	redacted, err := w.redactEntry(p)
+	if err != nil {
+		// Not a JSON object (or malformed); pass it through untouched rather than drop a log line.
+		return w.out.Write(p)
+	}
+	if _, err := w.out.Write(redacted); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// mayContain reports whether any configured field name appears as a quoted
+// string anywhere in the entry. False positives only cost a parse.
+func (w *RedactWriter) mayContain(p []byte) bool {
+	lower := bytes.ToLower(p)
+	for _, n := range w.needles {
+		if bytes.Contains(lower, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactEntry walks the top-level keys of the entry in order so that the
+// output keeps zerolog's field ordering.
+func (w *RedactWriter) redactEntry(p []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errNotObject
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, errNotObject
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+
+		value, err := w.redactField(key, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// redactField leaves scalar fields that are not configured untouched and
+// decodes everything else, so nested objects and arrays are searched too.
+func (w *RedactWriter) redactField(key string, raw json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(raw)
+	nested := len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+	if !nested && !w.sensitive(strings.ToLower(key)) {
+		return raw, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(w.redact(key, v, false))
+}
+
+func (w *RedactWriter) sensitive(name string) bool {
+	_, deny := w.deny[name]
+	_, apiKey := w.apiKeys[name]
+	_, email := w.emails[name]
+	return deny || apiKey || email
+}
+
+// redact returns the value to log for key at any depth. inPayload is set
+// below a "payload" field, where PayloadFields are scrubbed as well.
+func (w *RedactWriter) redact(key string, v any, inPayload bool) any {
+	name := strings.ToLower(key)
+
+	if _, ok := w.deny[name]; ok {
+		return redactedValue
+	}
+	if _, ok := w.payload[name]; ok && inPayload {
+		return redactedValue
+	}
+	if _, ok := w.apiKeys[name]; ok {
+		return redactString(v, MaskAPIKey)
+	}
+	if _, ok := w.emails[name]; ok {
+		return redactString(v, w.HashEmail)
+	}
+
+	inPayload = inPayload || name == "payload"
+	switch val := v.(type) {
+	case map[string]any:
+		for k, inner := range val {
+			val[k] = w.redact(k, inner, inPayload)
+		}
+	case []any:
+		for i, inner := range val {
+			val[i] = w.redact("", inner, inPayload)
+		}
+	}
+	return v
+}
+
+func redactString(v any, fn func(string) string) any {
+	s, ok := v.(string)
+	if !ok {
+		return redactedValue
+	}
+	return fn(s)
+}
+
+// HashEmail returns a stable, salted hash of the normalized address.
+func (w *RedactWriter) HashEmail(email string) string {
+	if email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(w.hashSalt + strings.ToLower(strings.TrimSpace(email))))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// MaskAPIKey keeps the key prefix and the first few characters, e.g. "sync_AbCd****".
+func MaskAPIKey(key string) string {
+	if key == "" {
+		return ""
+	}
+
+	prefix := ""
+	rest := key
+	if i := strings.Index(key, "_"); i >= 0 && i < len(key)-1 {
+		prefix, rest = key[:i+1], key[i+1:]
+	}
+
+	const visible = 4
+	if len(rest) <= visible {
+		return prefix + "****"
+	}
+	return prefix + rest[:visible] + "****"
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func redactLine(t *testing.T, cfg RedactionConfig, log func(zerolog.Logger)) map[string]any {
+	t.Helper()
+
+	var out bytes.Buffer
+	log(zerolog.New(NewRedactWriter(&out, cfg)))
+
+	var entry map[string]any
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("output is not JSON: %v: %s", err, out.String())
+	}
+	return entry
+}
+
+func TestRedactTopLevelFields(t *testing.T) {
+	cfg := DefaultRedactionConfig()
+	entry := redactLine(t, cfg, func(l zerolog.Logger) {
+		l.Info().
+			Str("password", "hunter2").
+			Str("api_key", "sync_AbCdEfGh").
+			Str("email", "a@example.com").
+			Str("user_id", "u1").
+			Msg("signin")
+	})
+
+	if entry["password"] != redactedValue {
+		t.Errorf("password = %v, want redacted", entry["password"])
+	}
+	if entry["api_key"] != "sync_AbCd****" {
+		t.Errorf("api_key = %v, want masked", entry["api_key"])
+	}
+	if email, _ := entry["email"].(string); !strings.HasPrefix(email, "sha256:") {
+		t.Errorf("email = %v, want hashed", entry["email"])
+	}
+	if entry["user_id"] != "u1" {
+		t.Errorf("user_id = %v, want untouched", entry["user_id"])
+	}
+}
+
+func TestRedactNestedFields(t *testing.T) {
+	cfg := DefaultRedactionConfig()
+	entry := redactLine(t, cfg, func(l zerolog.Logger) {
+		l.Info().
+			Interface("request", map[string]any{
+				"body": map[string]any{"password": "hunter2", "api_key": "sync_AbCdEfGh"},
+				"users": []any{
+					map[string]any{"email": "a@example.com", "id": 1},
+				},
+			}).
+			Msg("request")
+	})
+
+	body := entry["request"].(map[string]any)["body"].(map[string]any)
+	if body["password"] != redactedValue {
+		t.Errorf("nested password = %v, want redacted", body["password"])
+	}
+	if body["api_key"] != "sync_AbCd****" {
+		t.Errorf("nested api_key = %v, want masked", body["api_key"])
+	}
+	user := entry["request"].(map[string]any)["users"].([]any)[0].(map[string]any)
+	if email, _ := user["email"].(string); !strings.HasPrefix(email, "sha256:") {
+		t.Errorf("email in array = %v, want hashed", user["email"])
+	}
+	if user["id"] != float64(1) {
+		t.Errorf("id = %v, want untouched", user["id"])
+	}
+}
+
+func TestRedactPayloadFieldsOnlyInsidePayload(t *testing.T) {
+	cfg := DefaultRedactionConfig()
+	cfg.PayloadFields = []string{"ssn"}
+	entry := redactLine(t, cfg, func(l zerolog.Logger) {
+		l.Info().
+			Interface("payload", map[string]any{"profile": map[string]any{"ssn": "123"}}).
+			Str("ssn", "kept").
+			Msg("event")
+	})
+
+	profile := entry["payload"].(map[string]any)["profile"].(map[string]any)
+	if profile["ssn"] != redactedValue {
+		t.Errorf("payload ssn = %v, want redacted", profile["ssn"])
+	}
+	if entry["ssn"] != "kept" {
+		t.Errorf("top-level ssn = %v, want untouched", entry["ssn"])
+	}
+}
+
+func TestRedactPassesThroughUnrelatedEntries(t *testing.T) {
+	var out bytes.Buffer
+	w := NewRedactWriter(&out, DefaultRedactionConfig())
+
+	line := []byte(`{"level":"info","count":12345678901234567890,"message":"ok"}` + "\n")
+	if _, err := w.Write(line); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), line) {
+		t.Fatalf("output = %s, want unchanged %s", out.Bytes(), line)
+	}
+
+	out.Reset()
+	if _, err := w.Write([]byte("not json password\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if out.String() != "not json password\n" {
+		t.Fatalf("non-JSON output = %q, want unchanged", out.String())
+	}
+}
+
+func TestPgxLoggerDropsQueryArgs(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		var out bytes.Buffer
+		cfg := DefaultRedactionConfig()
+		cfg.Enabled = enabled
+		log := newPgxLogger(&out, zerolog.DebugLevel, cfg)
+
+		log.Info().
+			Str("sql", "UPDATE users SET email = $2 WHERE id = $1").
+			Interface("args", []any{"user-1", "ada@example.com"}).
+			Str("password", "hunter2").
+			Msg("Query")
+
+		got := out.String()
+		if strings.Contains(got, "ada@example.com") || !strings.Contains(got, "UPDATE users") {
+			t.Fatalf("redaction enabled=%v: pgx log = %q, want the query without its args", enabled, got)
+		}
+		if enabled == strings.Contains(got, "hunter2") {
+			t.Fatalf("redaction enabled=%v: pgx log = %q, want the denylist applied only when enabled", enabled, got)
+		}
+	}
+}
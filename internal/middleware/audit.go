@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditRecorder stores audit log entries.
+type AuditRecorder interface {
+	Record(ctx context.Context, entry *models.AuditEntry)
+}
+
+// AuditMiddleware records the request in the audit log as action once the
+// handler has run, with an outcome taken from the response status. It goes
+// before rate limiting and AuthMiddleware so requests they reject are
+// recorded too. The actor defaults to the user AuthMiddleware authenticated
+// and the target to the :id route parameter; handlers and services can set
+// both with audit.SetActor and audit.SetTarget.
+func AuditMiddleware(rec AuditRecorder, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entry := &models.AuditEntry{
+			Action: action,
+			Target: c.Param("id"),
+		}
+		c.Request = c.Request.WithContext(audit.NewContext(c.Request.Context(), entry))
+
+		c.Next()
+
+		if entry.ActorID == "" {
+			entry.ActorID = c.GetString("user_id")
+		}
+		if entry.APIKey == "" {
+			entry.APIKey = c.GetString("api_key")
+		}
+
+		entry.OccurredAt = time.Now()
+		entry.IP = c.ClientIP()
+		entry.UserAgent = c.Request.UserAgent()
+		entry.RequestID = c.GetString("request_id")
+		entry.Status = c.Writer.Status()
+		entry.Outcome = models.AuditOutcomeSuccess
+		if entry.Status >= http.StatusBadRequest {
+			entry.Outcome = models.AuditOutcomeFailure
+		}
+
+		// The client may be gone by now; the entry is still written.
+		rec.Record(context.WithoutCancel(c.Request.Context()), entry)
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type recorder struct {
+	entries []*models.AuditEntry
+}
+
+func (r *recorder) Record(_ context.Context, entry *models.AuditEntry) {
+	r.entries = append(r.entries, entry)
+}
+
+func newAuditRouter(rec *recorder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+
+	authenticated := func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("api_key", "sync_key")
+	}
+	rejected := func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+	}
+	router.DELETE("/things/:id", AuditMiddleware(rec, "thing.delete"), authenticated, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.PUT("/things/:id", AuditMiddleware(rec, "thing.update"), rejected, authenticated, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.POST("/signin", AuditMiddleware(rec, "auth.signin"), func(c *gin.Context) {
+		audit.SetActor(c.Request.Context(), "user-2", "sync_other")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+	})
+	return router
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	rec := &recorder{}
+	router := newAuditRouter(rec)
+
+	req := httptest.NewRequest(http.MethodDelete, "/things/42", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	req.Header.Set("User-Agent", "audit-test")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rec.entries) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(rec.entries))
+	}
+	got := rec.entries[0]
+	if got.Action != "thing.delete" || got.ActorID != "user-1" || got.APIKey != "sync_key" || got.Target != "42" ||
+		got.RequestID != "req-123" || got.UserAgent != "audit-test" || got.IP == "" ||
+		got.Outcome != models.AuditOutcomeSuccess || got.Status != http.StatusNoContent || got.OccurredAt.IsZero() {
+		t.Fatalf("entry %+v", got)
+	}
+}
+
+func TestAuditMiddlewareActorFromHandler(t *testing.T) {
+	rec := &recorder{}
+	router := newAuditRouter(rec)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/signin", nil))
+
+	got := rec.entries[0]
+	if got.ActorID != "user-2" || got.APIKey != "sync_other" || got.Outcome != models.AuditOutcomeFailure || got.Status != http.StatusUnauthorized {
+		t.Fatalf("entry %+v", got)
+	}
+	if got.RequestID == "" || res.Header().Get(RequestIDHeader) != got.RequestID {
+		t.Fatalf("request id %q, response header %q", got.RequestID, res.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestAuditMiddlewareRecordsRejectedRequests(t *testing.T) {
+	rec := &recorder{}
+	router := newAuditRouter(rec)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/things/42", nil))
+
+	if len(rec.entries) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(rec.entries))
+	}
+	got := rec.entries[0]
+	if got.Action != "thing.update" || got.ActorID != "" || got.Outcome != models.AuditOutcomeFailure || got.Status != http.StatusTooManyRequests {
+		t.Fatalf("entry %+v", got)
+	}
+}
+
+func TestRequestIDRejectsUnsafeHeader(t *testing.T) {
+	router := newAuditRouter(&recorder{})
+
+	req := httptest.NewRequest(http.MethodPost, "/signin", nil)
+	req.Header.Set(RequestIDHeader, "bad id\r\nwith spaces")
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	if id := res.Header().Get(RequestIDHeader); id == "" || id == req.Header.Get(RequestIDHeader) {
+		t.Fatalf("response request id %q, want a generated one", id)
+	}
+}
@@ -1,15 +1,19 @@
 package middleware
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(secretKey string) gin.HandlerFunc {
+// AuthMiddleware accepts bearer tokens signed with secretKey whose user
+// still exists and has not revoked them, as reported by sessions. A nil
+// sessions checks the signature only.
+func AuthMiddleware(secretKey string, sessions utils.Sessions) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -23,29 +27,23 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 		}
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secretKey), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := utils.Authenticate(c.Request.Context(), tokenString, secretKey, sessions)
+		switch {
+		case errors.Is(err, utils.ErrMissingAPIKey):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token does not contain api_key"})
+			return
+		case errors.Is(err, utils.ErrInvalidToken):
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
-		}
-
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if apiKey, ok := claims["api_key"].(string); ok {
-				c.Set("api_key", apiKey)
-			} else {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token does not contain api_key"})
-				return
-			}
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		case errors.Is(err, internalErrors.ErrDependencyUnavailable):
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": internalErrors.ErrDependencyUnavailable.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
 			return
 		}
+		c.Set("api_key", claims.APIKey)
+		c.Set("user_id", claims.UserID)
 
 		c.Next()
 	}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const authTestSecret = "auth-test-secret"
+
+// stubSessions maps user IDs to their current token version; users missing
+// from it are deleted.
+type stubSessions struct {
+	versions map[string]int
+	err      error
+}
+
+func (s stubSessions) TokenVersion(_ context.Context, userID string) (int, bool, error) {
+	version, ok := s.versions[userID]
+	return version, ok, s.err
+}
+
+func authGet(t *testing.T, sessions utils.Sessions, userID string, version int) int {
+	t.Helper()
+	token, err := utils.GenerateJWT(userID, "a@example.com", "sync_key", version, utils.JWTConfig{Secret: authTestSecret, Expiry: time.Hour})
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", AuthMiddleware(authTestSecret, sessions), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAuthMiddlewareChecksSessions(t *testing.T) {
+	sessions := stubSessions{versions: map[string]int{"user-1": 2}}
+
+	if code := authGet(t, sessions, "user-1", 2); code != http.StatusOK {
+		t.Fatalf("current token = %d, want 200", code)
+	}
+	if code := authGet(t, sessions, "user-1", 1); code != http.StatusUnauthorized {
+		t.Fatalf("revoked token = %d, want 401", code)
+	}
+	if code := authGet(t, sessions, "deleted-user", 0); code != http.StatusUnauthorized {
+		t.Fatalf("deleted user's token = %d, want 401", code)
+	}
+}
+
+func TestAuthMiddlewareSessionsUnavailable(t *testing.T) {
+	sessions := stubSessions{err: internalErrors.ErrDependencyUnavailable}
+
+	if code := authGet(t, sessions, "user-1", 0); code != http.StatusServiceUnavailable {
+		t.Fatalf("token checked while Postgres is down = %d, want 503", code)
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type CORSPolicy struct {
+	// PathPrefix selects which requests the policy applies to.
+	PathPrefix string
+	// AllowedOrigins accepts exact origins, "*" and wildcard subdomains such
+	// as "https://*.example.com".
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+var rateLimitHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"}
+
+// IngestionCORSPolicy is for public event ingestion: any configured origin may
+// post events, but cookies and other credentials are never sent along.
+func IngestionCORSPolicy(prefix string, origins []string, maxAge time.Duration) CORSPolicy {
+	return CORSPolicy{
+		PathPrefix:     prefix,
+		AllowedOrigins: origins,
+		AllowedMethods: []string{http.MethodPost, http.MethodOptions},
+		AllowedHeaders: []string{"Authorization", "Content-Type", "Content-Encoding", "X-Write-Key"},
+		ExposedHeaders: rateLimitHeaders,
+		MaxAge:         maxAge,
+	}
+}
+
+// ManagementCORSPolicy is for authenticated account and management routes,
+// which are expected to be called from a restricted set of dashboard origins.
+// Because it allows credentials, a "*" origin is ignored rather than letting
+// any site make credentialed requests.
+func ManagementCORSPolicy(prefix string, origins []string, maxAge time.Duration) CORSPolicy {
+	return CORSPolicy{
+		PathPrefix:     prefix,
+		AllowedOrigins: origins,
+		AllowedMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   rateLimitHeaders,
+		AllowCredentials: true,
+		MaxAge:           maxAge,
+	}
+}
+
+// CORSMiddleware applies the first policy whose PathPrefix matches the request.
+// It must be installed on the engine rather than a group so that preflight
+// requests, which have no matching route, still reach it.
+func CORSMiddleware(policies ...CORSPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		policy, ok := matchPolicy(policies, c.Request.URL.Path)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if !policy.originAllowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if policy.AllowCredentials || !policy.allowsAnyOrigin() {
+			c.Header("Access-Control-Allow-Origin", origin)
+		} else {
+			c.Header("Access-Control-Allow-Origin", "*")
+		}
+		if policy.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(policy.ExposedHeaders) > 0 {
+				c.Header("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+			}
+			c.Next()
+			return
+		}
+
+		method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+		if !containsFold(policy.AllowedMethods, method) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+		if len(policy.AllowedHeaders) > 0 {
+			c.Header("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+		}
+		if policy.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func matchPolicy(policies []CORSPolicy, path string) (CORSPolicy, bool) {
+	for _, p := range policies {
+		if strings.HasPrefix(path, p.PathPrefix) {
+			return p, true
+		}
+	}
+	return CORSPolicy{}, false
+}
+
+func (p CORSPolicy) allowsAnyOrigin() bool {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) originAllowed(origin string) bool {
+	if !p.AllowCredentials {
+		return utils.OriginAllowed(p.AllowedOrigins, origin)
+	}
+	allowed := make([]string, 0, len(p.AllowedOrigins))
+	for _, o := range p.AllowedOrigins {
+		if strings.TrimSpace(o) != "*" {
+			allowed = append(allowed, o)
+		}
+	}
+	return utils.OriginAllowed(allowed, origin)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func corsPreflight(router *gin.Engine, path string, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSCredentialedPolicyIgnoresWildcard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware(
+		ManagementCORSPolicy("/api/v1/account", []string{"*", "https://app.example.com"}, 0),
+		IngestionCORSPolicy("/api/v1/event", []string{"*"}, 0),
+	))
+
+	if w := corsPreflight(router, "/api/v1/account", "https://evil.example"); w.Code != http.StatusForbidden {
+		t.Fatalf("credentialed preflight from unlisted origin = %d, want 403", w.Code)
+	}
+
+	w := corsPreflight(router, "/api/v1/account", "https://app.example.com")
+	if w.Code != http.StatusNoContent ||
+		w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("preflight from listed origin = %d %v", w.Code, w.Header())
+	}
+
+	w = corsPreflight(router, "/api/v1/event", "https://any.example")
+	if w.Code != http.StatusNoContent ||
+		w.Header().Get("Access-Control-Allow-Origin") != "*" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatalf("ingestion preflight = %d %v, want wildcard without credentials", w.Code, w.Header())
+	}
+}
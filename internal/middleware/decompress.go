@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog"
+)
+
+// DecompressMiddleware caps the raw request body at maxBody bytes and
+// transparently decodes gzip, deflate and zstd bodies, capping the decoded
+// stream at maxDecompressed bytes so a small compressed payload cannot expand
+// without bound. Exceeding either limit surfaces as *http.MaxBytesError from
+// the body reader, which handlers translate to 413.
+func DecompressMiddleware(maxBody, maxDecompressed int64, log zerolog.Logger) gin.HandlerFunc {
+	log = log.With().Str("middleware", "decompress").Logger()
+
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBody {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBody)
+
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" {
+			c.Next()
+			return
+		}
+
+		body, err := decodeBody(encoding, c.Request.Body, maxDecompressed)
+		if errors.Is(err, errUnsupportedEncoding) {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported Content-Encoding"})
+			return
+		}
+		if isBodyTooLarge(err) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		if err != nil {
+			log.Warn().Err(err).
+				Str("encoding", encoding).
+				Str("ip", c.ClientIP()).
+				Msg("Failed to open compressed request body")
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid compressed body"})
+			return
+		}
+		defer body.Close()
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, body, maxDecompressed)
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+
+		c.Next()
+	}
+}
+
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
+func decodeBody(encoding string, body io.Reader, maxDecompressed int64) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		return newDeflateReader(body)
+	case "zstd":
+		dec, err := zstd.NewReader(body,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(uint64(maxDecompressed)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &zstdReader{ReadCloser: dec.IOReadCloser(), limit: maxDecompressed}, nil
+	default:
+		return nil, errUnsupportedEncoding
+	}
+}
+
+// newDeflateReader accepts both zlib-wrapped deflate, which is what the HTTP
+// spec means by "deflate", and the raw deflate stream some clients send instead.
+func newDeflateReader(body io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// zstdReader reports frames the decoder refuses for exceeding its memory
+// limit as *http.MaxBytesError, like any other body over maxDecompressed.
+type zstdReader struct {
+	io.ReadCloser
+	limit int64
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if errors.Is(err, zstd.ErrWindowSizeExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		err = &http.MaxBytesError{Limit: r.limit}
+	}
+	return n, err
+}
+
+// isBodyTooLarge reports whether err came from a body capped by http.MaxBytesReader.
+func isBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
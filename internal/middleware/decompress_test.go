@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog"
+)
+
+// decompressRouter echoes the decoded request body, answering 413 when
+// reading it hits a limit the way the ingestion handlers do.
+func decompressRouter(maxBody, maxDecompressed int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(DecompressMiddleware(maxBody, maxDecompressed, zerolog.Nop()))
+	router.POST("/echo", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		c.Data(http.StatusOK, "text/plain", body)
+	})
+	return router
+}
+
+func postEncoded(router *gin.Engine, encoding string, body []byte, knownLength bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(body))
+	req.Header.Set("Content-Encoding", encoding)
+	if !knownLength {
+		req.ContentLength = -1
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = fw
+	case "zstd":
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = zw
+	default:
+		t.Fatalf("unknown test encoding %q", encoding)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressRoundTrips(t *testing.T) {
+	router := decompressRouter(1<<20, 1<<20)
+	payload := []byte(`{"event":"signup","properties":{"plan":"pro"}}`)
+
+	for _, tc := range []struct{ name, format, header string }{
+		{"gzip", "gzip", "gzip"},
+		{"zlib-wrapped deflate", "zlib", "deflate"},
+		{"raw deflate", "raw-deflate", "deflate"},
+		{"zstd", "zstd", "zstd"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := postEncoded(router, tc.header, compress(t, tc.format, payload), true)
+			if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), payload) {
+				t.Fatalf("%s body = %d %q, want 200 %q", tc.name, w.Code, w.Body.String(), payload)
+			}
+		})
+	}
+}
+
+func TestDecompressRejectsLargeCompressedBody(t *testing.T) {
+	router := decompressRouter(64, 1<<20)
+	// Random-looking input keeps the compressed body bigger than maxBody.
+	payload := make([]byte, 4096)
+	for i := range payload {
+		payload[i] = byte(i * 7919 >> 3)
+	}
+	body := compress(t, "gzip", payload)
+	if len(body) <= 64 {
+		t.Fatalf("compressed test body is only %d bytes", len(body))
+	}
+
+	if w := postEncoded(router, "gzip", body, true); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("compressed body over maxBody = %d, want 413", w.Code)
+	}
+	if w := postEncoded(router, "gzip", body, false); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("chunked compressed body over maxBody = %d, want 413", w.Code)
+	}
+}
+
+func TestDecompressRejectsExpansionPastLimit(t *testing.T) {
+	router := decompressRouter(1<<20, 1024)
+	payload := []byte(strings.Repeat("a", 1<<20))
+
+	for _, format := range []string{"gzip", "zlib", "zstd"} {
+		header := format
+		if format == "zlib" {
+			header = "deflate"
+		}
+		body := compress(t, format, payload)
+		if len(body) > 1<<14 {
+			t.Fatalf("%s test body is %d bytes, want a small one", format, len(body))
+		}
+		if w := postEncoded(router, header, body, true); w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("%s body expanding past maxDecompressed = %d, want 413", format, w.Code)
+		}
+	}
+}
+
+func TestDecompressRejectsUnknownEncoding(t *testing.T) {
+	router := decompressRouter(1<<20, 1<<20)
+
+	if w := postEncoded(router, "br", []byte("whatever"), true); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("Content-Encoding br = %d, want 415", w.Code)
+	}
+}
+
+func TestDecompressRejectsCorruptStream(t *testing.T) {
+	router := decompressRouter(1<<20, 1<<20)
+
+	if w := postEncoded(router, "gzip", []byte("not a gzip stream"), true); w.Code != http.StatusBadRequest {
+		t.Fatalf("corrupt gzip body = %d, want 400", w.Code)
+	}
+	// A valid header followed by garbage only fails once the body is read.
+	body := compress(t, "gzip", []byte(strings.Repeat("event ", 100)))
+	body = append(body[:12:12], bytes.Repeat([]byte{0xff}, 32)...)
+	if w := postEncoded(router, "gzip", body, true); w.Code != http.StatusBadRequest {
+		t.Fatalf("gzip body corrupt after the header = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type RateLimitRule struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitMiddleware limits requests per api key when one has been set by
+// AuthMiddleware, and per client IP otherwise. If Redis is unavailable the
+// request is let through rather than failing every call.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, rule RateLimitRule, log zerolog.Logger) gin.HandlerFunc {
+	log = log.With().Str("middleware", "ratelimit").Str("rule", rule.Name).Logger()
+
+	return func(c *gin.Context) {
+		identity := "ip:" + c.ClientIP()
+		if apiKey := c.GetString("api_key"); apiKey != "" {
+			identity = "key:" + apiKey
+		}
+		key := ratelimit.Key(rule.Name, identity)
+
+		res, err := limiter.Allow(c.Request.Context(), key, rule.Limit, rule.Window)
+		if err != nil {
+			log.Warn().Err(err).Str("ip", c.ClientIP()).Msg("Rate limiter unavailable, allowing request")
+			c.Next()
+			return
+		}
+
+		resetSecs := int(math.Ceil(res.Reset.Seconds()))
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(resetSecs))
+
+		if !res.Allowed {
+			log.Warn().
+				Str("ip", c.ClientIP()).
+				Str("path", c.Request.URL.Path).
+				Msg("Rate limit exceeded")
+			c.Header("Retry-After", strconv.Itoa(resetSecs))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			return
+		}
+
+		c.Next()
+	}
+}
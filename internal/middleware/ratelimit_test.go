@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/ratelimit"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func newRateLimitRouter(t *testing.T, trusted []string) (*gin.Engine, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	// No retries, so the fail-open test does not wait out the backoff.
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(trusted); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+	limit := RateLimitMiddleware(ratelimit.NewLimiter(client, zerolog.Nop()), RateLimitRule{
+		Name:   "test",
+		Limit:  1,
+		Window: time.Minute,
+	}, zerolog.Nop())
+	router.GET("/", limit, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, srv
+}
+
+func rateLimitedGet(router *gin.Engine, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddlewareLimitsPerIP(t *testing.T) {
+	router, _ := newRateLimitRouter(t, nil)
+
+	if w := rateLimitedGet(router, "198.51.100.1:1234", ""); w.Code != http.StatusOK {
+		t.Fatalf("first request = %d, want 200", w.Code)
+	}
+	w := rateLimitedGet(router, "198.51.100.1:1234", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("headers = %v, want Retry-After and RateLimit-Remaining 0", w.Header())
+	}
+	if w := rateLimitedGet(router, "198.51.100.2:1234", ""); w.Code != http.StatusOK {
+		t.Fatalf("request from another IP = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareIgnoresUntrustedForwardedFor(t *testing.T) {
+	router, _ := newRateLimitRouter(t, nil)
+
+	rateLimitedGet(router, "198.51.100.1:1234", "203.0.113.1")
+	if w := rateLimitedGet(router, "198.51.100.1:1234", "203.0.113.2"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Forwarded-For = %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareTrustsConfiguredProxies(t *testing.T) {
+	router, _ := newRateLimitRouter(t, []string{"10.0.0.0/8"})
+
+	rateLimitedGet(router, "10.0.0.1:1234", "203.0.113.1")
+	if w := rateLimitedGet(router, "10.0.0.1:1234", "203.0.113.2"); w.Code != http.StatusOK {
+		t.Fatalf("second client behind trusted proxy = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitMiddlewareFailsOpen(t *testing.T) {
+	router, srv := newRateLimitRouter(t, nil)
+	srv.Close()
+
+	for i := 0; i < 2; i++ {
+		if w := rateLimitedGet(router, "198.51.100.1:1234", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d with Redis down = %d, want 200", i, w.Code)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEntry records one security-relevant action. APIKey is the account
+// the entry belongs to; it and ActorID are empty when the action could not
+// be tied to an account, e.g. a signin with an unknown email.
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	APIKey     string    `json:"-"`
+	ActorID    string    `json:"actor_id,omitempty"`
+	Action     string    `json:"action"`
+	Target     string    `json:"target,omitempty"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	RequestID  string    `json:"request_id"`
+	Outcome    string    `json:"outcome"`
+	// Status is the HTTP status the request was answered with.
+	Status int `json:"status"`
+}
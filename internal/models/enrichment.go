@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EnrichmentSettings controls which enrichers the processor runs for a project.
+type EnrichmentSettings struct {
+	APIKey           string         `json:"-"`
+	Enrichers        []string       `json:"enrichers"`
+	StaticProperties map[string]any `json:"static_properties"`
+	UpdatedAt        *time.Time     `json:"updated_at,omitempty"`
+}
+
+func (s *EnrichmentSettings) IsEnabled(name string) bool {
+	for _, e := range s.Enrichers {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
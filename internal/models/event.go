@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Event is the queued representation of an accepted event, as written by
+// EventRepository and read by the processor.
+type Event struct {
+	ID        string         `json:"id"`
+	APIKey    string         `json:"api_key"`
+	Payload   map[string]any `json:"payload"`
+	Timestamp time.Time      `json:"timestamp"`
+	Context   EventContext   `json:"context"`
+	// DistinctID, PersonID and Routes are filled in by the processor;
+	// Routes are the destinations assigned by tenant rules.
+	DistinctID string   `json:"distinct_id,omitempty"`
+	PersonID   string   `json:"person_id,omitempty"`
+	Routes     []string `json:"routes,omitempty"`
+	// Attempts counts how often the processor has failed to handle the event.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// EventContext holds facts about the request that delivered an event, captured
+// server-side so clients cannot spoof them. It is used for enrichment and is
+// not stored with the event.
+type EventContext struct {
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
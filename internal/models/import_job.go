@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportStatusFailed     = "failed"
+)
+
+type ImportJob struct {
+	ID           string     `json:"id"`
+	APIKey       string     `json:"-"`
+	Format       string     `json:"format"`
+	Status       string     `json:"status"`
+	RowsTotal    int64      `json:"rows_total"`
+	RowsAccepted int64      `json:"rows_accepted"`
+	RowsFailed   int64      `json:"rows_failed"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+type ImportRowError struct {
+	Row   int64  `json:"row"`
+	Error string `json:"error"`
+}
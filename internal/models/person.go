@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Person struct {
+	ID          string         `json:"id"`
+	APIKey      string         `json:"-"`
+	Traits      map[string]any `json:"traits"`
+	DistinctIDs []string       `json:"distinct_ids"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
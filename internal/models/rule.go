@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Rule actions.
+const (
+	RuleActionDrop   = "drop"
+	RuleActionRename = "rename"
+	RuleActionRedact = "redact"
+	RuleActionHash   = "hash"
+	RuleActionSet    = "set"
+	RuleActionRoute  = "route"
+)
+
+// Rule is a tenant-defined transformation applied by the processor. Rules run
+// in Position order; Condition is an expression over the event and, when
+// empty, always matches.
+type Rule struct {
+	ID        string `json:"id"`
+	APIKey    string `json:"-"`
+	Name      string `json:"name"`
+	Position  int    `json:"position"`
+	Enabled   bool   `json:"enabled"`
+	Condition string `json:"condition,omitempty"`
+	Action    string `json:"action"`
+	// Field is a dot-separated payload path, e.g. "properties.email".
+	Field string `json:"field,omitempty"`
+	// Target is the new path for rename and the destination name for route.
+	Target string `json:"target,omitempty"`
+	// Value is the expression whose result set writes to Field.
+	Value     string    `json:"value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -9,6 +9,22 @@ type User struct {
 	Name       string    `json:"name"`
 	Api_Key    string    `json:"api_key"`
 	IsVerified bool      `json:"is_verified"`
+	Plan       string    `json:"plan"`
+	MFAEnabled bool      `json:"mfa_enabled"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	// MFASecret is the TOTP secret, set from enrollment on; MFALastStep is
+	// the last time step a code was accepted for.
+	MFASecret   string `json:"-"`
+	MFALastStep int64  `json:"-"`
+	// MFAChallenge is the ID of the MFA challenge that can complete the
+	// next signin; MFALockedUntil, if set, is when MFA codes are accepted
+	// again after too many invalid ones.
+	MFAChallenge   string     `json:"-"`
+	MFALockedUntil *time.Time `json:"-"`
+	// PendingEmail is an address the user has asked to change to; it
+	// replaces Email once confirmed.
+	PendingEmail string `json:"pending_email,omitempty"`
+	// TokenVersion is carried by the user's tokens; bumping it revokes them.
+	TokenVersion int `json:"-"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// WriteKey is a publishable, write-only key for browser and pixel ingestion.
+// Events sent with it are attributed to the owning user's api key.
+type WriteKey struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	Key            string     `json:"key"`
+	AllowedOrigins []string   `json:"allowed_origins"`
+	OwnerAPIKey    string     `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
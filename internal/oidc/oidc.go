@@ -0,0 +1,249 @@
+// Package oidc signs users in through OpenID Connect providers with the
+// authorization-code flow and PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// stateTTL is how long a user has to finish logging in at the provider.
+const stateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider = errors.New("unknown OIDC provider")
+	ErrInvalidState    = errors.New("invalid or expired OIDC login state")
+	ErrInvalidToken    = errors.New("invalid OIDC ID token")
+)
+
+type ProviderConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// Scopes are requested in addition to openid; defaults to email and profile.
+	Scopes []string
+}
+
+// Identity is who the provider says signed in.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Client runs logins against the configured providers. Each provider's
+// discovery document is fetched on first use, so the auth service starts
+// even while a provider is unreachable.
+type Client struct {
+	providers   map[string]*provider
+	stateSecret []byte
+}
+
+type provider struct {
+	name   string
+	cfg    ProviderConfig
+	oauth2 oauth2.Config
+
+	mu       sync.Mutex
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewClient redirects back to <redirectBaseURL>/api/v1/auth/oidc/<name>/callback.
+// Login state is signed with a key derived from secret.
+func NewClient(providers map[string]ProviderConfig, redirectBaseURL string, secret string) *Client {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("sync oidc login state"))
+
+	c := &Client{
+		providers:   make(map[string]*provider, len(providers)),
+		stateSecret: mac.Sum(nil),
+	}
+	base := strings.TrimRight(redirectBaseURL, "/")
+	for name, cfg := range providers {
+		scopes := cfg.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{"email", "profile"}
+		}
+		c.providers[name] = &provider{
+			name: name,
+			cfg:  cfg,
+			oauth2: oauth2.Config{
+				ClientID:     cfg.ClientID,
+				ClientSecret: cfg.ClientSecret,
+				RedirectURL:  fmt.Sprintf("%s/api/v1/auth/oidc/%s/callback", base, name),
+				Scopes:       append([]string{gooidc.ScopeOpenID}, scopes...),
+			},
+		}
+	}
+	return c
+}
+
+// Providers returns the configured provider names, sorted.
+func (c *Client) Providers() []string {
+	names := make([]string, 0, len(c.providers))
+	for name := range c.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Begin starts a login with the named provider. It returns the provider URL
+// to send the user to and an opaque state to hand back to Finish; the
+// caller keeps it in a cookie so the callback can be tied to this browser.
+func (c *Client) Begin(ctx context.Context, name string) (authURL string, state string, err error) {
+	p, err := c.provider(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	csrf, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	state, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"provider": name,
+		"state":    csrf,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(stateTTL).Unix(),
+	}).SignedString(c.stateSecret)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL = p.oauth2.AuthCodeURL(csrf, oauth2.S256ChallengeOption(verifier), gooidc.Nonce(nonce))
+	return authURL, state, nil
+}
+
+// Finish completes a login from the provider's callback: stateParam and
+// code are its query parameters and state is what Begin returned.
+func (c *Client) Finish(ctx context.Context, name string, state string, stateParam string, code string) (*Identity, error) {
+	p, err := c.provider(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := c.parseState(state)
+	if err != nil {
+		return nil, err
+	}
+	if claims["provider"] != name ||
+		subtle.ConstantTimeCompare([]byte(claims["state"]), []byte(stateParam)) != 1 {
+		return nil, ErrInvalidState
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(claims["verifier"]))
+	if err != nil {
+		return nil, fmt.Errorf("exchange authorization code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidToken)
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(claims["nonce"])) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+
+	var profile struct {
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&profile); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return &Identity{
+		Provider: name,
+		Subject:  idToken.Subject,
+		Email:    profile.Email,
+		// Some providers send the claim as a string.
+		EmailVerified: profile.EmailVerified == true || profile.EmailVerified == "true",
+		Name:          profile.Name,
+	}, nil
+}
+
+func (c *Client) parseState(state string) (map[string]string, error) {
+	token, err := jwt.Parse(state, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return c.stateSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidState
+	}
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidState
+	}
+
+	claims := make(map[string]string, 4)
+	for _, key := range []string{"provider", "state", "nonce", "verifier"} {
+		value, ok := mapClaims[key].(string)
+		if !ok || value == "" {
+			return nil, ErrInvalidState
+		}
+		claims[key] = value
+	}
+	return claims, nil
+}
+
+// provider returns the named provider, running discovery if it has not
+// succeeded yet.
+func (c *Client) provider(ctx context.Context, name string) (*provider, error) {
+	p, ok := c.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.verifier != nil {
+		return p, nil
+	}
+
+	discovered, err := gooidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discover OIDC provider %s: %w", name, err)
+	}
+	p.oauth2.Endpoint = discovered.Endpoint()
+	p.verifier = discovered.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	return p, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,102 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Vighnesh-V-H/sync/internal/oidc"
+	"github.com/Vighnesh-V-H/sync/internal/oidc/oidctest"
+)
+
+func newClient(t *testing.T, user oidctest.User) (*oidc.Client, *oidctest.Provider) {
+	provider := oidctest.New(t, user)
+	client := oidc.NewClient(map[string]oidc.ProviderConfig{
+		"mock": {
+			Issuer:       provider.Issuer(),
+			ClientID:     oidctest.ClientID,
+			ClientSecret: oidctest.ClientSecret,
+		},
+	}, "http://sync.test", "jwt-secret")
+	return client, provider
+}
+
+// authorize follows authURL to the provider and returns the callback's
+// query parameters.
+func authorize(t *testing.T, authURL string) url.Values {
+	t.Helper()
+	httpClient := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := httpClient.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", res.StatusCode)
+	}
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse callback: %v", err)
+	}
+	if callback.Path != "/api/v1/auth/oidc/mock/callback" {
+		t.Fatalf("redirected to %s", callback)
+	}
+	return callback.Query()
+}
+
+func TestLogin(t *testing.T) {
+	client, _ := newClient(t, oidctest.User{Subject: "sub-1", Email: "ada@example.com", EmailVerified: true, Name: "Ada"})
+	ctx := context.Background()
+
+	authURL, state, err := client.Begin(ctx, "mock")
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	callback := authorize(t, authURL)
+
+	id, err := client.Finish(ctx, "mock", state, callback.Get("state"), callback.Get("code"))
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	want := oidc.Identity{Provider: "mock", Subject: "sub-1", Email: "ada@example.com", EmailVerified: true, Name: "Ada"}
+	if *id != want {
+		t.Fatalf("identity %+v, want %+v", *id, want)
+	}
+}
+
+func TestFinishRejectsMismatchedState(t *testing.T) {
+	client, _ := newClient(t, oidctest.User{Subject: "sub-1", Email: "ada@example.com", EmailVerified: true})
+	ctx := context.Background()
+
+	authURL, state, err := client.Begin(ctx, "mock")
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	callback := authorize(t, authURL)
+
+	// A callback from another login attempt must not be accepted.
+	_, otherState, err := client.Begin(ctx, "mock")
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if _, err := client.Finish(ctx, "mock", otherState, callback.Get("state"), callback.Get("code")); !errors.Is(err, oidc.ErrInvalidState) {
+		t.Fatalf("Finish with another login's state = %v, want ErrInvalidState", err)
+	}
+	if _, err := client.Finish(ctx, "mock", state+"x", callback.Get("state"), callback.Get("code")); !errors.Is(err, oidc.ErrInvalidState) {
+		t.Fatalf("Finish with tampered state = %v, want ErrInvalidState", err)
+	}
+	if _, err := client.Finish(ctx, "mock", "", callback.Get("state"), callback.Get("code")); !errors.Is(err, oidc.ErrInvalidState) {
+		t.Fatalf("Finish without state = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestUnknownProvider(t *testing.T) {
+	client, _ := newClient(t, oidctest.User{Subject: "sub-1"})
+	if _, _, err := client.Begin(context.Background(), "nope"); !errors.Is(err, oidc.ErrUnknownProvider) {
+		t.Fatalf("Begin unknown provider = %v, want ErrUnknownProvider", err)
+	}
+}
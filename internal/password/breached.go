@@ -0,0 +1,132 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedList looks passwords up in a local copy of the Pwned Passwords
+// list, as written by the PwnedPasswordsDownloader in either layout: a
+// single file of "<SHA-1>:<count>" lines sorted by hash, or, with
+// --single false, a directory of "<first 5 hex digits>.txt" files of
+// "<remaining 35 hex digits>:<count>" lines. Only the hash of a password is
+// ever compared, and the list is never loaded into memory: the single file
+// is binary searched and only the one small file needed is read from a
+// directory, so the full list of several gigabytes can be used. It is safe
+// for concurrent use.
+type BreachedList struct {
+	// file and size are set for a single file, dir for a directory.
+	file *os.File
+	size int64
+	dir  string
+}
+
+// prefixLength is how many leading hex digits of a hash name its file in
+// the per-prefix layout.
+const prefixLength = 5
+
+func OpenBreachedList(path string) (*BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &BreachedList{dir: path}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &BreachedList{file: f, size: info.Size()}, nil
+}
+
+func (l *BreachedList) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// Contains reports whether password appears in the list.
+func (l *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	target := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if l.file == nil {
+		return l.containsInPrefixFile(target)
+	}
+
+	// Find the first line at or after an offset whose hash is >= target.
+	// Reads go through ReadAt, so concurrent lookups need no lock.
+	lo, hi := int64(0), l.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		hash, err := l.hashAt(mid)
+		if err != nil {
+			return false, err
+		}
+		if hash == "" || hash >= target {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	hash, err := l.hashAt(lo)
+	if err != nil {
+		return false, err
+	}
+	return hash == target, nil
+}
+
+// containsInPrefixFile scans the per-prefix file target falls in, which
+// holds around a thousand lines. A missing file means no breached password
+// has that prefix.
+func (l *BreachedList) containsInPrefixFile(target string) (bool, error) {
+	f, err := os.Open(filepath.Join(l.dir, target[:prefixLength]+".txt"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	suffix := target[prefixLength:]
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(hash, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// hashAt returns the hash on the first line starting at or after off, or
+// "" past the last line.
+func (l *BreachedList) hashAt(off int64) (string, error) {
+	start := off
+	if start > 0 {
+		// Back up one byte so a line starting exactly at off is not skipped.
+		start--
+	}
+	r := bufio.NewReaderSize(io.NewSectionReader(l.file, start, l.size-start), 256)
+	if off > 0 {
+		if _, err := r.ReadString('\n'); err == io.EOF {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash), nil
+}
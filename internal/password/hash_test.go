@@ -0,0 +1,75 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams keeps hashing fast in tests.
+var testParams = Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestHashVerify(t *testing.T) {
+	h := NewHasher(testParams)
+	encoded, err := h.Hash("correct-horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("encoded hash %q", encoded)
+	}
+
+	match, rehash, err := h.Verify(encoded, "correct-horse")
+	if err != nil || !match || rehash {
+		t.Fatalf("Verify = %v, %v, %v; want match without rehash", match, rehash, err)
+	}
+	if match, _, err := h.Verify(encoded, "wrong-horse"); err != nil || match {
+		t.Fatalf("Verify wrong password = %v, %v", match, err)
+	}
+}
+
+func TestVerifyFlagsOutdatedHashes(t *testing.T) {
+	h := NewHasher(testParams)
+
+	weaker, err := NewHasher(Params{Memory: 32, Iterations: 1, Parallelism: 1}).Hash("correct-horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if match, rehash, err := h.Verify(weaker, "correct-horse"); err != nil || !match || !rehash {
+		t.Fatalf("Verify with old parameters = %v, %v, %v; want match and rehash", match, rehash, err)
+	}
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct-horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	if match, rehash, err := h.Verify(string(legacy), "correct-horse"); err != nil || !match || !rehash {
+		t.Fatalf("Verify bcrypt = %v, %v, %v; want match and rehash", match, rehash, err)
+	}
+	if match, rehash, err := h.Verify(string(legacy), "wrong-horse"); err != nil || match || rehash {
+		t.Fatalf("Verify bcrypt wrong password = %v, %v, %v", match, rehash, err)
+	}
+}
+
+func TestVerifyRejectsMalformedHashes(t *testing.T) {
+	h := NewHasher(testParams)
+	if match, _, err := h.Verify("", "anything"); err != nil || match {
+		t.Fatalf("Verify empty hash = %v, %v; want no match", match, err)
+	}
+	for _, encoded := range []string{
+		"plaintext",
+		"$argon2id$v=19$m=64,t=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1048577,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=11,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=17$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=300$c2FsdA$a2V5",
+	} {
+		if _, _, err := h.Verify(encoded, "anything"); err == nil {
+			t.Errorf("Verify(%q) succeeded, want error", encoded)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+)
+
+// Policy decides which new passwords are acceptable. Breached is optional;
+// without it passwords are not checked against known breaches.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	Breached  *BreachedList
+}
+
+// Check returns an error wrapping ErrWeakPassword if password breaks the
+// policy for the account with email.
+func (p *Policy) Check(password string, email string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", internalErrors.ErrWeakPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", internalErrors.ErrWeakPassword, p.MaxLength)
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	candidate := strings.ToLower(strings.TrimSpace(password))
+	local, _, _ := strings.Cut(email, "@")
+	if candidate == email || candidate == local {
+		return fmt.Errorf("%w: must not be your email address", internalErrors.ErrWeakPassword)
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return fmt.Errorf("check breached passwords: %w", err)
+		}
+		if breached {
+			return fmt.Errorf("%w: appears in a known data breach", internalErrors.ErrWeakPassword)
+		}
+	}
+	return nil
+}
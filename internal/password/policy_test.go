@@ -0,0 +1,136 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+)
+
+// writeBreachedList writes passwords in the Pwned Passwords file format.
+func writeBreachedList(t *testing.T, passwords ...string) string {
+	t.Helper()
+	var lines []string
+	for i, p := range passwords {
+		sum := sha1.Sum([]byte(p))
+		lines = append(lines, fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(sum[:])), i+1))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+	return path
+}
+
+// writeBreachedDir writes passwords in the per-prefix layout, one file per
+// first five hex digits of the hash.
+func writeBreachedDir(t *testing.T, passwords ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string][]string{}
+	for i, p := range passwords {
+		sum := sha1.Sum([]byte(p))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		files[hash[:5]] = append(files[hash[:5]], fmt.Sprintf("%s:%d", hash[5:], i+1))
+	}
+	for prefix, lines := range files {
+		sort.Strings(lines)
+		if err := os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600); err != nil {
+			t.Fatalf("write list: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestBreachedList(t *testing.T) {
+	breached := []string{"password", "123456", "qwerty", "letmein", "correct-horse", "hunter2", "iloveyou"}
+
+	for name, path := range map[string]string{
+		"single file": writeBreachedList(t, breached...),
+		"per prefix":  writeBreachedDir(t, breached...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			list, err := OpenBreachedList(path)
+			if err != nil {
+				t.Fatalf("OpenBreachedList: %v", err)
+			}
+			defer list.Close()
+
+			for _, p := range breached {
+				if ok, err := list.Contains(p); err != nil || !ok {
+					t.Errorf("Contains(%q) = %v, %v; want true", p, ok, err)
+				}
+			}
+			for _, p := range []string{"Password", "battery-staple", "", "zzzzzzzz"} {
+				if ok, err := list.Contains(p); err != nil || ok {
+					t.Errorf("Contains(%q) = %v, %v; want false", p, ok, err)
+				}
+			}
+		})
+	}
+}
+
+func TestBreachedListConcurrentLookups(t *testing.T) {
+	breached := []string{"password", "123456", "qwerty", "letmein"}
+	list, err := OpenBreachedList(writeBreachedList(t, breached...))
+	if err != nil {
+		t.Fatalf("OpenBreachedList: %v", err)
+	}
+	defer list.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				p := breached[j%len(breached)]
+				if ok, err := list.Contains(p); err != nil || !ok {
+					t.Errorf("Contains(%q) = %v, %v; want true", p, ok, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPolicy(t *testing.T) {
+	list, err := OpenBreachedList(writeBreachedList(t, "password123"))
+	if err != nil {
+		t.Fatalf("OpenBreachedList: %v", err)
+	}
+	defer list.Close()
+	policy := &Policy{MinLength: 8, MaxLength: 64, Breached: list}
+
+	for _, tc := range []struct {
+		password string
+		ok       bool
+	}{
+		{"correct-horse", true},
+		{"short", false},
+		{strings.Repeat("a", 65), false},
+		{"password123", false},
+		{"Ada.Lovelace@Example.com", false},
+		{"ada.lovelace", false},
+		{"ada.lovelace!", true},
+	} {
+		err := policy.Check(tc.password, "ada.lovelace@example.com")
+		if tc.ok && err != nil {
+			t.Errorf("Check(%q) = %v, want ok", tc.password, err)
+		}
+		if !tc.ok && !errors.Is(err, internalErrors.ErrWeakPassword) {
+			t.Errorf("Check(%q) = %v, want ErrWeakPassword", tc.password, err)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/enrichment"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/rules"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const (
+	receiveTimeout = 5 * time.Second
+	errorBackoff   = time.Second
+	retryTimeout   = 5 * time.Second
+	// storeAttempts bounds how often one event's store write is tried before
+	// the event is handed back to the queue.
+	storeAttempts   = 5
+	maxStoreBackoff = 30 * time.Second
+)
+
+// Source hands out queued events. *repositories.EventRepository reads them
+// from whichever queue.Queue it was built with.
+type Source interface {
+	ReceiveEvent(ctx context.Context, timeout time.Duration) (*repositories.ReceivedEvent, error)
+}
+
+// Store persists processed events. *repositories.EventRepository stores them
+// in Postgres, trying once per call.
+type Store interface {
+	SaveProcessedEvent(ctx context.Context, ev *models.Event) error
+}
+
+// Rules hands out a project's compiled rules. *service.RuleService caches
+// them from Postgres.
+type Rules interface {
+	CachedRules(ctx context.Context, apiKey string) ([]*rules.Compiled, error)
+}
+
+// Deps are the processor's collaborators. Events and Store are required; any
+// other stage left nil is skipped, so the processor can run without Postgres
+// in development and tests.
+type Deps struct {
+	Events     Source
+	Store      Store
+	Identity   *service.IdentityService
+	Enrichment *service.EnrichmentService
+	Enrichers  *enrichment.Registry
+	Rules      Rules
+	Webhooks   *service.WebhookService
+}
+
+// Processor drains the event queue, resolves each event to a person,
+// enriches it, applies the project's rules, stores it and queues its webhooks.
+type Processor struct {
+	deps   Deps
+	logger zerolog.Logger
+	// backoff is the wait after a failed event, and the first wait between
+	// store attempts.
+	backoff time.Duration
+}
+
+func New(deps Deps, logger zerolog.Logger) *Processor {
+	return &Processor{
+		deps:    deps,
+		logger:  logger.With().Str("component", "processor").Logger(),
+		backoff: errorBackoff,
+	}
+}
+
+// Run processes events until ctx is cancelled. An event is acknowledged only
+// once it is stored (or dropped by a rule); failed events are requeued and
+// eventually dead-lettered.
+func (p *Processor) Run(ctx context.Context) error {
+	p.logger.Info().Msg("Processor started")
+
+	for {
+		if ctx.Err() != nil {
+			p.logger.Info().Msg("Processor stopped")
+			return nil
+		}
+
+		ev, err := p.deps.Events.ReceiveEvent(ctx, receiveTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			p.logger.Error().Err(err).Msg("Failed to read from event queue")
+			sleep(ctx, errorBackoff)
+			continue
+		}
+		if ev == nil {
+			continue
+		}
+
+		if err := p.process(ctx, ev.Event); err != nil {
+			p.logger.Error().Err(err).
+				Str("event_id", ev.ID).
+				Int("attempts", ev.Attempts+1).
+				Msg("Failed to process event")
+			p.retry(ctx, ev)
+			sleep(ctx, p.backoff)
+			continue
+		}
+		if err := ev.Ack(ctx); err != nil {
+			p.logger.Error().Err(err).
+				Str("event_id", ev.ID).
+				Msg("Failed to acknowledge event")
+		}
+	}
+}
+
+// retry hands a failed event back to the queue, even during shutdown, since
+// some backends have already removed it.
+func (p *Processor) retry(ctx context.Context, ev *repositories.ReceivedEvent) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), retryTimeout)
+	defer cancel()
+
+	if err := ev.Retry(ctx); err != nil {
+		p.logger.Error().Err(err).
+			Str("event_id", ev.ID).
+			Msg("Failed to requeue event")
+	}
+}
+
+// process runs ev through every stage. It returns an error only when the
+// event was not stored and must not be acknowledged.
+func (p *Processor) process(ctx context.Context, ev *models.Event) error {
+	if ev.Payload == nil {
+		ev.Payload = map[string]any{}
+	}
+
+	// An event that cannot be resolved is still stored, without a person,
+	// rather than lost.
+	if p.deps.Identity != nil {
+		if err := p.deps.Identity.Resolve(ctx, ev); err != nil {
+			p.logger.Warn().Err(err).
+				Str("event_id", ev.ID).
+				Msg("Storing event without identity")
+		}
+	}
+
+	p.enrich(ctx, ev)
+
+	dropped, err := p.dropped(ctx, ev)
+	if err != nil {
+		return err
+	}
+	if dropped {
+		return nil
+	}
+
+	if err := p.save(ctx, ev); err != nil {
+		return err
+	}
+
+	// A retried event is not stored twice, so failing here only queues its
+	// webhooks again.
+	if p.deps.Webhooks != nil {
+		if err := p.deps.Webhooks.EnqueueEvent(ctx, ev); err != nil {
+			return fmt.Errorf("failed to queue webhooks: %w", err)
+		}
+	}
+
+	p.logger.Debug().
+		Str("event_id", ev.ID).
+		Str("person_id", ev.PersonID).
+		Msg("Event processed")
+	return nil
+}
+
+// save stores ev, backing off between attempts so a Postgres outage pauses
+// processing instead of draining the queue into failed writes. This is the
+// only retry of the write: Store makes a single attempt per call.
+func (p *Processor) save(ctx context.Context, ev *models.Event) error {
+	delay := p.backoff
+	for attempt := 1; ; attempt++ {
+		err := p.deps.Store.SaveProcessedEvent(ctx, ev)
+		if err == nil || attempt >= storeAttempts || ctx.Err() != nil {
+			return err
+		}
+		p.logger.Warn().Err(err).
+			Str("event_id", ev.ID).
+			Int("attempt", attempt).
+			Msg("Failed to store event, retrying")
+		sleep(ctx, delay)
+		delay = min(delay*2, maxStoreBackoff)
+	}
+}
+
+func (p *Processor) enrich(ctx context.Context, ev *models.Event) {
+	if p.deps.Enrichment == nil || p.deps.Enrichers == nil {
+		return
+	}
+
+	settings, err := p.deps.Enrichment.CachedSettings(ctx, ev.APIKey)
+	if err != nil {
+		p.logger.Warn().Err(err).
+			Str("event_id", ev.ID).
+			Msg("Storing event without enrichment")
+		return
+	}
+	p.deps.Enrichers.Apply(ctx, ev, settings)
+}
+
+// dropped applies the project's rules and reports whether one dropped the
+// event. Rules see enriched events, so they can filter on e.g. $geo. If the
+// rules cannot be loaded the event fails rather than being stored unfiltered.
+func (p *Processor) dropped(ctx context.Context, ev *models.Event) (bool, error) {
+	if p.deps.Rules == nil {
+		return false, nil
+	}
+
+	compiled, err := p.deps.Rules.CachedRules(ctx, ev.APIKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	res := rules.Apply(ev, compiled)
+	for _, ruleErr := range res.Errors {
+		p.logger.Warn().
+			Str("event_id", ev.ID).
+			Str("rule_id", ruleErr.RuleID).
+			Str("error", ruleErr.Error).
+			Msg("Rule failed")
+	}
+	if res.Dropped {
+		p.logger.Debug().
+			Str("event_id", ev.ID).
+			Str("rule_id", res.DroppedBy).
+			Msg("Event dropped by rule")
+	}
+	return res.Dropped, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
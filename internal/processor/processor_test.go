@@ -0,0 +1,120 @@
+package processor_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/processor"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/routes"
+	"github.com/Vighnesh-V-H/sync/internal/service"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const testSecret = "test-secret"
+
+type memoryStore struct {
+	mu     sync.Mutex
+	events []*models.Event
+	saved  chan struct{}
+}
+
+func (s *memoryStore) SaveProcessedEvent(_ context.Context, ev *models.Event) error {
+	s.mu.Lock()
+	s.events = append(s.events, ev)
+	s.mu.Unlock()
+	s.saved <- struct{}{}
+	return nil
+}
+
+// TestIngestToProcess sends events through the HTTP API and checks the
+// processor stores each one exactly once, using only in-memory backends.
+func TestIngestToProcess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := zerolog.Nop()
+
+	q := queue.NewMemoryQueue()
+	defer q.Close()
+	eventRepo := repositories.NewEventRepository(nil, dedup.NewMemoryStore(), q, log)
+	eventSvc := service.NewEventService(eventRepo, nil, log)
+
+	router := gin.New()
+	routes.SetupEventRoutes(router.Group("/api/v1"), handler.NewEventHandler(eventSvc, log), middleware.AuthMiddleware(testSecret, nil))
+
+	store := &memoryStore{saved: make(chan struct{}, 10)}
+	proc := processor.New(processor.Deps{Events: eventRepo, Store: store}, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		proc.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	token, err := utils.GenerateJWT("user-1", "user@example.com", "key-1", 0, utils.JWTConfig{Secret: testSecret, Expiry: time.Hour})
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/event/add", bytes.NewBufferString(`{"payload":{"event":"signup"}}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test-agent")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /event/add = %d: %s", rec.Code, rec.Body)
+	}
+
+	// A retried client-side ID is queued once.
+	for range 2 {
+		if _, err := eventSvc.AddEventWithID(ctx, "key-1", "fixed-id", service.AddEventRequest{
+			Payload: map[string]any{"event": "retry"},
+		}); err != nil {
+			t.Fatalf("AddEventWithID: %v", err)
+		}
+	}
+
+	for range 2 {
+		select {
+		case <-store.saved:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the processor")
+		}
+	}
+	select {
+	case <-store.saved:
+		t.Fatal("duplicate event was processed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	first := store.events[0]
+	if first.APIKey != "key-1" || first.Payload["event"] != "signup" {
+		t.Fatalf("stored event = %+v", first)
+	}
+	if first.Context.UserAgent != "test-agent" {
+		t.Fatalf("user agent = %q, want test-agent", first.Context.UserAgent)
+	}
+	if store.events[1].ID != "fixed-id" {
+		t.Fatalf("second event ID = %q, want fixed-id", store.events[1].ID)
+	}
+}
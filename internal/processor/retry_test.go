@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/rules"
+	"github.com/rs/zerolog"
+)
+
+type failingStore struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (s *failingStore) SaveProcessedEvent(context.Context, *models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("postgres down")
+	}
+	return nil
+}
+
+func (s *failingStore) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestProcessRetriesStoreFailures(t *testing.T) {
+	store := &failingStore{failures: storeAttempts - 1}
+	p := New(Deps{Store: store}, zerolog.Nop())
+	p.backoff = time.Millisecond
+
+	if err := p.process(t.Context(), &models.Event{ID: "e1"}); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if store.calls != storeAttempts {
+		t.Fatalf("store called %d times, want %d", store.calls, storeAttempts)
+	}
+}
+
+func TestProcessReportsStoreFailure(t *testing.T) {
+	store := &failingStore{failures: storeAttempts}
+	p := New(Deps{Store: store}, zerolog.Nop())
+	p.backoff = time.Millisecond
+
+	if err := p.process(t.Context(), &models.Event{ID: "e1"}); err == nil {
+		t.Fatal("process succeeded although the event was never stored")
+	}
+	if store.calls != storeAttempts {
+		t.Fatalf("store called %d times, want %d", store.calls, storeAttempts)
+	}
+}
+
+type failingRules struct{}
+
+func (failingRules) CachedRules(context.Context, string) ([]*rules.Compiled, error) {
+	return nil, errors.New("postgres down")
+}
+
+// TestProcessFailsWithoutRules checks an event whose rules cannot be loaded
+// is not stored unfiltered.
+func TestProcessFailsWithoutRules(t *testing.T) {
+	store := &failingStore{}
+	p := New(Deps{Store: store, Rules: failingRules{}}, zerolog.Nop())
+
+	if err := p.process(t.Context(), &models.Event{ID: "e1"}); err == nil {
+		t.Fatal("process succeeded without rules")
+	}
+	if store.calls != 0 {
+		t.Fatalf("store called %d times, want 0", store.calls)
+	}
+}
+
+// TestRunRequeuesFailedEvents checks an event that fails is not acknowledged
+// and lost: it is received again and stored once the store recovers.
+func TestRunRequeuesFailedEvents(t *testing.T) {
+	q := queue.NewMemoryQueue()
+	events := repositories.NewEventRepository(nil, dedup.NewMemoryStore(), q, zerolog.Nop())
+	store := &failingStore{failures: storeAttempts}
+
+	runUntil(t, events, store, func() bool { return store.callCount() > storeAttempts })
+
+	if calls := store.callCount(); calls != storeAttempts+1 {
+		t.Fatalf("store called %d times, want %d", calls, storeAttempts+1)
+	}
+	if n := q.Len(); n != 0 {
+		t.Fatalf("%d events left queued, want 0", n)
+	}
+	if dead := q.DeadLetters(); len(dead) != 0 {
+		t.Fatalf("%d events dead-lettered, want 0", len(dead))
+	}
+}
+
+// TestRunDeadLettersEventsThatKeepFailing checks an event is moved aside
+// rather than retried forever.
+func TestRunDeadLettersEventsThatKeepFailing(t *testing.T) {
+	q := queue.NewMemoryQueue()
+	events := repositories.NewEventRepository(nil, dedup.NewMemoryStore(), q, zerolog.Nop())
+	store := &failingStore{failures: 1 << 30}
+
+	runUntil(t, events, store, func() bool { return len(q.DeadLetters()) > 0 })
+
+	if n := q.Len(); n != 0 {
+		t.Fatalf("%d events left queued, want 0", n)
+	}
+	var dead models.Event
+	if err := json.Unmarshal(q.DeadLetters()[0].Value, &dead); err != nil {
+		t.Fatalf("dead letter is not an event: %v", err)
+	}
+	if dead.ID != "e1" {
+		t.Fatalf("dead-lettered event %q, want e1", dead.ID)
+	}
+}
+
+// runUntil queues one event and runs the processor until done reports true.
+func runUntil(t *testing.T, events *repositories.EventRepository, store *failingStore, done func() bool) {
+	t.Helper()
+
+	if _, err := events.AddEvent(t.Context(), "key-1", "e1", map[string]any{"event": "signup"}, models.EventContext{}); err != nil {
+		t.Fatalf("AddEvent: %v", err)
+	}
+
+	p := New(Deps{Events: events, Store: store}, zerolog.Nop())
+	p.backoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(t.Context())
+	stopped := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the processor")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	// kafkaDeliveryTimeout bounds how long Publish waits for a record to be
+	// acknowledged, retries included, so a struggling cluster fails the
+	// publish (and the events service spills to its WAL) instead of holding
+	// requests open.
+	kafkaDeliveryTimeout = 10 * time.Second
+	kafkaRecordRetries   = 5
+	// kafkaPollBatch is how many records one fetch hands to Receive.
+	kafkaPollBatch = 500
+	// kafkaDeadLetterSuffix names the topic dead-lettered records go to.
+	kafkaDeadLetterSuffix = ".dead"
+)
+
+type KafkaOptions struct {
+	Brokers []string
+	Topic   string
+	// Group is the consumer group. Leave it empty for publish-only clients
+	// such as the events service.
+	Group string
+}
+
+// KafkaQueue produces records keyed by tenant, so the default partitioner
+// keeps each tenant's events on one partition and in order. Consumers commit
+// offsets only for settled records, giving at-least-once delivery. A retried
+// record is produced again at the end of its partition.
+type KafkaQueue struct {
+	client *kgo.Client
+	topic  string
+	group  string
+	logger zerolog.Logger
+
+	// fetched holds polled records not yet handed out by Receive.
+	mu      sync.Mutex
+	fetched []*kgo.Record
+}
+
+func NewKafkaQueue(opts KafkaOptions, logger zerolog.Logger) (*KafkaQueue, error) {
+	if len(opts.Brokers) == 0 {
+		return nil, errors.New("kafka queue requires at least one broker")
+	}
+	if opts.Topic == "" {
+		return nil, errors.New("kafka queue requires a topic")
+	}
+
+	kopts := []kgo.Opt{
+		kgo.SeedBrokers(opts.Brokers...),
+		kgo.DefaultProduceTopic(opts.Topic),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordDeliveryTimeout(kafkaDeliveryTimeout),
+		kgo.RecordRetries(kafkaRecordRetries),
+		kgo.ProduceRequestTimeout(kafkaDeliveryTimeout),
+	}
+	if opts.Group != "" {
+		kopts = append(kopts,
+			kgo.ConsumerGroup(opts.Group),
+			kgo.ConsumeTopics(opts.Topic),
+			kgo.AutoCommitMarks(),
+		)
+	}
+
+	client, err := kgo.NewClient(kopts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to reach kafka: %w", err)
+	}
+
+	logger.Info().
+		Strs("brokers", opts.Brokers).
+		Str("topic", opts.Topic).
+		Str("group", opts.Group).
+		Msg("Connected to Kafka")
+
+	return &KafkaQueue{
+		client: client,
+		topic:  opts.Topic,
+		group:  opts.Group,
+		logger: logger.With().Str("component", "kafka_queue").Logger(),
+	}, nil
+}
+
+func (q *KafkaQueue) Publish(ctx context.Context, msgs ...Message) error {
+	records := make([]*kgo.Record, 0, len(msgs))
+	for _, msg := range msgs {
+		records = append(records, &kgo.Record{Key: []byte(msg.Key), Value: msg.Value})
+	}
+	return q.client.ProduceSync(ctx, records...).FirstErr()
+}
+
+func (q *KafkaQueue) Receive(ctx context.Context, timeout time.Duration) (*Delivery, error) {
+	if q.group == "" {
+		return nil, errors.New("kafka queue has no consumer group")
+	}
+
+	if rec := q.next(); rec != nil {
+		return q.delivery(rec), nil
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	fetches := q.client.PollRecords(pollCtx, kafkaPollBatch)
+	if fetches.IsClientClosed() {
+		return nil, errors.New("kafka client closed")
+	}
+	for _, fe := range fetches.Errors() {
+		if errors.Is(fe.Err, context.DeadlineExceeded) || errors.Is(fe.Err, context.Canceled) {
+			continue
+		}
+		return nil, fmt.Errorf("kafka fetch from %s/%d: %w", fe.Topic, fe.Partition, fe.Err)
+	}
+
+	q.mu.Lock()
+	q.fetched = append(q.fetched, fetches.Records()...)
+	q.mu.Unlock()
+
+	if rec := q.next(); rec != nil {
+		return q.delivery(rec), nil
+	}
+	return nil, nil
+}
+
+func (q *KafkaQueue) next() *kgo.Record {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.fetched) == 0 {
+		return nil
+	}
+	rec := q.fetched[0]
+	q.fetched[0] = nil
+	q.fetched = q.fetched[1:]
+	return rec
+}
+
+func (q *KafkaQueue) delivery(rec *kgo.Record) *Delivery {
+	return &Delivery{
+		Message: Message{Key: string(rec.Key), Value: rec.Value},
+		ack: func(context.Context) error {
+			q.client.MarkCommitRecords(rec)
+			return nil
+		},
+		retry: func(ctx context.Context, msg Message) error {
+			return q.client.ProduceSync(ctx, &kgo.Record{Key: []byte(msg.Key), Value: msg.Value}).FirstErr()
+		},
+		deadLetter: func(ctx context.Context) error {
+			return q.client.ProduceSync(ctx, &kgo.Record{
+				Topic: q.topic + kafkaDeadLetterSuffix,
+				Key:   rec.Key,
+				Value: rec.Value,
+			}).FirstErr()
+		},
+	}
+}
+
+// Close commits acknowledged offsets before leaving the group.
+func (q *KafkaQueue) Close() error {
+	var err error
+	if q.group != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = q.client.CommitMarkedOffsets(ctx)
+	}
+	q.client.Close()
+	return err
+}
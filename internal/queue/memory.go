@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrQueueClosed = errors.New("queue closed")
+
+// MemoryQueue is an unbounded in-process FIFO for development and tests. It
+// only connects publishers and consumers in the same process.
+type MemoryQueue struct {
+	mu     sync.Mutex
+	msgs   []Message
+	dead   []Message
+	notify chan struct{}
+	closed bool
+	// done is closed by Close to wake every blocked receiver.
+	done chan struct{}
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{notify: make(chan struct{}, 1), done: make(chan struct{})}
+}
+
+func (q *MemoryQueue) Publish(_ context.Context, msgs ...Message) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrQueueClosed
+	}
+	q.msgs = append(q.msgs, msgs...)
+	q.mu.Unlock()
+
+	q.wake()
+	return nil
+}
+
+// requeue appends msg even after Close, so a message being handled while
+// the queue closes is still drained.
+func (q *MemoryQueue) requeue(msg Message) {
+	q.mu.Lock()
+	q.msgs = append(q.msgs, msg)
+	q.mu.Unlock()
+
+	q.wake()
+}
+
+func (q *MemoryQueue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *MemoryQueue) Receive(ctx context.Context, timeout time.Duration) (*Delivery, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		q.mu.Lock()
+		if len(q.msgs) > 0 {
+			msg := q.msgs[0]
+			q.msgs = q.msgs[1:]
+			remaining := len(q.msgs)
+			q.mu.Unlock()
+			// Pass the wake-up on so a second consumer does not sleep
+			// while messages are waiting.
+			if remaining > 0 {
+				q.wake()
+			}
+			return &Delivery{
+				Message: msg,
+				retry: func(_ context.Context, retried Message) error {
+					q.requeue(retried)
+					return nil
+				},
+				deadLetter: func(context.Context) error {
+					q.mu.Lock()
+					q.dead = append(q.dead, msg)
+					q.mu.Unlock()
+					return nil
+				},
+			}, nil
+		}
+		closed := q.closed
+		q.mu.Unlock()
+		if closed {
+			return nil, ErrQueueClosed
+		}
+
+		select {
+		case <-q.notify:
+		case <-q.done:
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Len returns the number of messages waiting.
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.msgs)
+}
+
+// DeadLetters returns the messages dead-lettered so far.
+func (q *MemoryQueue) DeadLetters() []Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]Message(nil), q.dead...)
+}
+
+// Close makes further Publish calls fail and wakes blocked receivers once
+// the queue has drained.
+func (q *MemoryQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.done)
+	}
+	return nil
+}
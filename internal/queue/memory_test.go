@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestMemoryQueueFIFO(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	if err := q.Publish(ctx, Message{Value: []byte("a")}, Message{Value: []byte("b")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	for _, want := range []string{"a", "b"} {
+		d, err := q.Receive(ctx, time.Second)
+		if err != nil {
+			t.Fatalf("Receive: %v", err)
+		}
+		if d == nil || string(d.Value) != want {
+			t.Fatalf("Receive = %v, want %q", d, want)
+		}
+		if err := d.Ack(ctx); err != nil {
+			t.Fatalf("Ack: %v", err)
+		}
+	}
+	if n := q.Len(); n != 0 {
+		t.Fatalf("Len = %d, want 0", n)
+	}
+}
+
+func TestMemoryQueueReceiveTimeout(t *testing.T) {
+	q := NewMemoryQueue()
+
+	d, err := q.Receive(context.Background(), 10*time.Millisecond)
+	if err != nil || d != nil {
+		t.Fatalf("Receive on empty queue = %v, %v; want nil, nil", d, err)
+	}
+}
+
+func TestMemoryQueueWakesBlockedReceiver(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	got := make(chan *Delivery, 1)
+	go func() {
+		d, _ := q.Receive(ctx, 5*time.Second)
+		got <- d
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := q.Publish(ctx, Message{Value: []byte("x")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case d := <-got:
+		if d == nil || string(d.Value) != "x" {
+			t.Fatalf("Receive = %v, want x", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked receiver was not woken by Publish")
+	}
+}
+
+func TestMemoryQueueClose(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	if err := q.Publish(ctx, Message{Value: []byte("left over")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	q.Close()
+
+	if err := q.Publish(ctx, Message{}); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("Publish after Close = %v, want ErrQueueClosed", err)
+	}
+	if d, err := q.Receive(ctx, time.Second); err != nil || d == nil {
+		t.Fatalf("Receive after Close = %v, %v; want the queued message", d, err)
+	}
+	if _, err := q.Receive(ctx, time.Second); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("Receive on drained closed queue = %v, want ErrQueueClosed", err)
+	}
+}
+
+func TestMemoryQueueReceiveCancelled(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := q.Receive(ctx, time.Second); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Receive with cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestMemoryQueueCloseWakesEveryReceiver(t *testing.T) {
+	q := NewMemoryQueue()
+
+	const receivers = 3
+	errs := make(chan error, receivers)
+	for range receivers {
+		go func() {
+			_, err := q.Receive(context.Background(), 5*time.Second)
+			errs <- err
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+
+	for range receivers {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrQueueClosed) {
+				t.Fatalf("Receive = %v, want ErrQueueClosed", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("a blocked receiver was not woken by Close")
+		}
+	}
+}
+
+func TestOpenRefusesMemoryBackend(t *testing.T) {
+	if _, err := Open(Options{Backend: BackendMemory}, zerolog.Nop()); err == nil {
+		t.Fatal("Open accepted the memory backend")
+	}
+}
@@ -0,0 +1,91 @@
+// Package queue abstracts the event queue between the events service and
+// the processor.
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	BackendRedis  = "redis"
+	BackendKafka  = "kafka"
+	BackendMemory = "memory"
+)
+
+// Message is a queued event. Key is the tenant's api key; backends that
+// partition use it so each tenant's events stay in order.
+type Message struct {
+	Key   string
+	Value []byte
+}
+
+// Delivery is a received message. Exactly one of Ack, Retry or DeadLetter
+// must be called once the message has been handled; backends that track
+// consumer progress only advance past messages settled this way.
+type Delivery struct {
+	Message
+	ack        func(ctx context.Context) error
+	retry      func(ctx context.Context, msg Message) error
+	deadLetter func(ctx context.Context) error
+}
+
+func (d *Delivery) Ack(ctx context.Context) error {
+	if d.ack == nil {
+		return nil
+	}
+	return d.ack(ctx)
+}
+
+// Retry queues msg, normally the delivered message with updated contents, to
+// be received again and settles this delivery.
+func (d *Delivery) Retry(ctx context.Context, msg Message) error {
+	if err := d.retry(ctx, msg); err != nil {
+		return err
+	}
+	return d.Ack(ctx)
+}
+
+// DeadLetter moves the message to the backend's dead-letter queue, where it
+// is kept for inspection but never received again, and settles this delivery.
+func (d *Delivery) DeadLetter(ctx context.Context) error {
+	if err := d.deadLetter(ctx); err != nil {
+		return err
+	}
+	return d.Ack(ctx)
+}
+
+type Queue interface {
+	Publish(ctx context.Context, msgs ...Message) error
+	// Receive blocks for up to timeout and returns nil, nil if nothing arrived.
+	Receive(ctx context.Context, timeout time.Duration) (*Delivery, error)
+	Close() error
+}
+
+// Options selects and configures a backend. Redis is used by the redis
+// backend; the Kafka fields by the kafka backend.
+type Options struct {
+	Backend string
+	Redis   *redis.Client
+	Kafka   KafkaOptions
+}
+
+// Open returns the configured backend for the standalone services. The
+// memory backend is refused: it only connects a producer and consumer in the
+// same process, so cmd/dev builds it directly with NewMemoryQueue.
+func Open(opts Options, logger zerolog.Logger) (Queue, error) {
+	switch opts.Backend {
+	case "", BackendRedis:
+		return NewRedisQueue(opts.Redis, DefaultRedisKey), nil
+	case BackendKafka:
+		return NewKafkaQueue(opts.Kafka, logger)
+	case BackendMemory:
+		return nil, fmt.Errorf("queue backend %q only works within cmd/dev", opts.Backend)
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q", opts.Backend)
+	}
+}
@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisKey is the list events have always been queued on.
+const DefaultRedisKey = "events:queue"
+
+// deadLetterSuffix names the list dead-lettered messages are pushed onto.
+const deadLetterSuffix = ":dead"
+
+// RedisQueue is a Redis list: RPUSH to publish, BLPOP to receive. It keeps
+// a single global order, and a message is gone once popped, so Ack is a no-op
+// and Retry pushes the message back onto the end of the list.
+type RedisQueue struct {
+	client *redis.Client
+	key    string
+}
+
+func NewRedisQueue(client *redis.Client, key string) *RedisQueue {
+	return &RedisQueue{client: client, key: key}
+}
+
+func (q *RedisQueue) Publish(ctx context.Context, msgs ...Message) error {
+	if len(msgs) == 1 {
+		return q.client.RPush(ctx, q.key, msgs[0].Value).Err()
+	}
+
+	pipe := q.client.Pipeline()
+	for _, msg := range msgs {
+		pipe.RPush(ctx, q.key, msg.Value)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (q *RedisQueue) Receive(ctx context.Context, timeout time.Duration) (*Delivery, error) {
+	res, err := q.client.BLPop(ctx, timeout, q.key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// BLPOP returns [key, value].
+	value := []byte(res[1])
+	return &Delivery{
+		Message: Message{Value: value},
+		retry: func(ctx context.Context, msg Message) error {
+			return q.client.RPush(ctx, q.key, msg.Value).Err()
+		},
+		deadLetter: func(ctx context.Context) error {
+			return q.client.RPush(ctx, q.key+deadLetterSuffix, value).Err()
+		},
+	}, nil
+}
+
+// Close leaves the client open; it is shared with the rest of the service.
+func (q *RedisQueue) Close() error {
+	return nil
+}
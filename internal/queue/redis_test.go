@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisQueueRetryAndDeadLetter(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	q := NewRedisQueue(client, DefaultRedisKey)
+	ctx := context.Background()
+
+	if err := q.Publish(ctx, Message{Value: []byte("a")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	d, err := q.Receive(ctx, time.Second)
+	if err != nil || d == nil {
+		t.Fatalf("Receive = %v, %v", d, err)
+	}
+	if err := d.Retry(ctx, Message{Value: []byte("a, retried")}); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+
+	d, err = q.Receive(ctx, time.Second)
+	if err != nil || d == nil || string(d.Value) != "a, retried" {
+		t.Fatalf("Receive after Retry = %v, %v; want the retried message", d, err)
+	}
+	if err := d.DeadLetter(ctx); err != nil {
+		t.Fatalf("DeadLetter: %v", err)
+	}
+
+	if d, err := q.Receive(ctx, 10*time.Millisecond); err != nil || d != nil {
+		t.Fatalf("Receive after DeadLetter = %v, %v; want nothing", d, err)
+	}
+	dead, err := client.LRange(ctx, DefaultRedisKey+deadLetterSuffix, 0, -1).Result()
+	if err != nil || len(dead) != 1 || dead[0] != "a, retried" {
+		t.Fatalf("dead letters = %v, %v", dead, err)
+	}
+}
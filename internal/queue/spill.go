@@ -0,0 +1,172 @@
+package queue
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/wal"
+	"github.com/rs/zerolog"
+)
+
+const replayBatchSize = 100
+
+// spillMetrics is published at /debug/vars as "queue_spill".
+var spillMetrics = expvar.NewMap("queue_spill")
+
+// SpillQueue puts a local WAL in front of another queue. Publish falls back
+// to the WAL when the backend fails, and while anything is spilled new
+// messages are appended behind it so replay preserves the original order.
+// Replaying is at-least-once: a message can be republished after a crash, so
+// consumers must tolerate duplicates (the processor stores events by ID).
+type SpillQueue struct {
+	Queue
+
+	wal            *wal.Log
+	replayInterval time.Duration
+	logger         zerolog.Logger
+}
+
+func NewSpillQueue(backend Queue, log *wal.Log, replayInterval time.Duration, logger zerolog.Logger) *SpillQueue {
+	spillMetrics.Set("pending_bytes", expvar.Func(func() any { return log.Pending() }))
+	spillMetrics.Set("disk_bytes", expvar.Func(func() any { return log.Size() }))
+	spillMetrics.Set("segments", expvar.Func(func() any { return log.Segments() }))
+
+	return &SpillQueue{
+		Queue:          backend,
+		wal:            log,
+		replayInterval: replayInterval,
+		logger:         logger.With().Str("component", "queue_spill").Logger(),
+	}
+}
+
+// Publish succeeds once msgs are either on the backend or fsynced to the WAL.
+// It only fails if both are unavailable or the WAL is full.
+func (q *SpillQueue) Publish(ctx context.Context, msgs ...Message) error {
+	if q.wal.Pending() == 0 {
+		err := q.Queue.Publish(ctx, msgs...)
+		if err == nil {
+			return nil
+		}
+		q.logger.Warn().Err(err).
+			Int("messages", len(msgs)).
+			Msg("Queue backend unavailable, spilling to WAL")
+	}
+
+	records := make([][]byte, len(msgs))
+	for i, msg := range msgs {
+		records[i] = encodeMessage(msg)
+	}
+	if err := q.wal.Append(records...); err != nil {
+		if errors.Is(err, wal.ErrFull) {
+			spillMetrics.Add("rejected_messages", int64(len(msgs)))
+		}
+		q.logger.Error().Err(err).
+			Int("messages", len(msgs)).
+			Msg("Failed to spill messages to WAL")
+		return err
+	}
+	spillMetrics.Add("spilled_messages", int64(len(msgs)))
+	return nil
+}
+
+// Run replays the WAL into the backend every replayInterval until ctx is
+// cancelled. Anything left over from a previous run is replayed first.
+func (q *SpillQueue) Run(ctx context.Context) error {
+	ticker := time.NewTicker(q.replayInterval)
+	defer ticker.Stop()
+
+	for {
+		q.replay(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// replay drains the WAL in batches and stops at the first backend failure,
+// leaving the failed batch to be retried on the next tick.
+func (q *SpillQueue) replay(ctx context.Context) {
+	if q.wal.Pending() == 0 {
+		return
+	}
+
+	var replayed int
+	for ctx.Err() == nil {
+		records, cursor, readErr := q.wal.Read(replayBatchSize)
+		if errors.Is(readErr, wal.ErrClosed) {
+			return
+		}
+		if readErr != nil {
+			spillMetrics.Add("corrupt_segments", 1)
+			q.logger.Error().Err(readErr).Msg("Skipping unreadable WAL records")
+		}
+		if len(records) == 0 && readErr == nil {
+			break
+		}
+
+		msgs := make([]Message, 0, len(records))
+		for _, rec := range records {
+			msg, err := decodeMessage(rec)
+			if err != nil {
+				q.logger.Error().Err(err).Msg("Dropping malformed WAL record")
+				continue
+			}
+			msgs = append(msgs, msg)
+		}
+
+		if len(msgs) > 0 {
+			if err := q.Queue.Publish(ctx, msgs...); err != nil {
+				q.logger.Warn().Err(err).
+					Int64("pending_bytes", q.wal.Pending()).
+					Msg("Queue backend still unavailable, will retry WAL replay")
+				return
+			}
+		}
+		if err := q.wal.Commit(cursor); err != nil {
+			q.logger.Error().Err(err).Msg("Failed to commit WAL replay progress")
+			return
+		}
+		replayed += len(msgs)
+		spillMetrics.Add("replayed_messages", int64(len(msgs)))
+	}
+
+	if replayed > 0 {
+		q.logger.Info().
+			Int("messages", replayed).
+			Int64("pending_bytes", q.wal.Pending()).
+			Msg("Replayed spilled messages from WAL")
+	}
+}
+
+// Close closes the WAL and the backend. Spilled messages stay on disk for
+// the next run.
+func (q *SpillQueue) Close() error {
+	walErr := q.wal.Close()
+	if err := q.Queue.Close(); err != nil {
+		return err
+	}
+	return walErr
+}
+
+// encodeMessage lays a message out as uvarint(len(key)) key value.
+func encodeMessage(msg Message) []byte {
+	buf := make([]byte, 0, binary.MaxVarintLen64+len(msg.Key)+len(msg.Value))
+	buf = binary.AppendUvarint(buf, uint64(len(msg.Key)))
+	buf = append(buf, msg.Key...)
+	return append(buf, msg.Value...)
+}
+
+func decodeMessage(rec []byte) (Message, error) {
+	keyLen, n := binary.Uvarint(rec)
+	if n <= 0 || uint64(len(rec)-n) < keyLen {
+		return Message{}, errors.New("invalid message framing")
+	}
+	rec = rec[n:]
+	return Message{Key: string(rec[:keyLen]), Value: rec[keyLen:]}, nil
+}
@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/wal"
+	"github.com/rs/zerolog"
+)
+
+// flakyQueue is a MemoryQueue whose Publish can be made to fail.
+type flakyQueue struct {
+	*MemoryQueue
+	down bool
+}
+
+func (q *flakyQueue) Publish(ctx context.Context, msgs ...Message) error {
+	if q.down {
+		return errors.New("backend down")
+	}
+	return q.MemoryQueue.Publish(ctx, msgs...)
+}
+
+func TestSpillQueueReplaysInOrder(t *testing.T) {
+	ctx := context.Background()
+	log, err := wal.Open(t.TempDir(), wal.Options{})
+	if err != nil {
+		t.Fatalf("wal.Open: %v", err)
+	}
+	backend := &flakyQueue{MemoryQueue: NewMemoryQueue()}
+	q := NewSpillQueue(backend, log, time.Hour, zerolog.Nop())
+	defer q.Close()
+
+	publish := func(i int) {
+		t.Helper()
+		if err := q.Publish(ctx, Message{Key: "k", Value: fmt.Appendf(nil, "%d", i)}); err != nil {
+			t.Fatalf("Publish(%d): %v", i, err)
+		}
+	}
+
+	publish(0)
+	backend.down = true
+	publish(1)
+	publish(2)
+	// Recovered, but earlier messages are still spilled: stay behind them.
+	backend.down = false
+	publish(3)
+	if backend.Len() != 1 {
+		t.Fatalf("backend has %d messages before replay, want 1", backend.Len())
+	}
+
+	q.replay(ctx)
+	if log.Pending() != 0 {
+		t.Fatalf("WAL has %d pending bytes after replay", log.Pending())
+	}
+	for i := range 4 {
+		d, err := backend.Receive(ctx, time.Second)
+		if err != nil || d == nil {
+			t.Fatalf("Receive: %v, %v", d, err)
+		}
+		if string(d.Value) != fmt.Sprint(i) || d.Key != "k" {
+			t.Fatalf("message %d = %q/%q", i, d.Key, d.Value)
+		}
+	}
+}
+
+func TestSpillQueueReplayKeepsBatchOnFailure(t *testing.T) {
+	ctx := context.Background()
+	log, err := wal.Open(t.TempDir(), wal.Options{})
+	if err != nil {
+		t.Fatalf("wal.Open: %v", err)
+	}
+	backend := &flakyQueue{MemoryQueue: NewMemoryQueue(), down: true}
+	q := NewSpillQueue(backend, log, time.Hour, zerolog.Nop())
+	defer q.Close()
+
+	if err := q.Publish(ctx, Message{Value: []byte("x")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	q.replay(ctx)
+	if log.Pending() == 0 {
+		t.Fatal("replay against a failing backend consumed the WAL")
+	}
+}
+
+func TestMessageEncoding(t *testing.T) {
+	msg := Message{Key: "api-key", Value: []byte(`{"id":"1"}`)}
+	got, err := decodeMessage(encodeMessage(msg))
+	if err != nil {
+		t.Fatalf("decodeMessage: %v", err)
+	}
+	if got.Key != msg.Key || string(got.Value) != string(msg.Value) {
+		t.Fatalf("round trip = %+v, want %+v", got, msg)
+	}
+	if _, err := decodeMessage([]byte{0x05, 'a'}); err == nil {
+		t.Fatal("decodeMessage accepted a truncated key")
+	}
+}
+
+// TestSpillQueueReplayAfterClose checks a replay racing shutdown is not
+// reported as WAL corruption.
+func TestSpillQueueReplayAfterClose(t *testing.T) {
+	ctx := context.Background()
+	log, err := wal.Open(t.TempDir(), wal.Options{})
+	if err != nil {
+		t.Fatalf("wal.Open: %v", err)
+	}
+	backend := &flakyQueue{MemoryQueue: NewMemoryQueue(), down: true}
+	q := NewSpillQueue(backend, log, time.Hour, zerolog.Nop())
+
+	if err := q.Publish(ctx, Message{Value: []byte("x")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	backend.down = false
+	log.Close()
+
+	corrupt := func() int64 {
+		if v, ok := spillMetrics.Get("corrupt_segments").(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	before := corrupt()
+	q.replay(ctx)
+	if after := corrupt(); after != before {
+		t.Fatalf("corrupt_segments went from %d to %d on a closed WAL", before, after)
+	}
+}
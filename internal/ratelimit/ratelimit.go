@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// slidingWindowScript keeps one sorted-set member per request scored by its
+// timestamp in ms. Trimming, counting and adding happen in a single script so
+// concurrent requests for the same key can never overshoot the limit.
+//
+// Returns {allowed, remaining, reset_ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+type Limiter struct {
+	redis *redis.Client
+	log   zerolog.Logger
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the oldest request in the window expires,
+	// i.e. when at least one more request will be accepted.
+	Reset time.Duration
+}
+
+// Key is the Redis key of the bucket for identity under the named rule. HTTP
+// and gRPC build keys the same way so they share one bucket per client.
+func Key(rule string, identity string) string {
+	return "ratelimit:" + rule + ":" + identity
+}
+
+func NewLimiter(redisClient *redis.Client, log zerolog.Logger) *Limiter {
+	return &Limiter{
+		redis: redisClient,
+		log:   log.With().Str("component", "ratelimit").Logger(),
+	}
+}
+
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	now := time.Now().UnixMilli()
+
+	res, err := slidingWindowScript.Run(ctx, l.redis, []string{key},
+		now,
+		window.Milliseconds(),
+		limit,
+		strconv.FormatInt(now, 10)+"-"+uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		l.log.Error().Err(err).Str("key", key).Msg("Failed to evaluate rate limit")
+		return nil, err
+	}
+
+	remaining := int(res[1])
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &Result{
+		Allowed:   res[0] == 1,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
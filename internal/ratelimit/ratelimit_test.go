@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func newTestLimiter(t *testing.T) *Limiter {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewLimiter(client, zerolog.Nop())
+}
+
+func TestLimiterAllowsUpToLimit(t *testing.T) {
+	l := newTestLimiter(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(ctx, "k", 3, time.Minute)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i, res, 2-i)
+		}
+	}
+
+	res, err := l.Allow(ctx, "k", 3, time.Minute)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("request over limit = %+v, want denied", res)
+	}
+	if res.Reset <= 0 || res.Reset > time.Minute {
+		t.Fatalf("Reset = %v, want within the window", res.Reset)
+	}
+}
+
+func TestLimiterKeysAreIndependent(t *testing.T) {
+	l := newTestLimiter(t)
+	ctx := context.Background()
+
+	if res, _ := l.Allow(ctx, "a", 1, time.Minute); !res.Allowed {
+		t.Fatal("first request for a denied")
+	}
+	if res, _ := l.Allow(ctx, "a", 1, time.Minute); res.Allowed {
+		t.Fatal("second request for a allowed")
+	}
+	if res, _ := l.Allow(ctx, "b", 1, time.Minute); !res.Allowed {
+		t.Fatal("first request for b denied")
+	}
+}
+
+func TestLimiterWindowSlides(t *testing.T) {
+	l := newTestLimiter(t)
+	ctx := context.Background()
+
+	if res, _ := l.Allow(ctx, "k", 1, 50*time.Millisecond); !res.Allowed {
+		t.Fatal("first request denied")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if res, _ := l.Allow(ctx, "k", 1, 50*time.Millisecond); !res.Allowed {
+		t.Fatal("request after the window denied")
+	}
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+const auditColumns = `id, occurred_at, COALESCE(api_key, ''), COALESCE(actor_id, ''), action,
+	COALESCE(target, ''), COALESCE(ip, ''), COALESCE(user_agent, ''), COALESCE(request_id, ''), outcome, status`
+
+type AuditRepository struct {
+	db  *db.DB
+	log zerolog.Logger
+}
+
+func NewAuditRepository(db *db.DB, log zerolog.Logger) *AuditRepository {
+	return &AuditRepository{
+		db:  db,
+		log: log.With().Str("repository", "audit").Logger(),
+	}
+}
+
+// AuditFilter narrows a listing; zero fields match everything.
+type AuditFilter struct {
+	Actions []string
+	ActorID string
+	Outcome string
+	From    time.Time
+	To      time.Time
+	// Before pages back through the log: only entries with smaller IDs match.
+	Before int64
+	// Limit caps the number of entries; 0 means no limit.
+	Limit int
+}
+
+func (r *AuditRepository) AppendAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	return r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		return r.db.Pool.QueryRow(ctx, `
+			INSERT INTO audit_log (occurred_at, api_key, actor_id, action, target, ip, user_agent, request_id, outcome, status)
+			VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+			RETURNING id
+		`, entry.OccurredAt, entry.APIKey, entry.ActorID, entry.Action, entry.Target,
+			entry.IP, entry.UserAgent, entry.RequestID, entry.Outcome, entry.Status,
+		).Scan(&entry.ID)
+	})
+}
+
+// PurgeAuditEntries deletes up to limit entries that occurred before cutoff,
+// oldest first, and returns how many it deleted. The audit log rejects any
+// other deletes.
+func (r *AuditRepository) PurgeAuditEntries(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	var purged int64
+	err := r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		return r.db.Pool.QueryRow(ctx, `SELECT audit_log_purge($1, $2)`, cutoff, limit).Scan(&purged)
+	})
+	return purged, err
+}
+
+// ListAuditEntries returns apiKey's entries matching filter, newest first.
+func (r *AuditRepository) ListAuditEntries(ctx context.Context, apiKey string, filter AuditFilter) ([]*models.AuditEntry, error) {
+	var entries []*models.AuditEntry
+	err := r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		entries = []*models.AuditEntry{}
+		return r.eachAuditEntry(ctx, apiKey, filter, func(entry *models.AuditEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// EachAuditEntry streams apiKey's entries matching filter to fn, newest
+// first, without holding them all in memory. It is not retried, since fn
+// may already have written some out.
+func (r *AuditRepository) EachAuditEntry(ctx context.Context, apiKey string, filter AuditFilter, fn func(*models.AuditEntry) error) error {
+	return r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		return r.eachAuditEntry(ctx, apiKey, filter, fn)
+	})
+}
+
+func (r *AuditRepository) eachAuditEntry(ctx context.Context, apiKey string, filter AuditFilter, fn func(*models.AuditEntry) error) error {
+	conds := []string{"api_key = $1"}
+	args := []any{apiKey}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if len(filter.Actions) > 0 {
+		add("action = ANY($%d)", filter.Actions)
+	}
+	if filter.ActorID != "" {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.Outcome != "" {
+		add("outcome = $%d", filter.Outcome)
+	}
+	if !filter.From.IsZero() {
+		add("occurred_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("occurred_at < $%d", filter.To)
+	}
+	if filter.Before > 0 {
+		add("id < $%d", filter.Before)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE ` + strings.Join(conds, " AND ") + ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanAuditEntry(row pgx.Row) (*models.AuditEntry, error) {
+	e := &models.AuditEntry{}
+	err := row.Scan(&e.ID, &e.OccurredAt, &e.APIKey, &e.ActorID, &e.Action,
+		&e.Target, &e.IP, &e.UserAgent, &e.RequestID, &e.Outcome, &e.Status)
+	return e, err
+}
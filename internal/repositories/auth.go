@@ -7,10 +7,8 @@ import (
 	"github.com/Vighnesh-V-H/sync/internal/db"
 	errors "github.com/Vighnesh-V-H/sync/internal/error"
 	"github.com/Vighnesh-V-H/sync/internal/models"
-	"github.com/Vighnesh-V-H/sync/internal/utils"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	gonanoid "github.com/matoous/go-nanoid/v2"
 	"github.com/rs/zerolog"
 )
 
@@ -22,84 +20,245 @@ type AuthRepository struct {
 func NewAuthRepository(db *db.DB, log zerolog.Logger) *AuthRepository {
 	return &AuthRepository{
 		db:  db,
-		log: log,
+		log: log.With().Str("repository", "auth").Logger(),
 	}
 }
 
+// CreateUser stores user as given; the service hashes the password, normalizes
+// the email and assigns the ID and api key beforehand. Uniqueness is left to
+// the database, so concurrent signups for one email cannot both succeed: the
+// loser gets ErrUserAlreadyExists, and an api key collision ErrAPIKeyConflict.
 func (r *AuthRepository) CreateUser(ctx context.Context, user *models.User) error {
-	mail := user.Email
-
-	existing, err := r.GetUserByEmail(ctx, mail)
-	if err != nil {
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		_, err := r.db.Pool.Exec(ctx, `
+			INSERT INTO users (id, email, password, name, api_key, is_verified, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, user.ID, user.Email, user.Password, user.Name, user.Api_Key, user.IsVerified, user.CreatedAt, user.UpdatedAt)
 		return err
-	}
-	if existing != nil {
+	})
+
+	switch db.UniqueViolation(err) {
+	case "users_email_normalized_key", "users_email_key":
 		return errors.ErrUserAlreadyExists
+	case "users_api_key_key":
+		return errors.ErrAPIKeyConflict
 	}
+	return err
+}
 
-	hashed, err := utils.HashPassword(user.Password)
-	if err != nil {
+const userColumns = `u.id, u.email, u.password, u.name, u.api_key, u.is_verified, u.plan,
+	u.mfa_enabled, COALESCE(u.mfa_secret, ''), u.mfa_last_step, COALESCE(u.mfa_challenge, ''),
+	u.mfa_locked_until, COALESCE(u.pending_email, ''), u.token_version, u.created_at, u.updated_at`
+
+// GetUserByEmail returns nil, nil if no active account has email.
+func (r *AuthRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getUser(ctx, `
+		SELECT `+userColumns+`
+		FROM users u
+		WHERE lower(btrim(u.email)) = lower(btrim($1)) AND u.deleted_at IS NULL
+		LIMIT 1
+	`, email)
+}
+
+// GetUserByID returns nil, nil if there is no such user or it was deleted.
+func (r *AuthRepository) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	return r.getUser(ctx, `
+		SELECT `+userColumns+`
+		FROM users u
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+	`, id)
+}
+
+// GetUserByIdentity returns the active user linked to the provider account,
+// or nil, nil.
+func (r *AuthRepository) GetUserByIdentity(ctx context.Context, provider string, subject string) (*models.User, error) {
+	return r.getUser(ctx, `
+		SELECT `+userColumns+`
+		FROM user_identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.provider = $1 AND i.subject = $2 AND u.deleted_at IS NULL
+	`, provider, subject)
+}
+
+// LinkIdentity links a provider account to userID. Linking an account that
+// is already linked is a no-op.
+func (r *AuthRepository) LinkIdentity(ctx context.Context, userID string, provider string, subject string, email string) error {
+	return r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		_, err := r.db.Pool.Exec(ctx, `
+			INSERT INTO user_identities (provider, subject, user_id, email, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (provider, subject) DO NOTHING
+		`, provider, subject, userID, email, time.Now())
 		return err
-	}
-	user.Password = hashed
-	user.ID = uuid.New().String()
-	user.CreatedAt = time.Now()
-	user.UpdatedAt = time.Now()
+	})
+}
+
+func (r *AuthRepository) getUser(ctx context.Context, query string, args ...any) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		return r.db.Pool.QueryRow(ctx, query, args...).Scan(
+			&user.ID,
+			&user.Email,
+			&user.Password,
+			&user.Name,
+			&user.Api_Key,
+			&user.IsVerified,
+			&user.Plan,
+			&user.MFAEnabled,
+			&user.MFASecret,
+			&user.MFALastStep,
+			&user.MFAChallenge,
+			&user.MFALockedUntil,
+			&user.PendingEmail,
+			&user.TokenVersion,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+	})
 
-	apikey, err := gonanoid.New()
 	if err != nil {
-		return err
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
 	}
-	syncID := "sync_" + apikey
 
-	tx, err := r.db.Pool.Begin(ctx)
+	return user, nil
+}
+
+// UpdateUser saves the user's name, email, pending email and verification
+// state. Taking an email that belongs to another account returns
+// ErrUserAlreadyExists.
+func (r *AuthRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	var affected int64
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tag, err := r.db.Pool.Exec(ctx, `
+			UPDATE users
+			SET name = $2, email = $3, is_verified = $4, pending_email = NULLIF($5, ''), updated_at = $6
+			WHERE id = $1 AND deleted_at IS NULL
+		`, user.ID, user.Name, user.Email, user.IsVerified, user.PendingEmail, user.UpdatedAt)
+		affected = tag.RowsAffected()
+		return err
+	})
+
+	switch db.UniqueViolation(err) {
+	case "users_email_normalized_key", "users_email_key":
+		return errors.ErrUserAlreadyExists
+	}
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback(ctx)
+	if affected == 0 {
+		return errors.ErrUserNotFound
+	}
+	return nil
+}
 
-	_, err = tx.Exec(ctx, `
-		INSERT INTO users (id, email, password, name, api_key, is_verified, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, user.ID, user.Email, user.Password, user.Name, syncID, true, user.CreatedAt, user.UpdatedAt)
+// TokenVersion returns the user's current token version, and false if there
+// is no such user or it was deleted.
+func (r *AuthRepository) TokenVersion(ctx context.Context, id string) (int, bool, error) {
+	var version int
+	err := r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		return r.db.Pool.QueryRow(ctx, `
+			SELECT token_version FROM users WHERE id = $1 AND deleted_at IS NULL
+		`, id).Scan(&version)
+	})
+	if err == pgx.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return version, true, nil
+}
 
+// UpdatePassword replaces the user's password hash.
+func (r *AuthRepository) UpdatePassword(ctx context.Context, id string, hashed string, at time.Time) error {
+	var affected int64
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tag, err := r.db.Pool.Exec(ctx, `
+			UPDATE users SET password = $2, updated_at = $3
+			WHERE id = $1 AND deleted_at IS NULL
+		`, id, hashed, at)
+		affected = tag.RowsAffected()
+		return err
+	})
 	if err != nil {
 		return err
 	}
+	if affected == 0 {
+		return errors.ErrUserNotFound
+	}
+	return nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
+// ChangePassword replaces the user's password hash and bumps their token
+// version in one statement, revoking every token issued before.
+func (r *AuthRepository) ChangePassword(ctx context.Context, id string, hashed string, at time.Time) error {
+	var affected int64
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tag, err := r.db.Pool.Exec(ctx, `
+			UPDATE users SET password = $2, token_version = token_version + 1, updated_at = $3
+			WHERE id = $1 AND deleted_at IS NULL
+		`, id, hashed, at)
+		affected = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
 		return err
 	}
-
+	if affected == 0 {
+		return errors.ErrUserNotFound
+	}
 	return nil
 }
 
-func (r *AuthRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `
-		SELECT id, email, password, name, api_key, is_verified, created_at, updated_at
-		FROM users
-		WHERE email = $1
-		LIMIT 1
-	`
-
-	user := &models.User{}
-	err := r.db.Pool.QueryRow(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Password,
-		&user.Name,
-		&user.Api_Key,
-		&user.IsVerified,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+// DeleteUser soft-deletes the user in one transaction: their personal
+// details and linked provider accounts are removed (freeing both for a new
+// signup), their write keys are revoked and an erasure request is queued for
+// eraseAfter.
+func (r *AuthRepository) DeleteUser(ctx context.Context, id string, eraseAfter time.Time) error {
+	return r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tx, err := r.db.Pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
 
-	if err != nil {
+		now := time.Now()
+		var apiKey string
+		err = tx.QueryRow(ctx, `
+			UPDATE users
+			SET deleted_at = $2, email = 'deleted+' || id || '@deleted.invalid', pending_email = NULL,
+				name = '', password = '', updated_at = $2
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING api_key
+		`, id, now).Scan(&apiKey)
 		if err == pgx.ErrNoRows {
-			return nil, nil
+			return errors.ErrUserNotFound
+		}
+		if err != nil {
+			return err
 		}
-		return nil, err
-	}
 
-	return user, nil
+		if _, err := tx.Exec(ctx, `DELETE FROM user_identities WHERE user_id = $1`, id); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE write_keys SET revoked_at = $2
+			WHERE user_id = $1 AND revoked_at IS NULL
+		`, id, now); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO erasure_requests (id, user_id, api_key, requested_at, erase_after)
+			VALUES ($1, $2, $3, $4, $5)
+		`, uuid.New().String(), id, apiKey, now, eraseAfter); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
 }
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+type EnrichmentRepository struct {
+	db  *db.DB
+	log zerolog.Logger
+}
+
+func NewEnrichmentRepository(db *db.DB, log zerolog.Logger) *EnrichmentRepository {
+	return &EnrichmentRepository{
+		db:  db,
+		log: log.With().Str("repository", "enrichment").Logger(),
+	}
+}
+
+// GetSettings returns nil if the project has never saved settings.
+func (r *EnrichmentRepository) GetSettings(ctx context.Context, apiKey string) (*models.EnrichmentSettings, error) {
+	settings := &models.EnrichmentSettings{APIKey: apiKey}
+	var updatedAt time.Time
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT enrichers, static_properties, updated_at
+		FROM enrichment_settings WHERE api_key = $1
+	`, apiKey).Scan(&settings.Enrichers, &settings.StaticProperties, &updatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	settings.UpdatedAt = &updatedAt
+	return settings, nil
+}
+
+func (r *EnrichmentRepository) SaveSettings(ctx context.Context, settings *models.EnrichmentSettings) error {
+	now := time.Now()
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO enrichment_settings (api_key, enrichers, static_properties, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (api_key) DO UPDATE SET
+			enrichers = EXCLUDED.enrichers,
+			static_properties = EXCLUDED.static_properties,
+			updated_at = EXCLUDED.updated_at
+	`, settings.APIKey, settings.Enrichers, settings.StaticProperties, now)
+	if err != nil {
+		r.log.Error().Err(err).Msg("Failed to save enrichment settings")
+		return err
+	}
+	settings.UpdatedAt = &now
+	return nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// erasedTables hold tenant data keyed by api_key. Identities and webhook
+// deliveries go with their persons and webhooks.
+var erasedTables = []string{"events", "persons", "webhooks", "rules", "enrichment_settings"}
+
+type ErasureRepository struct {
+	db  *db.DB
+	log zerolog.Logger
+}
+
+func NewErasureRepository(db *db.DB, log zerolog.Logger) *ErasureRepository {
+	return &ErasureRepository{
+		db:  db,
+		log: log.With().Str("repository", "erasure").Logger(),
+	}
+}
+
+// EraseNext carries out one due erasure request and returns the api key it
+// erased, or "" if nothing is due. The request row is locked for the
+// duration, so several workers can run at once.
+func (r *ErasureRepository) EraseNext(ctx context.Context) (string, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var id, apiKey string
+	err = tx.QueryRow(ctx, `
+		SELECT id, api_key FROM erasure_requests
+		WHERE completed_at IS NULL AND erase_after <= $1
+		ORDER BY erase_after
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, time.Now()).Scan(&id, &apiKey)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, table := range erasedTables {
+		tag, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE api_key = $1`, apiKey)
+		if err != nil {
+			return "", err
+		}
+		r.log.Debug().
+			Str("erasure_id", id).
+			Str("table", table).
+			Int64("rows", tag.RowsAffected()).
+			Msg("Erased tenant rows")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE erasure_requests SET completed_at = $2 WHERE id = $1`, id, time.Now()); err != nil {
+		return "", err
+	}
+	return apiKey, tx.Commit(ctx)
+}
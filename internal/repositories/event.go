@@ -3,75 +3,253 @@ package repositories
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/Vighnesh-V-H/sync/internal/db"
-	"github.com/redis/go-redis/v9"
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
 	"github.com/rs/zerolog"
 )
 
+// queuedTTL is how long an event ID is remembered for deduplication.
+const queuedTTL = 24 * time.Hour
+
+// EventRepository queues accepted events on q and stores processed ones in
+// Postgres. The dedup store remembers recently queued IDs so retried requests
+// are not queued twice.
 type EventRepository struct {
 	db    *db.DB
-	redis *redis.Client
+	dedup dedup.Store
+	queue queue.Queue
 	log   zerolog.Logger
 }
 
-func NewEventRepository(db *db.DB, redisClient *redis.Client, log zerolog.Logger) *EventRepository {
+func NewEventRepository(db *db.DB, dedupStore dedup.Store, q queue.Queue, log zerolog.Logger) *EventRepository {
 	return &EventRepository{
 		db:    db,
-		redis: redisClient,
+		dedup: dedupStore,
+		queue: q,
 		log:   log.With().Str("repository", "event").Logger(),
 	}
 }
 
-func (r *EventRepository) AddEvent(ctx context.Context, apiKey string, id string, payload map[string]interface{}) error {
+// AddEvent queues one event and reports whether it was queued; an ID queued
+// within queuedTTL is skipped.
+func (r *EventRepository) AddEvent(ctx context.Context, apiKey string, id string, payload map[string]interface{}, evCtx models.EventContext) (bool, error) {
 
 	r.log.Debug().
 		Str("api_key", apiKey).
 		Str("event_id", id).
 		Msg("Receiving event for processing")
 
-	queuedKey := fmt.Sprintf("event_queued:%s", id)
-	r.log.Debug().Str("queued_key", queuedKey).Msg("Checking if event already queued")
-	exists, _ := r.redis.Get(ctx, queuedKey).Result()
-	if exists == "1" {
+	isNew, err := r.dedup.Claim(ctx, id, queuedTTL)
+	if err != nil {
+		// Queueing a possible duplicate beats dropping the event.
+		r.log.Warn().Err(err).Str("event_id", id).Msg("Failed to check if event already queued")
+	} else if !isNew {
 		r.log.Warn().Str("event_id", id).Msg("Event already queued, skipping")
-		return nil
+		return false, nil
 	}
-	r.redis.Set(ctx, queuedKey, "1", 24*time.Hour)
 
-	eventData := map[string]interface{}{
-		"api_key":   apiKey,
-		"id":        id,
-		"payload":   payload,
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-	}
-
-	payloadJSON, err := json.Marshal(eventData)
+	payloadJSON, err := marshalQueuedEvent(apiKey, id, payload, time.Now(), evCtx)
 	if err != nil {
 		r.log.Error().Err(err).
 			Str("event_id", id).
 			Msg("Failed to marshal event payload")
-		return err
+		return false, err
 	}
 
-	queueKey := "events:queue"
-	err = r.redis.RPush(ctx, queueKey, payloadJSON).Err()
+	err = r.queue.Publish(ctx, queue.Message{Key: apiKey, Value: payloadJSON})
 	if err != nil {
 		r.log.Error().Err(err).
 			Str("event_id", id).
-			Str("queue_key", queueKey).
-			Msg("Failed to push event to Redis queue")
-		return err
+			Msg("Failed to publish event to queue")
+		r.forget(ctx, id)
+		return false, err
 	}
 
 	r.log.Info().
 		Str("api_key", apiKey).
 		Str("event_id", id).
-		Str("queue_key", queueKey).
 		Int("payload_size", len(payloadJSON)).
-		Msg("Event queued successfully in Redis")
+		Msg("Event queued successfully")
+
+	return true, nil
+}
+
+type QueuedEvent struct {
+	ID        string
+	Payload   map[string]interface{}
+	Timestamp time.Time
+	Context   models.EventContext
+}
+
+// AddEvents enqueues a batch of events in one publish and reports how many
+// were queued. Like AddEvent it skips IDs queued within queuedTTL, so the
+// processor cannot tell the two paths apart. If publishing fails nothing is
+// left claimed, so the whole batch can be retried.
+func (r *EventRepository) AddEvents(ctx context.Context, apiKey string, events []QueuedEvent) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(events))
+	for i, ev := range events {
+		ids[i] = ev.ID
+	}
+	claimed, err := r.dedup.ClaimAll(ctx, queuedTTL, ids...)
+	if err != nil {
+		// Queueing possible duplicates beats dropping the batch.
+		r.log.Warn().Err(err).
+			Int("batch_size", len(events)).
+			Msg("Failed to check if events already queued")
+		claimed = nil
+	}
+
+	msgs := make([]queue.Message, 0, len(events))
+	for i, ev := range events {
+		if claimed != nil && !claimed[i] {
+			continue
+		}
+		payloadJSON, err := marshalQueuedEvent(apiKey, ev.ID, ev.Payload, ev.Timestamp, ev.Context)
+		if err != nil {
+			r.log.Error().Err(err).
+				Str("event_id", ev.ID).
+				Msg("Failed to marshal event payload")
+			r.forget(ctx, claimedIDs(ids, claimed)...)
+			return 0, err
+		}
+		msgs = append(msgs, queue.Message{Key: apiKey, Value: payloadJSON})
+	}
+
+	if len(msgs) > 0 {
+		if err := r.queue.Publish(ctx, msgs...); err != nil {
+			r.log.Error().Err(err).
+				Int("batch_size", len(events)).
+				Msg("Failed to publish event batch to queue")
+			r.forget(ctx, claimedIDs(ids, claimed)...)
+			return 0, err
+		}
+	}
+
+	r.log.Debug().
+		Str("api_key", apiKey).
+		Int("batch_size", len(events)).
+		Int("queued", len(msgs)).
+		Msg("Event batch queued successfully")
+
+	return len(msgs), nil
+}
+
+// claimedIDs returns the ids ClaimAll reported as new; with no claim results
+// nothing was claimed.
+func claimedIDs(ids []string, claimed []bool) []string {
+	var out []string
+	for i, ok := range claimed {
+		if ok {
+			out = append(out, ids[i])
+		}
+	}
+	return out
+}
+
+// forget releases ids claimed for an event that was not queued after all.
+func (r *EventRepository) forget(ctx context.Context, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	if err := r.dedup.Forget(context.WithoutCancel(ctx), ids...); err != nil {
+		r.log.Warn().Err(err).
+			Strs("event_ids", ids).
+			Msg("Failed to release claimed event IDs")
+	}
+}
 
-	return nil
+func marshalQueuedEvent(apiKey string, id string, payload map[string]interface{}, ts time.Time, evCtx models.EventContext) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"api_key":   apiKey,
+		"id":        id,
+		"payload":   payload,
+		"timestamp": ts.UTC().Format(time.RFC3339),
+		"context":   evCtx,
+	})
+}
+
+// maxEventAttempts is how often the processor may fail an event before it
+// is dead-lettered.
+const maxEventAttempts = 5
+
+// ReceivedEvent is an event taken off the queue. Ack it once it has been
+// handled, or Retry it if handling failed.
+type ReceivedEvent struct {
+	*models.Event
+	delivery *queue.Delivery
+}
+
+func (e *ReceivedEvent) Ack(ctx context.Context) error {
+	return e.delivery.Ack(ctx)
+}
+
+// Retry queues the event to be processed again, exactly as it was received
+// apart from its attempt count. After maxEventAttempts failures it is moved
+// to the dead-letter queue instead, so one bad event cannot cycle forever.
+func (e *ReceivedEvent) Retry(ctx context.Context) error {
+	e.Attempts++
+	if e.Attempts >= maxEventAttempts {
+		return e.delivery.DeadLetter(ctx)
+	}
+
+	// The processor may have rewritten e.Event, so requeue the original.
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(e.delivery.Value, &fields); err != nil {
+		return err
+	}
+	attempts, err := json.Marshal(e.Attempts)
+	if err != nil {
+		return err
+	}
+	fields["attempts"] = attempts
+	value, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return e.delivery.Retry(ctx, queue.Message{Key: e.delivery.Key, Value: value})
+}
+
+// ReceiveEvent blocks for up to timeout waiting for the next queued event. It
+// returns nil, nil when the queue stayed empty.
+func (r *EventRepository) ReceiveEvent(ctx context.Context, timeout time.Duration) (*ReceivedEvent, error) {
+	delivery, err := r.queue.Receive(ctx, timeout)
+	if err != nil || delivery == nil {
+		return nil, err
+	}
+
+	ev := &models.Event{}
+	if err := json.Unmarshal(delivery.Value, ev); err != nil {
+		r.log.Error().Err(err).Msg("Dropping malformed event from queue")
+		return nil, delivery.Ack(ctx)
+	}
+	return &ReceivedEvent{Event: ev, delivery: delivery}, nil
+}
+
+// SaveProcessedEvent stores an event after the processor has resolved it.
+// Saving the same event twice is a no-op. It makes a single attempt: the
+// processor backs off and retries failed saves itself.
+func (r *EventRepository) SaveProcessedEvent(ctx context.Context, ev *models.Event) error {
+	processedAt := time.Now()
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		_, err := r.db.Pool.Exec(ctx, `
+			INSERT INTO events (id, api_key, person_id, distinct_id, payload, timestamp, processed_at, routes)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, COALESCE($8::text[], '{}'))
+			ON CONFLICT (id) DO NOTHING
+		`, ev.ID, ev.APIKey, ev.PersonID, ev.DistinctID, ev.Payload, ev.Timestamp, processedAt, ev.Routes)
+		return err
+	})
+	if err != nil {
+		r.log.Error().Err(err).
+			Str("event_id", ev.ID).
+			Msg("Failed to store processed event")
+	}
+	return err
 }
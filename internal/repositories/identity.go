@@ -0,0 +1,253 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// IdentityRepository stores the per-tenant identity graph: every distinct ID
+// a tenant has seen points at exactly one person.
+type IdentityRepository struct {
+	db  *db.DB
+	log zerolog.Logger
+}
+
+func NewIdentityRepository(db *db.DB, log zerolog.Logger) *IdentityRepository {
+	return &IdentityRepository{
+		db:  db,
+		log: log.With().Str("repository", "identity").Logger(),
+	}
+}
+
+// Link makes primary and secondary (which may be empty) resolve to the same
+// person and returns that person's ID. When both IDs already belong to
+// different persons, the secondary's person is merged into the primary's.
+// Changes for the same tenant are serialised with an advisory lock so
+// concurrent processors cannot split one user into two persons; events from
+// already linked IDs are resolved without taking it.
+func (r *IdentityRepository) Link(ctx context.Context, apiKey string, primary string, secondary string) (string, error) {
+	var personID string
+	err := r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		var err error
+		personID, err = r.linkedPerson(ctx, apiKey, primary, secondary)
+		return err
+	})
+	if err != nil || personID != "" {
+		return personID, err
+	}
+
+	// The transaction is a single attempt: it may create a person, so it is
+	// not retried.
+	err = r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		var err error
+		personID, err = r.link(ctx, apiKey, primary, secondary)
+		return err
+	})
+	return personID, err
+}
+
+// link makes the changes for Link in one transaction under the tenant's
+// advisory lock.
+func (r *IdentityRepository) link(ctx context.Context, apiKey string, primary string, secondary string) (string, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, apiKey); err != nil {
+		return "", err
+	}
+
+	primaryPerson, err := lookupPerson(ctx, tx, apiKey, primary)
+	if err != nil {
+		return "", err
+	}
+	secondaryPerson := ""
+	if secondary != "" && secondary != primary {
+		secondaryPerson, err = lookupPerson(ctx, tx, apiKey, secondary)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	now := time.Now()
+	personID := primaryPerson
+	switch {
+	case primaryPerson == "" && secondaryPerson == "":
+		personID = uuid.New().String()
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO persons (id, api_key, traits, created_at, updated_at)
+			VALUES ($1, $2, '{}', $3, $3)
+		`, personID, apiKey, now); err != nil {
+			return "", err
+		}
+	case primaryPerson == "":
+		// A known anonymous visitor has just been identified; keep their person.
+		personID = secondaryPerson
+	case secondaryPerson != "" && secondaryPerson != primaryPerson:
+		if err := mergePersons(ctx, tx, apiKey, primaryPerson, secondaryPerson, now); err != nil {
+			return "", err
+		}
+		r.log.Info().
+			Str("person_id", primaryPerson).
+			Str("merged_person_id", secondaryPerson).
+			Msg("Merged persons")
+	}
+
+	for _, distinctID := range []string{primary, secondary} {
+		if distinctID == "" {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO identities (api_key, distinct_id, person_id, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (api_key, distinct_id) DO NOTHING
+		`, apiKey, distinctID, personID, now); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+
+	return personID, nil
+}
+
+// linkedPerson returns the person primary belongs to if secondary (when
+// given) already belongs to the same person, so Link has nothing to change.
+// It returns "" when a write is needed.
+func (r *IdentityRepository) linkedPerson(ctx context.Context, apiKey string, primary string, secondary string) (string, error) {
+	ids := []string{primary}
+	if secondary != "" && secondary != primary {
+		ids = append(ids, secondary)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT distinct_id, person_id FROM identities
+		WHERE api_key = $1 AND distinct_id = ANY($2)
+	`, apiKey, ids)
+	if err != nil {
+		return "", err
+	}
+	persons := make(map[string]string, len(ids))
+	var distinctID, personID string
+	_, err = pgx.ForEachRow(rows, []any{&distinctID, &personID}, func() error {
+		persons[distinctID] = personID
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	personID = persons[primary]
+	for _, id := range ids {
+		if persons[id] != personID {
+			return "", nil
+		}
+	}
+	return personID, nil
+}
+
+// MergeTraits shallow-merges traits into the person's existing traits.
+// Merging the same traits again changes nothing, so it is retried.
+func (r *IdentityRepository) MergeTraits(ctx context.Context, personID string, traits map[string]any) error {
+	now := time.Now()
+	return r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		_, err := r.db.Pool.Exec(ctx, `
+			UPDATE persons SET traits = traits || $2::jsonb, updated_at = $3
+			WHERE id = $1
+		`, personID, traits, now)
+		return err
+	})
+}
+
+// GetPersonByDistinctID returns nil if the distinct ID is unknown for the tenant.
+func (r *IdentityRepository) GetPersonByDistinctID(ctx context.Context, apiKey string, distinctID string) (*models.Person, error) {
+	var person *models.Person
+	err := r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		var err error
+		person, err = r.getPersonByDistinctID(ctx, apiKey, distinctID)
+		return err
+	})
+	return person, err
+}
+
+func (r *IdentityRepository) getPersonByDistinctID(ctx context.Context, apiKey string, distinctID string) (*models.Person, error) {
+	person := &models.Person{APIKey: apiKey}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT p.id, p.traits, p.created_at, p.updated_at
+		FROM identities i
+		JOIN persons p ON p.id = i.person_id
+		WHERE i.api_key = $1 AND i.distinct_id = $2
+	`, apiKey, distinctID).Scan(&person.ID, &person.Traits, &person.CreatedAt, &person.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT distinct_id FROM identities
+		WHERE api_key = $1 AND person_id = $2
+		ORDER BY created_at
+	`, apiKey, person.ID)
+	if err != nil {
+		return nil, err
+	}
+	person.DistinctIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
+
+func lookupPerson(ctx context.Context, tx pgx.Tx, apiKey string, distinctID string) (string, error) {
+	if distinctID == "" {
+		return "", nil
+	}
+
+	var personID string
+	err := tx.QueryRow(ctx, `
+		SELECT person_id FROM identities WHERE api_key = $1 AND distinct_id = $2
+	`, apiKey, distinctID).Scan(&personID)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	return personID, err
+}
+
+// mergePersons folds drop into keep: identities and already stored events are
+// repointed, traits are combined with keep's values winning, and drop is removed.
+func mergePersons(ctx context.Context, tx pgx.Tx, apiKey string, keep string, drop string, now time.Time) error {
+	statements := []struct {
+		sql  string
+		args []any
+	}{
+		{`UPDATE identities SET person_id = $2 WHERE api_key = $1 AND person_id = $3`, []any{apiKey, keep, drop}},
+		{`UPDATE events SET person_id = $2 WHERE api_key = $1 AND person_id = $3`, []any{apiKey, keep, drop}},
+		{`
+			UPDATE persons SET
+				traits = (SELECT traits FROM persons WHERE id = $2) || persons.traits,
+				updated_at = $3
+			WHERE id = $1
+		`, []any{keep, drop, now}},
+		{`DELETE FROM persons WHERE id = $1`, []any{drop}},
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt.sql, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	importJobTTL       = 7 * 24 * time.Hour
+	maxImportRowErrors = 1000
+)
+
+// ImportRepository keeps import job progress in Redis. Jobs are short-lived
+// operational records, so they expire rather than being stored in Postgres.
+type ImportRepository struct {
+	redis *redis.Client
+	log   zerolog.Logger
+}
+
+func NewImportRepository(redisClient *redis.Client, log zerolog.Logger) *ImportRepository {
+	return &ImportRepository{
+		redis: redisClient,
+		log:   log.With().Str("repository", "import").Logger(),
+	}
+}
+
+func (r *ImportRepository) CreateJob(ctx context.Context, job *models.ImportJob) error {
+	key := importJobKey(job.ID)
+
+	pipe := r.redis.TxPipeline()
+	pipe.HSet(ctx, key, map[string]interface{}{
+		"api_key":       job.APIKey,
+		"format":        job.Format,
+		"status":        job.Status,
+		"rows_total":    0,
+		"rows_accepted": 0,
+		"rows_failed":   0,
+		"created_at":    job.CreatedAt.UTC().Format(time.RFC3339),
+	})
+	pipe.Expire(ctx, key, importJobTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *ImportRepository) SetStatus(ctx context.Context, id string, status string, jobErr string) error {
+	fields := map[string]interface{}{"status": status}
+	if jobErr != "" {
+		fields["error"] = jobErr
+	}
+	if status == models.ImportStatusCompleted || status == models.ImportStatusFailed {
+		fields["finished_at"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	return r.redis.HSet(ctx, importJobKey(id), fields).Err()
+}
+
+// RecordProgress adds the outcome of one chunk to the job counters and stores
+// its row errors, keeping at most maxImportRowErrors per job.
+func (r *ImportRepository) RecordProgress(ctx context.Context, id string, accepted int64, rowErrors []models.ImportRowError) error {
+	key := importJobKey(id)
+	failed := int64(len(rowErrors))
+
+	pipe := r.redis.TxPipeline()
+	pipe.HIncrBy(ctx, key, "rows_total", accepted+failed)
+	pipe.HIncrBy(ctx, key, "rows_accepted", accepted)
+	pipe.HIncrBy(ctx, key, "rows_failed", failed)
+	if failed > 0 {
+		errKey := importErrorsKey(id)
+		values := make([]interface{}, 0, len(rowErrors))
+		for _, rowErr := range rowErrors {
+			data, err := json.Marshal(rowErr)
+			if err != nil {
+				return err
+			}
+			values = append(values, data)
+		}
+		pipe.RPush(ctx, errKey, values...)
+		pipe.LTrim(ctx, errKey, 0, maxImportRowErrors-1)
+		pipe.Expire(ctx, errKey, importJobTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetJob returns nil if the job does not exist or has expired.
+func (r *ImportRepository) GetJob(ctx context.Context, id string) (*models.ImportJob, error) {
+	fields, err := r.redis.HGetAll(ctx, importJobKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	job := &models.ImportJob{
+		ID:     id,
+		APIKey: fields["api_key"],
+		Format: fields["format"],
+		Status: fields["status"],
+		Error:  fields["error"],
+	}
+	job.RowsTotal, _ = strconv.ParseInt(fields["rows_total"], 10, 64)
+	job.RowsAccepted, _ = strconv.ParseInt(fields["rows_accepted"], 10, 64)
+	job.RowsFailed, _ = strconv.ParseInt(fields["rows_failed"], 10, 64)
+	job.CreatedAt, _ = time.Parse(time.RFC3339, fields["created_at"])
+	if v, ok := fields["finished_at"]; ok {
+		if finished, err := time.Parse(time.RFC3339, v); err == nil {
+			job.FinishedAt = &finished
+		}
+	}
+
+	return job, nil
+}
+
+func (r *ImportRepository) GetRowErrors(ctx context.Context, id string) ([]models.ImportRowError, error) {
+	values, err := r.redis.LRange(ctx, importErrorsKey(id), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	rowErrors := make([]models.ImportRowError, 0, len(values))
+	for _, v := range values {
+		var rowErr models.ImportRowError
+		if err := json.Unmarshal([]byte(v), &rowErr); err != nil {
+			r.log.Warn().Err(err).Str("import_id", id).Msg("Skipping malformed import row error")
+			continue
+		}
+		rowErrors = append(rowErrors, rowErr)
+	}
+	return rowErrors, nil
+}
+
+func importJobKey(id string) string {
+	return fmt.Sprintf("import:%s", id)
+}
+
+func importErrorsKey(id string) string {
+	return fmt.Sprintf("import:%s:errors", id)
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	errors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/jackc/pgx/v5"
+)
+
+// SetMFASecret stores a pending TOTP secret, replacing any earlier pending
+// one. It returns ErrMFAAlreadyEnabled rather than replace an active secret.
+func (r *AuthRepository) SetMFASecret(ctx context.Context, userID string, secret string) error {
+	var affected int64
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tag, err := r.db.Pool.Exec(ctx, `
+			UPDATE users SET mfa_secret = $2, updated_at = $3
+			WHERE id = $1 AND deleted_at IS NULL AND NOT mfa_enabled
+		`, userID, secret, time.Now())
+		affected = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// EnableMFA activates the pending secret, records step as used, resets the
+// count of invalid codes and replaces the user's recovery codes with
+// codeHashes.
+func (r *AuthRepository) EnableMFA(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	return r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tx, err := r.db.Pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		now := time.Now()
+		tag, err := tx.Exec(ctx, `
+			UPDATE users SET mfa_enabled = TRUE, mfa_last_step = $2, mfa_failures = 0, updated_at = $3
+			WHERE id = $1 AND deleted_at IS NULL AND NOT mfa_enabled AND mfa_secret IS NOT NULL
+		`, userID, step, now)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return errors.ErrMFAAlreadyEnabled
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at)
+			SELECT $1, unnest($2::text[]), $3
+		`, userID, codeHashes, now); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
+}
+
+// DisableMFA removes the user's secret and recovery codes.
+func (r *AuthRepository) DisableMFA(ctx context.Context, userID string) error {
+	return r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tx, err := r.db.Pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE users
+			SET mfa_enabled = FALSE, mfa_secret = NULL, mfa_last_step = 0,
+				mfa_challenge = NULL, mfa_failures = 0, mfa_locked_until = NULL, updated_at = $2
+			WHERE id = $1
+		`, userID, time.Now()); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
+}
+
+// UseMFAStep records that a code for step was accepted. It reports false if
+// step is not after the last accepted one, i.e. the code was already used.
+func (r *AuthRepository) UseMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	var affected int64
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tag, err := r.db.Pool.Exec(ctx, `
+			UPDATE users SET mfa_last_step = $2
+			WHERE id = $1 AND mfa_last_step < $2
+		`, userID, step)
+		affected = tag.RowsAffected()
+		return err
+	})
+	return affected == 1, err
+}
+
+// UseRecoveryCode marks the recovery code with codeHash as used. It reports
+// false if the user has no such unused code.
+func (r *AuthRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	var affected int64
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tag, err := r.db.Pool.Exec(ctx, `
+			UPDATE mfa_recovery_codes SET used_at = $3
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+		`, userID, codeHash, time.Now())
+		affected = tag.RowsAffected()
+		return err
+	})
+	return affected == 1, err
+}
+
+// SetMFAChallenge makes challengeID the only MFA challenge that can complete
+// a signin, superseding any issued before.
+func (r *AuthRepository) SetMFAChallenge(ctx context.Context, userID string, challengeID string) error {
+	return r.db.Guard.Idempotent(ctx, func(ctx context.Context) error {
+		_, err := r.db.Pool.Exec(ctx, `
+			UPDATE users SET mfa_challenge = $2 WHERE id = $1
+		`, userID, challengeID)
+		return err
+	})
+}
+
+// UseMFAChallenge consumes the challenge with challengeID and resets the
+// count of invalid codes. It reports false if the challenge is not the
+// current one, i.e. it was used or superseded.
+func (r *AuthRepository) UseMFAChallenge(ctx context.Context, userID string, challengeID string) (bool, error) {
+	var affected int64
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		tag, err := r.db.Pool.Exec(ctx, `
+			UPDATE users SET mfa_challenge = NULL, mfa_failures = 0
+			WHERE id = $1 AND mfa_challenge = $2
+		`, userID, challengeID)
+		affected = tag.RowsAffected()
+		return err
+	})
+	return affected == 1, err
+}
+
+// RecordMFAFailure counts an invalid code. The maxFailures-th in a row locks
+// the user's MFA codes until lockedUntil and drops the current challenge; it reports
+// whether this one did.
+func (r *AuthRepository) RecordMFAFailure(ctx context.Context, userID string, maxFailures int, lockedUntil time.Time) (bool, error) {
+	var locked bool
+	err := r.db.Guard.Call(ctx, func(ctx context.Context) error {
+		return r.db.Pool.QueryRow(ctx, `
+			UPDATE users SET
+				mfa_failures = CASE WHEN mfa_failures + 1 >= $2 THEN 0 ELSE mfa_failures + 1 END,
+				mfa_locked_until = CASE WHEN mfa_failures + 1 >= $2 THEN $3 ELSE mfa_locked_until END,
+				mfa_challenge = CASE WHEN mfa_failures + 1 >= $2 THEN NULL ELSE mfa_challenge END
+			WHERE id = $1
+			RETURNING mfa_failures = 0
+		`, userID, maxFailures, lockedUntil).Scan(&locked)
+	})
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	return locked, err
+}
@@ -0,0 +1,465 @@
+// Package repotest provides in-memory stand-ins for the repositories, so
+// services and handlers can be tested without Postgres.
+package repotest
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	errors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+)
+
+// EventStore is an in-process event store. It records queued events
+// instead of publishing them, ignoring IDs it has already seen, and keeps
+// processed events by ID.
+type EventStore struct {
+	mu        sync.Mutex
+	queued    []*models.Event
+	seen      map[string]bool
+	processed map[string]*models.Event
+}
+
+func NewEventStore() *EventStore {
+	return &EventStore{
+		seen:      make(map[string]bool),
+		processed: make(map[string]*models.Event),
+	}
+}
+
+func (s *EventStore) AddEvent(_ context.Context, apiKey string, id string, payload map[string]interface{}, evCtx models.EventContext) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queue(apiKey, repositories.QueuedEvent{ID: id, Payload: payload, Timestamp: time.Now(), Context: evCtx}), nil
+}
+
+func (s *EventStore) AddEvents(_ context.Context, apiKey string, events []repositories.QueuedEvent) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queued := 0
+	for _, ev := range events {
+		if s.queue(apiKey, ev) {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+func (s *EventStore) queue(apiKey string, ev repositories.QueuedEvent) bool {
+	if s.seen[ev.ID] {
+		return false
+	}
+	s.seen[ev.ID] = true
+	s.queued = append(s.queued, &models.Event{
+		ID:        ev.ID,
+		APIKey:    apiKey,
+		Payload:   ev.Payload,
+		Timestamp: ev.Timestamp,
+		Context:   ev.Context,
+	})
+	return true
+}
+
+// Queued returns the events added so far, oldest first.
+func (s *EventStore) Queued() []*models.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*models.Event(nil), s.queued...)
+}
+
+func (s *EventStore) SaveProcessedEvent(_ context.Context, ev *models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.processed[ev.ID]; !ok {
+		s.processed[ev.ID] = ev
+	}
+	return nil
+}
+
+// Processed returns the stored event with id, or nil.
+func (s *EventStore) Processed(id string) *models.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processed[id]
+}
+
+// UserStore is an in-process user store. Like the users table it matches
+// emails case-insensitively, keeps emails and api keys unique and hides
+// deleted users.
+type UserStore struct {
+	mu      sync.Mutex
+	byID    map[string]*models.User
+	apiKeys map[string]bool
+	// identities maps provider+"\x00"+subject to a user ID.
+	identities map[string]string
+	// recoveryCodes maps user ID to unused recovery code hashes.
+	recoveryCodes map[string]map[string]bool
+	// mfaFailures maps user ID to invalid MFA codes since the last success.
+	mfaFailures map[string]int
+	erasures    map[string]time.Time
+}
+
+func NewUserStore() *UserStore {
+	return &UserStore{
+		byID:          make(map[string]*models.User),
+		apiKeys:       make(map[string]bool),
+		identities:    make(map[string]string),
+		recoveryCodes: make(map[string]map[string]bool),
+		mfaFailures:   make(map[string]int),
+		erasures:      make(map[string]time.Time),
+	}
+}
+
+func (s *UserStore) CreateUser(_ context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findEmail(user.Email) != nil {
+		return errors.ErrUserAlreadyExists
+	}
+	if s.apiKeys[user.Api_Key] {
+		return errors.ErrAPIKeyConflict
+	}
+	stored := *user
+	s.byID[user.ID] = &stored
+	s.apiKeys[user.Api_Key] = true
+	return nil
+}
+
+func (s *UserStore) GetUserByEmail(_ context.Context, email string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user := s.findEmail(email); user != nil {
+		found := *user
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (s *UserStore) GetUserByID(_ context.Context, id string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *user
+	return &found, nil
+}
+
+func (s *UserStore) UpdateUser(_ context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.byID[user.ID]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	if other := s.findEmail(user.Email); other != nil && other.ID != user.ID {
+		return errors.ErrUserAlreadyExists
+	}
+	stored.Name = user.Name
+	stored.Email = user.Email
+	stored.IsVerified = user.IsVerified
+	stored.PendingEmail = user.PendingEmail
+	stored.UpdatedAt = user.UpdatedAt
+	return nil
+}
+
+func (s *UserStore) UpdatePassword(_ context.Context, id string, hashed string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.byID[id]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	stored.Password = hashed
+	stored.UpdatedAt = at
+	return nil
+}
+
+func (s *UserStore) ChangePassword(_ context.Context, id string, hashed string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.byID[id]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	stored.Password = hashed
+	stored.TokenVersion++
+	stored.UpdatedAt = at
+	return nil
+}
+
+func (s *UserStore) TokenVersion(_ context.Context, id string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[id]
+	if !ok {
+		return 0, false, nil
+	}
+	return user.TokenVersion, true, nil
+}
+
+func (s *UserStore) DeleteUser(_ context.Context, id string, eraseAfter time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byID[id]; !ok {
+		return errors.ErrUserNotFound
+	}
+	delete(s.byID, id)
+	for key, userID := range s.identities {
+		if userID == id {
+			delete(s.identities, key)
+		}
+	}
+	s.erasures[id] = eraseAfter
+	return nil
+}
+
+func (s *UserStore) GetUserByIdentity(_ context.Context, provider string, subject string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[s.identities[provider+"\x00"+subject]]
+	if !ok {
+		return nil, nil
+	}
+	found := *user
+	return &found, nil
+}
+
+func (s *UserStore) LinkIdentity(_ context.Context, userID string, provider string, subject string, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := provider + "\x00" + subject
+	if _, ok := s.identities[key]; !ok {
+		s.identities[key] = userID
+	}
+	return nil
+}
+
+func (s *UserStore) SetMFASecret(_ context.Context, userID string, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[userID]
+	if !ok || user.MFAEnabled {
+		return errors.ErrMFAAlreadyEnabled
+	}
+	user.MFASecret = secret
+	return nil
+}
+
+func (s *UserStore) EnableMFA(_ context.Context, userID string, step int64, codeHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[userID]
+	if !ok || user.MFAEnabled || user.MFASecret == "" {
+		return errors.ErrMFAAlreadyEnabled
+	}
+	user.MFAEnabled = true
+	user.MFALastStep = step
+	delete(s.mfaFailures, userID)
+	codes := make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes[hash] = true
+	}
+	s.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (s *UserStore) DisableMFA(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.byID[userID]; ok {
+		user.MFAEnabled = false
+		user.MFASecret = ""
+		user.MFALastStep = 0
+		user.MFAChallenge = ""
+		user.MFALockedUntil = nil
+	}
+	delete(s.recoveryCodes, userID)
+	delete(s.mfaFailures, userID)
+	return nil
+}
+
+func (s *UserStore) UseMFAStep(_ context.Context, userID string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[userID]
+	if !ok || step <= user.MFALastStep {
+		return false, nil
+	}
+	user.MFALastStep = step
+	return true, nil
+}
+
+func (s *UserStore) UseRecoveryCode(_ context.Context, userID string, codeHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.recoveryCodes[userID][codeHash] {
+		return false, nil
+	}
+	delete(s.recoveryCodes[userID], codeHash)
+	return true, nil
+}
+
+func (s *UserStore) SetMFAChallenge(_ context.Context, userID string, challengeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.byID[userID]; ok {
+		user.MFAChallenge = challengeID
+	}
+	return nil
+}
+
+func (s *UserStore) UseMFAChallenge(_ context.Context, userID string, challengeID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[userID]
+	if !ok || user.MFAChallenge == "" || user.MFAChallenge != challengeID {
+		return false, nil
+	}
+	user.MFAChallenge = ""
+	delete(s.mfaFailures, userID)
+	return true, nil
+}
+
+func (s *UserStore) RecordMFAFailure(_ context.Context, userID string, maxFailures int, lockedUntil time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.byID[userID]
+	if !ok {
+		return false, nil
+	}
+	s.mfaFailures[userID]++
+	if s.mfaFailures[userID] < maxFailures {
+		return false, nil
+	}
+	delete(s.mfaFailures, userID)
+	user.MFALockedUntil = &lockedUntil
+	user.MFAChallenge = ""
+	return true, nil
+}
+
+// ErasureScheduled reports when the deleted user id's data is due for erasure.
+func (s *UserStore) ErasureScheduled(id string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.erasures[id]
+	return at, ok
+}
+
+func (s *UserStore) findEmail(email string) *models.User {
+	email = strings.ToLower(strings.TrimSpace(email))
+	for _, user := range s.byID {
+		if strings.ToLower(strings.TrimSpace(user.Email)) == email {
+			return user
+		}
+	}
+	return nil
+}
+
+// RuleStore is an in-process rule store. Like the rules table it lists a
+// project's rules by position, then by creation time.
+type RuleStore struct {
+	mu    sync.Mutex
+	rules []*models.Rule
+}
+
+func NewRuleStore() *RuleStore {
+	return &RuleStore{}
+}
+
+func (s *RuleStore) CreateRule(_ context.Context, rule *models.Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *rule
+	s.rules = append(s.rules, &stored)
+	return nil
+}
+
+func (s *RuleStore) ListRules(_ context.Context, apiKey string) ([]*models.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := []*models.Rule{}
+	for _, rule := range s.rules {
+		if rule.APIKey == apiKey {
+			copied := *rule
+			rules = append(rules, &copied)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Position != rules[j].Position {
+			return rules[i].Position < rules[j].Position
+		}
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+	})
+	return rules, nil
+}
+
+func (s *RuleStore) GetRule(_ context.Context, apiKey string, id string) (*models.Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(apiKey, id)
+	if i < 0 {
+		return nil, errors.ErrRuleNotFound
+	}
+	rule := *s.rules[i]
+	return &rule, nil
+}
+
+func (s *RuleStore) UpdateRule(_ context.Context, rule *models.Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(rule.APIKey, rule.ID)
+	if i < 0 {
+		return errors.ErrRuleNotFound
+	}
+	updated := *rule
+	updated.CreatedAt = s.rules[i].CreatedAt
+	s.rules[i] = &updated
+	return nil
+}
+
+func (s *RuleStore) DeleteRule(_ context.Context, apiKey string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(apiKey, id)
+	if i < 0 {
+		return errors.ErrRuleNotFound
+	}
+	s.rules = append(s.rules[:i], s.rules[i+1:]...)
+	return nil
+}
+
+func (s *RuleStore) find(apiKey string, id string) int {
+	for i, rule := range s.rules {
+		if rule.APIKey == apiKey && rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	errors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+const ruleColumns = `id, name, position, enabled, condition, action, field, target, value, created_at, updated_at`
+
+type RuleRepository struct {
+	db  *db.DB
+	log zerolog.Logger
+}
+
+func NewRuleRepository(db *db.DB, log zerolog.Logger) *RuleRepository {
+	return &RuleRepository{
+		db:  db,
+		log: log.With().Str("repository", "rule").Logger(),
+	}
+}
+
+func (r *RuleRepository) CreateRule(ctx context.Context, rule *models.Rule) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO rules (id, api_key, name, position, enabled, condition, action, field, target, value, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, rule.ID, rule.APIKey, rule.Name, rule.Position, rule.Enabled, rule.Condition, rule.Action,
+		rule.Field, rule.Target, rule.Value, rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// ListRules returns the project's rules in evaluation order.
+func (r *RuleRepository) ListRules(ctx context.Context, apiKey string) ([]*models.Rule, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+ruleColumns+`
+		FROM rules WHERE api_key = $1
+		ORDER BY position, created_at
+	`, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []*models.Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows, apiKey)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *RuleRepository) GetRule(ctx context.Context, apiKey string, id string) (*models.Rule, error) {
+	rule, err := scanRule(r.db.Pool.QueryRow(ctx, `
+		SELECT `+ruleColumns+`
+		FROM rules WHERE api_key = $1 AND id = $2
+	`, apiKey, id), apiKey)
+	if err == pgx.ErrNoRows {
+		return nil, errors.ErrRuleNotFound
+	}
+	return rule, err
+}
+
+func (r *RuleRepository) UpdateRule(ctx context.Context, rule *models.Rule) error {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE rules SET
+			name = $3, position = $4, enabled = $5, condition = $6, action = $7,
+			field = $8, target = $9, value = $10, updated_at = $11
+		WHERE api_key = $1 AND id = $2
+	`, rule.APIKey, rule.ID, rule.Name, rule.Position, rule.Enabled, rule.Condition, rule.Action,
+		rule.Field, rule.Target, rule.Value, rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepository) DeleteRule(ctx context.Context, apiKey string, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM rules WHERE api_key = $1 AND id = $2`, apiKey, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrRuleNotFound
+	}
+	return nil
+}
+
+func scanRule(row pgx.Row, apiKey string) (*models.Rule, error) {
+	rule := &models.Rule{APIKey: apiKey}
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Position, &rule.Enabled, &rule.Condition, &rule.Action,
+		&rule.Field, &rule.Target, &rule.Value, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	errors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const planCacheTTL = 5 * time.Minute
+
+// consumeQuotaScript adds n to the monthly counter only if the result stays
+// within the limit, so the counter never drifts past the quota under
+// concurrent writes. A limit of 0 means unlimited. Returns {allowed, used}.
+var consumeQuotaScript = redis.NewScript(`
+local key = KEYS[1]
+local n = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local expire_at = tonumber(ARGV[3])
+
+local used = tonumber(redis.call('GET', key) or '0')
+if limit > 0 and used + n > limit then
+	return {0, used}
+end
+
+used = redis.call('INCRBY', key, n)
+if used == n then
+	redis.call('EXPIREAT', key, expire_at)
+end
+return {1, used}
+`)
+
+// releaseQuotaScript takes back up to n previously counted events without
+// letting the counter go below zero. Returns the usage after releasing.
+var releaseQuotaScript = redis.NewScript(`
+local key = KEYS[1]
+local n = tonumber(ARGV[1])
+
+local used = tonumber(redis.call('GET', key) or '0')
+if used <= 0 then
+	return 0
+end
+return redis.call('DECRBY', key, math.min(n, used))
+`)
+
+type UsageRepository struct {
+	db    *db.DB
+	redis *redis.Client
+	log   zerolog.Logger
+}
+
+func NewUsageRepository(db *db.DB, redisClient *redis.Client, log zerolog.Logger) *UsageRepository {
+	return &UsageRepository{
+		db:    db,
+		redis: redisClient,
+		log:   log.With().Str("repository", "usage").Logger(),
+	}
+}
+
+func (r *UsageRepository) GetPlanByAPIKey(ctx context.Context, apiKey string) (string, error) {
+	cacheKey := fmt.Sprintf("plan:%s", apiKey)
+	if plan, err := r.redis.Get(ctx, cacheKey).Result(); err == nil {
+		return plan, nil
+	}
+
+	var plan string
+	err := r.db.Pool.QueryRow(ctx, `SELECT plan FROM users WHERE api_key = $1 LIMIT 1`, apiKey).Scan(&plan)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", errors.ErrUserNotFound
+		}
+		return "", err
+	}
+
+	if err := r.redis.Set(ctx, cacheKey, plan, planCacheTTL).Err(); err != nil {
+		r.log.Warn().Err(err).Msg("Failed to cache plan")
+	}
+
+	return plan, nil
+}
+
+// ConsumeMonthlyEvents counts n events against the api key's quota for the
+// given period. It reports whether the events were within the limit and the
+// usage after counting them; nothing is counted when they were not.
+func (r *UsageRepository) ConsumeMonthlyEvents(ctx context.Context, apiKey string, period time.Time, n int64, limit int64, resetsAt time.Time) (bool, int64, error) {
+	res, err := consumeQuotaScript.Run(ctx, r.redis, []string{monthlyUsageKey(apiKey, period)},
+		n,
+		limit,
+		resetsAt.Add(24*time.Hour).Unix(),
+	).Int64Slice()
+	if err != nil {
+		r.log.Error().Err(err).Msg("Failed to consume monthly quota")
+		return false, 0, err
+	}
+
+	return res[0] == 1, res[1], nil
+}
+
+// ReleaseMonthlyEvents returns n events counted by ConsumeMonthlyEvents to
+// the api key's quota for the given period.
+func (r *UsageRepository) ReleaseMonthlyEvents(ctx context.Context, apiKey string, period time.Time, n int64) error {
+	err := releaseQuotaScript.Run(ctx, r.redis, []string{monthlyUsageKey(apiKey, period)}, n).Err()
+	if err != nil {
+		r.log.Error().Err(err).Msg("Failed to release monthly quota")
+	}
+	return err
+}
+
+func (r *UsageRepository) GetMonthlyEvents(ctx context.Context, apiKey string, period time.Time) (int64, error) {
+	used, err := r.redis.Get(ctx, monthlyUsageKey(apiKey, period)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return used, nil
+}
+
+func monthlyUsageKey(apiKey string, period time.Time) string {
+	return fmt.Sprintf("usage:events:%s:%s", apiKey, period.Format("2006-01"))
+}
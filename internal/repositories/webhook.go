@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	errors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.response_status, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at`
+
+type WebhookRepository struct {
+	db  *db.DB
+	log zerolog.Logger
+}
+
+func NewWebhookRepository(db *db.DB, log zerolog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		db:  db,
+		log: log.With().Str("repository", "webhook").Logger(),
+	}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, wh *models.Webhook) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO webhooks (id, api_key, url, event_types, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, wh.ID, wh.APIKey, wh.URL, wh.EventTypes, wh.Secret, wh.CreatedAt)
+	return err
+}
+
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, apiKey string) ([]*models.Webhook, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, url, event_types, secret, created_at
+		FROM webhooks
+		WHERE api_key = $1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		wh := &models.Webhook{APIKey: apiKey}
+		if err := rows.Scan(&wh.ID, &wh.URL, &wh.EventTypes, &wh.Secret, &wh.CreatedAt); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook soft-deletes the webhook so its delivery log stays readable.
+// Pending deliveries are abandoned.
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, apiKey string, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE webhooks SET deleted_at = $3
+		WHERE api_key = $1 AND id = $2 AND deleted_at IS NULL
+	`, apiKey, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// CreateDeliveries queues deliveries in one round trip.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(`
+			INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, d.ID, d.WebhookID, d.EventID, d.EventType, []byte(d.Payload), d.Status, d.NextAttemptAt, d.CreatedAt)
+	}
+	return r.db.Pool.SendBatch(ctx, batch).Close()
+}
+
+// ListDeliveries returns the newest deliveries of a webhook owned by apiKey.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, apiKey string, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE w.api_key = $1 AND d.webhook_id = $2
+		ORDER BY d.created_at DESC
+		LIMIT $3
+	`, apiKey, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, apiKey string, webhookID string, id string) (*models.WebhookDelivery, error) {
+	d, err := scanDelivery(r.db.Pool.QueryRow(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE w.api_key = $1 AND d.webhook_id = $2 AND d.id = $3 AND w.deleted_at IS NULL
+	`, apiKey, webhookID, id))
+	if err == pgx.ErrNoRows {
+		return nil, errors.ErrDeliveryNotFound
+	}
+	return d, err
+}
+
+// ClaimDueDeliveries leases up to limit pending deliveries whose time has
+// come by pushing their next attempt lease into the future. A worker that
+// dies mid-delivery therefore only delays the retry until the lease expires.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	now := time.Now()
+	rows, err := r.db.Pool.Query(ctx, `
+		UPDATE webhook_deliveries d SET next_attempt_at = $2
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND w.deleted_at IS NULL AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns+`, w.url, w.secret
+	`, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d := &models.WebhookDelivery{}
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt stores the outcome of a delivery attempt. The caller decides
+// the resulting status and, for retries, when the next attempt is due.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		UPDATE webhook_deliveries SET
+			status = $2, attempts = $3, response_status = $4, last_error = $5,
+			next_attempt_at = $6, delivered_at = $7
+		WHERE id = $1
+	`, d.ID, d.Status, d.Attempts, d.ResponseStatus, d.LastError, d.NextAttemptAt, d.DeliveredAt)
+	return err
+}
+
+// Reschedule moves a claimed delivery's next attempt without counting an
+// attempt, e.g. while the endpoint's circuit is open.
+func (r *WebhookRepository) Reschedule(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE webhook_deliveries SET next_attempt_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+func scanDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{}
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/db"
+	errors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/rs/zerolog"
+)
+
+type WriteKeyRepository struct {
+	db  *db.DB
+	log zerolog.Logger
+}
+
+func NewWriteKeyRepository(db *db.DB, log zerolog.Logger) *WriteKeyRepository {
+	return &WriteKeyRepository{
+		db:  db,
+		log: log.With().Str("repository", "write_key").Logger(),
+	}
+}
+
+func (r *WriteKeyRepository) CreateWriteKey(ctx context.Context, apiKey string, origins []string) (*models.WriteKey, error) {
+	var userID string
+	err := r.db.Pool.QueryRow(ctx, `SELECT id FROM users WHERE api_key = $1`, apiKey).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	key, err := gonanoid.New()
+	if err != nil {
+		return nil, err
+	}
+
+	if origins == nil {
+		origins = []string{}
+	}
+
+	wk := &models.WriteKey{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		Key:            "wk_" + key,
+		AllowedOrigins: origins,
+		OwnerAPIKey:    apiKey,
+		CreatedAt:      time.Now(),
+	}
+
+	_, err = r.db.Pool.Exec(ctx, `
+		INSERT INTO write_keys (id, user_id, key, allowed_origins, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, wk.ID, wk.UserID, wk.Key, wk.AllowedOrigins, wk.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return wk, nil
+}
+
+func (r *WriteKeyRepository) ListWriteKeys(ctx context.Context, apiKey string) ([]*models.WriteKey, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT wk.id, wk.user_id, wk.key, wk.allowed_origins, wk.created_at, wk.revoked_at
+		FROM write_keys wk
+		JOIN users u ON u.id = wk.user_id
+		WHERE u.api_key = $1
+		ORDER BY wk.created_at DESC
+	`, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*models.WriteKey{}
+	for rows.Next() {
+		wk := &models.WriteKey{OwnerAPIKey: apiKey}
+		if err := rows.Scan(&wk.ID, &wk.UserID, &wk.Key, &wk.AllowedOrigins, &wk.CreatedAt, &wk.RevokedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, wk)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *WriteKeyRepository) RevokeWriteKey(ctx context.Context, apiKey string, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE write_keys wk
+		SET revoked_at = $3
+		FROM users u
+		WHERE u.id = wk.user_id AND u.api_key = $1 AND wk.id = $2 AND wk.revoked_at IS NULL
+	`, apiKey, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.ErrWriteKeyNotFound
+	}
+	return nil
+}
+
+// GetActiveWriteKey returns the write key together with its owner's api key,
+// or nil if the key does not exist or has been revoked.
+func (r *WriteKeyRepository) GetActiveWriteKey(ctx context.Context, key string) (*models.WriteKey, error) {
+	wk := &models.WriteKey{}
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT wk.id, wk.user_id, wk.key, wk.allowed_origins, wk.created_at, u.api_key
+		FROM write_keys wk
+		JOIN users u ON u.id = wk.user_id
+		WHERE wk.key = $1 AND wk.revoked_at IS NULL
+		LIMIT 1
+	`, key).Scan(&wk.ID, &wk.UserID, &wk.Key, &wk.AllowedOrigins, &wk.CreatedAt, &wk.OwnerAPIKey)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return wk, nil
+}
@@ -0,0 +1,186 @@
+// Package resilience guards calls to backing services (Postgres, Redis) with
+// per-dependency circuit breakers and retries idempotent calls with jittered
+// exponential backoff.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+// metrics is published at /debug/vars as "dependencies", one map per breaker.
+var metrics = expvar.NewMap("dependencies")
+
+type BreakerOptions struct {
+	// Threshold consecutive failures open the circuit for Cooldown.
+	Threshold int
+	Cooldown  time.Duration
+	// IsFailure reports whether err means the dependency is unhealthy, as
+	// opposed to e.g. a missing row. Defaults to any non-nil error other
+	// than the caller cancelling.
+	IsFailure func(err error) bool
+}
+
+// Breaker is a consecutive-failure circuit breaker. Once open, calls fail
+// with ErrDependencyUnavailable until the cooldown passes; then a single probe
+// call is let through and its outcome closes or re-opens the circuit.
+type Breaker struct {
+	name string
+	opts BreakerOptions
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+
+	stats *expvar.Map
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Breaker{}
+)
+
+// NewBreaker creates the breaker for a dependency and registers it for
+// States and metrics. Creating a second breaker with the same name replaces
+// the first in both.
+func NewBreaker(name string, opts BreakerOptions) *Breaker {
+	if opts.Threshold <= 0 {
+		opts.Threshold = 5
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = 10 * time.Second
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = defaultIsFailure
+	}
+
+	b := &Breaker{
+		name:  name,
+		opts:  opts,
+		state: StateClosed,
+		now:   time.Now,
+		stats: new(expvar.Map).Init(),
+	}
+	b.stats.Set("state", expvar.Func(func() any { return b.State() }))
+	metrics.Set(name, b.stats)
+
+	registryMu.Lock()
+	registry[name] = b
+	registryMu.Unlock()
+
+	return b
+}
+
+func (b *Breaker) Name() string { return b.name }
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && !b.now().Before(b.openUntil) {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Do calls fn unless the circuit is open. A nil Breaker just calls fn.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if b == nil {
+		return fn(ctx)
+	}
+	if !b.allow() {
+		b.stats.Add("rejected", 1)
+		return fmt.Errorf("%s: %w", b.name, internalErrors.ErrDependencyUnavailable)
+	}
+
+	err := fn(ctx)
+	b.record(err)
+	return err
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		if b.now().Before(b.openUntil) {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = false
+	}
+
+	// Half-open: only one probe at a time.
+	if b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil || !b.opts.IsFailure(err) {
+		if b.state != StateClosed {
+			b.stats.Add("closed", 1)
+		}
+		b.state = StateClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.stats.Add("failures", 1)
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.opts.Threshold {
+		if b.state != StateOpen {
+			b.stats.Add("opened", 1)
+		}
+		b.state = StateOpen
+		b.openUntil = b.now().Add(b.opts.Cooldown)
+		b.probing = false
+	}
+}
+
+// States returns the state of every registered breaker by name.
+func States() map[string]State {
+	registryMu.Lock()
+	breakers := make([]*Breaker, 0, len(registry))
+	for _, b := range registry {
+		breakers = append(breakers, b)
+	}
+	registryMu.Unlock()
+
+	sort.Slice(breakers, func(i, j int) bool { return breakers[i].name < breakers[j].name })
+	states := make(map[string]State, len(breakers))
+	for _, b := range breakers {
+		states[b.name] = b.State()
+	}
+	return states
+}
+
+func defaultIsFailure(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,77 @@
+package resilience
+
+import (
+	"context"
+	"time"
+)
+
+// Dependency pairs a backing service's breaker with the retry policy for its
+// idempotent calls. A nil *Dependency calls straight through, so tests and
+// tools can leave it unset.
+type Dependency struct {
+	breaker *Breaker
+	retry   RetryPolicy
+	// timeout bounds each attempt; 0 leaves the caller's context alone.
+	timeout time.Duration
+}
+
+type Options struct {
+	Breaker BreakerOptions
+	Retry   RetryPolicy
+	Timeout time.Duration
+}
+
+func NewDependency(name string, opts Options) *Dependency {
+	if opts.Retry.Retryable == nil {
+		opts.Retry.Retryable = opts.Breaker.IsFailure
+	}
+	return &Dependency{
+		breaker: NewBreaker(name, opts.Breaker),
+		retry:   opts.Retry,
+		timeout: opts.Timeout,
+	}
+}
+
+func (d *Dependency) Breaker() *Breaker {
+	if d == nil {
+		return nil
+	}
+	return d.breaker
+}
+
+// Call makes a single attempt through the breaker.
+func (d *Dependency) Call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if d == nil {
+		return fn(ctx)
+	}
+	return d.breaker.Do(ctx, d.withTimeout(fn))
+}
+
+// Idempotent is Call with retries. Each attempt goes through the breaker, so
+// retries stop as soon as the circuit opens.
+func (d *Dependency) Idempotent(ctx context.Context, fn func(ctx context.Context) error) error {
+	if d == nil {
+		return fn(ctx)
+	}
+	call := d.withTimeout(fn)
+	attempts := 0
+	err := Retry(ctx, d.retry, func(ctx context.Context) error {
+		attempts++
+		return d.breaker.Do(ctx, call)
+	})
+	if attempts > 1 {
+		d.breaker.stats.Add("retries", int64(attempts-1))
+	}
+	return err
+}
+
+func (d *Dependency) withTimeout(fn func(ctx context.Context) error) func(ctx context.Context) error {
+	if d.timeout <= 0 {
+		return fn
+	}
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+		return fn(ctx)
+	}
+}
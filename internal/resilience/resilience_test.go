@@ -0,0 +1,120 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+)
+
+var errDown = errors.New("connection refused")
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	b := NewBreaker("test_breaker", BreakerOptions{Threshold: 2, Cooldown: time.Minute})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	fail := func(context.Context) error { return errDown }
+	ok := func(context.Context) error { return nil }
+
+	b.Do(ctx, fail)
+	b.Do(ctx, fail)
+	if b.State() != StateOpen {
+		t.Fatalf("state after threshold failures = %s, want open", b.State())
+	}
+
+	called := false
+	err := b.Do(ctx, func(context.Context) error { called = true; return nil })
+	if !errors.Is(err, internalErrors.ErrDependencyUnavailable) || called {
+		t.Fatalf("open breaker: err=%v called=%v", err, called)
+	}
+
+	now = now.Add(time.Minute)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("state after cooldown = %s, want half_open", b.State())
+	}
+	// A failed probe re-opens the circuit straight away.
+	b.Do(ctx, fail)
+	if b.State() != StateOpen {
+		t.Fatalf("state after failed probe = %s, want open", b.State())
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Do(ctx, ok); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("state after successful probe = %s, want closed", b.State())
+	}
+	if States()["test_breaker"] != StateClosed {
+		t.Fatalf("States() = %v", States())
+	}
+}
+
+func TestBreakerIgnoresNonFailures(t *testing.T) {
+	notFound := errors.New("not found")
+	b := NewBreaker("test_classified", BreakerOptions{
+		Threshold: 1,
+		IsFailure: func(err error) bool { return !errors.Is(err, notFound) },
+	})
+
+	b.Do(context.Background(), func(context.Context) error { return notFound })
+	if b.State() != StateClosed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	attempts := 0
+	err := Retry(ctx, policy, func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errDown
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("Retry = %v after %d attempts, want success after 3", err, attempts)
+	}
+
+	attempts = 0
+	err = Retry(ctx, policy, func(context.Context) error {
+		attempts++
+		return errDown
+	})
+	if !errors.Is(err, errDown) || attempts != 3 {
+		t.Fatalf("Retry = %v after %d attempts, want errDown after 3", err, attempts)
+	}
+
+	attempts = 0
+	Retry(ctx, policy, func(context.Context) error {
+		attempts++
+		return internalErrors.ErrDependencyUnavailable
+	})
+	if attempts != 1 {
+		t.Fatalf("open circuit was retried %d times", attempts-1)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	for attempt := range 40 {
+		if d := backoff(p, attempt); d <= 0 || d > p.MaxDelay {
+			t.Fatalf("backoff(%d) = %s, want (0, %s]", attempt, d, p.MaxDelay)
+		}
+	}
+}
+
+func TestNilDependencyCallsThrough(t *testing.T) {
+	var d *Dependency
+	called := false
+	if err := d.Idempotent(context.Background(), func(context.Context) error { called = true; return nil }); err != nil || !called {
+		t.Fatalf("nil dependency: err=%v called=%v", err, called)
+	}
+}
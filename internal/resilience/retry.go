@@ -0,0 +1,65 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+)
+
+type RetryPolicy struct {
+	// MaxAttempts includes the first call; 1 or less means no retries.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Retryable reports whether a failed attempt may be retried. Open
+	// circuits and cancellations are never retried.
+	Retryable func(err error) bool
+}
+
+// Retry calls fn until it succeeds, returns a non-retryable error, the
+// attempts run out or ctx ends. Between attempts it sleeps for a random
+// duration up to BaseDelay*2^attempt, capped at MaxDelay ("full jitter").
+// Only use it for idempotent operations.
+func Retry(ctx context.Context, p RetryPolicy, fn func(ctx context.Context) error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = defaultRetryable
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || attempt+1 >= p.MaxAttempts || ctx.Err() != nil || !retryable(err) || !defaultRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff(p, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func backoff(p RetryPolicy, attempt int) time.Duration {
+	ceiling := p.MaxDelay
+	if attempt < 32 {
+		if d := p.BaseDelay << attempt; d > 0 && (ceiling <= 0 || d < ceiling) {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+func defaultRetryable(err error) bool {
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, internalErrors.ErrDependencyUnavailable)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAuditRoutes serves the caller's audit log; exports are themselves
+// recorded with rec.
+func SetupAuditRoutes(router gin.IRouter, h *handler.AuditHandler, auth gin.HandlerFunc, rec middleware.AuditRecorder) {
+	auditLog := router.Group("/audit")
+	{
+		auditLog.GET("", auth, h.ListAudit)
+		auditLog.GET("/export", audited(rec, audit.ActionAuditExport, []gin.HandlerFunc{auth}, h.ExportAudit)...)
+	}
+}
+
+// audited returns the handlers of a route recorded in the audit log as
+// action: the audit middleware, then mw and handler. Audited routes take
+// their middleware this way rather than from their group, which would run it
+// first, so requests mw rejects, e.g. rate limited or unauthenticated ones,
+// are recorded too.
+func audited(rec middleware.AuditRecorder, action string, mw []gin.HandlerFunc, handler gin.HandlerFunc) []gin.HandlerFunc {
+	handlers := make([]gin.HandlerFunc, 0, len(mw)+2)
+	handlers = append(handlers, middleware.AuditMiddleware(rec, action))
+	handlers = append(handlers, mw...)
+	return append(handlers, handler)
+}
@@ -1,14 +1,35 @@
 package routes
 
 import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
 	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAuthRoutes(router gin.IRouter, h *handler.AuthHandler) {
-	auth := router.Group("/auth")
+// SetupAuthRoutes applies mw (e.g. rate limiting) to every auth route; the
+// account routes additionally require auth. Signins and account changes are
+// recorded with rec, including those mw or auth reject.
+func SetupAuthRoutes(router gin.IRouter, h *handler.AuthHandler, auth gin.HandlerFunc, rec middleware.AuditRecorder, mw ...gin.HandlerFunc) {
+	account := append(mw[:len(mw):len(mw)], auth)
+
+	routes := router.Group("/auth")
+	{
+		routes.POST("/signup", audited(rec, audit.ActionSignup, mw, h.Signup)...)
+		routes.POST("/signin", audited(rec, audit.ActionSignin, mw, h.Signin)...)
+		routes.POST("/signin/mfa", audited(rec, audit.ActionSigninMFA, mw, h.SigninMFA)...)
+		routes.POST("/confirm-email", audited(rec, audit.ActionEmailConfirm, mw, h.ConfirmEmail)...)
+
+		routes.PATCH("/me", audited(rec, audit.ActionAccountUpdate, account, h.UpdateAccount)...)
+		routes.DELETE("/me", audited(rec, audit.ActionAccountDelete, account, h.DeleteAccount)...)
+		routes.POST("/change-password", audited(rec, audit.ActionPasswordChange, account, h.ChangePassword)...)
+		routes.POST("/mfa/enroll", audited(rec, audit.ActionMFAEnroll, account, h.EnrollMFA)...)
+		routes.POST("/mfa/verify", audited(rec, audit.ActionMFAEnable, account, h.VerifyMFA)...)
+		routes.DELETE("/mfa", audited(rec, audit.ActionMFADisable, account, h.DisableMFA)...)
+	}
+
+	reads := router.Group("/auth", account...)
 	{
-		auth.POST("/signup", h.Signup)
-		auth.POST("/signin", h.Signin)
+		reads.GET("/me", h.GetAccount)
 	}
 }
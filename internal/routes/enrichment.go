@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupEnrichmentRoutes(router gin.IRouter, h *handler.EnrichmentHandler, auth gin.HandlerFunc, rec middleware.AuditRecorder) {
+	authed := []gin.HandlerFunc{auth}
+
+	enrichment := router.Group("/enrichment")
+	{
+		enrichment.GET("", auth, h.GetSettings)
+		enrichment.PUT("", audited(rec, audit.ActionEnrichmentSave, authed, h.UpdateSettings)...)
+	}
+}
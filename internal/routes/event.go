@@ -2,13 +2,14 @@ package routes
 
 import (
 	"github.com/Vighnesh-V-H/sync/internal/handler"
-	"github.com/Vighnesh-V-H/sync/internal/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupEventRoutes(router gin.IRouter, h *handler.EventHandler, secret string) {
+// SetupEventRoutes applies mw (e.g. rate limiting) after authentication so it can key on the api key.
+func SetupEventRoutes(router gin.IRouter, h *handler.EventHandler, auth gin.HandlerFunc, mw ...gin.HandlerFunc) {
 	event := router.Group("/event")
-	event.Use(middleware.AuthMiddleware(secret))
+	event.Use(auth)
+	event.Use(mw...)
 	{
 		event.POST("/add", h.AddEvent)
 	}
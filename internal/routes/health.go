@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupHealthRoutes(router gin.IRouter, h *handler.HealthHandler) {
+	router.GET("/healthz", h.Live)
+	router.GET("/readyz", h.Ready)
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupIdentityRoutes applies mw to the ingestion endpoints only; person
+// lookups are management reads.
+func SetupIdentityRoutes(router gin.IRouter, h *handler.IdentityHandler, auth gin.HandlerFunc, mw ...gin.HandlerFunc) {
+	ingest := router.Group("")
+	ingest.Use(auth)
+	ingest.Use(mw...)
+	{
+		ingest.POST("/identify", h.Identify)
+		ingest.POST("/alias", h.Alias)
+	}
+
+	persons := router.Group("/persons")
+	persons.Use(auth)
+	{
+		persons.GET("/:distinct_id", h.GetPerson)
+	}
+}
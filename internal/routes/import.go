@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupImportRoutes is separate from SetupEventRoutes because imports take
+// much larger bodies than single events and need their own size limits in mw.
+func SetupImportRoutes(router gin.IRouter, h *handler.ImportHandler, auth gin.HandlerFunc, rec middleware.AuditRecorder, mw ...gin.HandlerFunc) {
+	ingest := append([]gin.HandlerFunc{auth}, mw...)
+
+	imports := router.Group("/event/import")
+	{
+		imports.POST("", audited(rec, audit.ActionImportStart, ingest, h.StartImport)...)
+	}
+
+	jobs := router.Group("/event/import", ingest...)
+	{
+		jobs.GET("/:id", h.GetImport)
+	}
+}
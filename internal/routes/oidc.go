@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupOIDCRoutes applies mw (e.g. the auth rate limit) to the OIDC login
+// routes and records logins with rec, including those mw rejects.
+func SetupOIDCRoutes(router gin.IRouter, h *handler.OIDCHandler, rec middleware.AuditRecorder, mw ...gin.HandlerFunc) {
+	oidc := router.Group("/auth/oidc")
+	{
+		oidc.GET("/:provider/callback", audited(rec, audit.ActionOIDCSignin, mw, h.Callback)...)
+	}
+
+	login := router.Group("/auth/oidc", mw...)
+	{
+		login.GET("", h.ListProviders)
+		login.GET("/:provider/login", h.Login)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupRuleRoutes(router gin.IRouter, h *handler.RuleHandler, auth gin.HandlerFunc, rec middleware.AuditRecorder) {
+	authed := []gin.HandlerFunc{auth}
+
+	rules := router.Group("/rules")
+	{
+		rules.POST("", audited(rec, audit.ActionRuleCreate, authed, h.CreateRule)...)
+		rules.GET("", auth, h.ListRules)
+		rules.POST("/dry-run", auth, h.DryRun)
+		rules.PUT("/:id", audited(rec, audit.ActionRuleUpdate, authed, h.UpdateRule)...)
+		rules.DELETE("/:id", audited(rec, audit.ActionRuleDelete, authed, h.DeleteRule)...)
+	}
+}
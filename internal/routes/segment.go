@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSegmentRoutes registers the Segment-compatible tracking API. It is
+// mounted at the router root because Segment SDKs only let you change the
+// host, not the /v1 path prefix.
+func SetupSegmentRoutes(router gin.IRouter, h *handler.SegmentHandler, mw ...gin.HandlerFunc) {
+	segment := router.Group("/v1")
+	segment.Use(mw...)
+	{
+		segment.POST("/track", h.Track)
+		segment.POST("/identify", h.Identify)
+		segment.POST("/page", h.Page)
+		segment.POST("/screen", h.Screen)
+		segment.POST("/group", h.Group)
+		segment.POST("/alias", h.Alias)
+		segment.POST("/batch", h.Batch)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTrackRoutes registers the browser-facing ingestion routes, which are
+// authenticated by a public write key rather than a JWT.
+func SetupTrackRoutes(router gin.IRouter, h *handler.TrackHandler, mw ...gin.HandlerFunc) {
+	track := router.Group("/track")
+	track.Use(mw...)
+	{
+		track.POST("", h.Track)
+		track.GET("/pixel.gif", h.Pixel)
+	}
+}
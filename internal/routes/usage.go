@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupUsageRoutes(router gin.IRouter, h *handler.UsageHandler, auth gin.HandlerFunc) {
+	usage := router.Group("/usage")
+	usage.Use(auth)
+	{
+		usage.GET("", h.GetUsage)
+	}
+}
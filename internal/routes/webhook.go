@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupWebhookRoutes(router gin.IRouter, h *handler.WebhookHandler, auth gin.HandlerFunc, rec middleware.AuditRecorder) {
+	authed := []gin.HandlerFunc{auth}
+
+	webhooks := router.Group("/webhooks")
+	{
+		webhooks.POST("", audited(rec, audit.ActionWebhookCreate, authed, h.CreateWebhook)...)
+		webhooks.GET("", auth, h.ListWebhooks)
+		webhooks.DELETE("/:id", audited(rec, audit.ActionWebhookDelete, authed, h.DeleteWebhook)...)
+		webhooks.GET("/:id/deliveries", auth, h.ListDeliveries)
+		webhooks.POST("/:id/deliveries/:delivery_id/replay", audited(rec, audit.ActionWebhookReplay, authed, h.ReplayDelivery)...)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	"github.com/Vighnesh-V-H/sync/internal/handler"
+	"github.com/Vighnesh-V-H/sync/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupWriteKeyRoutes(router gin.IRouter, h *handler.WriteKeyHandler, auth gin.HandlerFunc, rec middleware.AuditRecorder) {
+	authed := []gin.HandlerFunc{auth}
+
+	keys := router.Group("/write-keys")
+	{
+		keys.POST("", audited(rec, audit.ActionWriteKeyCreate, authed, h.CreateWriteKey)...)
+		keys.GET("", auth, h.ListWriteKeys)
+		keys.DELETE("/:id", audited(rec, audit.ActionWriteKeyRevoke, authed, h.RevokeWriteKey)...)
+	}
+}
@@ -0,0 +1,53 @@
+package rules
+
+import "strings"
+
+// Paths address nested payload fields with dots: "properties.email".
+
+func getPath(payload map[string]any, path string) (any, bool) {
+	keys := strings.Split(path, ".")
+	current := payload
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	value, ok := current[keys[len(keys)-1]]
+	return value, ok
+}
+
+// setPath creates intermediate objects as needed, replacing any non-object
+// value in the way.
+func setPath(payload map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	current := payload
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[key] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+func deletePath(payload map[string]any, path string) bool {
+	keys := strings.Split(path, ".")
+	current := payload
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			return false
+		}
+		current = next
+	}
+	last := keys[len(keys)-1]
+	if _, ok := current[last]; !ok {
+		return false
+	}
+	delete(current, last)
+	return true
+}
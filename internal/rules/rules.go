@@ -0,0 +1,207 @@
+// Package rules compiles and applies tenant-defined transformation rules.
+// Conditions and values are expr-lang expressions: they can read the event
+// but have no access to I/O, so tenants cannot reach outside the sandbox.
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// maxExpressionNodes keeps a single rule from being arbitrarily expensive.
+const maxExpressionNodes = 500
+
+// Compiled is a rule with its expressions parsed and type-checked.
+type Compiled struct {
+	Rule      *models.Rule
+	condition *vm.Program
+	value     *vm.Program
+}
+
+// Result describes what a rule set did to an event.
+type Result struct {
+	Dropped   bool        `json:"dropped"`
+	DroppedBy string      `json:"dropped_by,omitempty"`
+	Applied   []string    `json:"applied"`
+	Errors    []RuleError `json:"errors,omitempty"`
+}
+
+// RuleError is a rule that failed at runtime; the remaining rules still run.
+type RuleError struct {
+	RuleID string `json:"rule_id"`
+	Error  string `json:"error"`
+}
+
+var hashFunction = expr.Function("sha256", func(params ...any) (any, error) {
+	return hashValue(params[0]), nil
+}, new(func(any) string))
+
+// Compile validates rule and compiles its expressions. Errors wrap
+// ErrInvalidRule.
+func Compile(rule *models.Rule) (*Compiled, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", internalErrors.ErrInvalidRule, fmt.Sprintf(format, args...))
+	}
+
+	switch rule.Action {
+	case models.RuleActionDrop:
+	case models.RuleActionRename:
+		if rule.Field == "" || rule.Target == "" {
+			return nil, invalid("rename requires field and target")
+		}
+	case models.RuleActionRedact, models.RuleActionHash:
+		if rule.Field == "" {
+			return nil, invalid("%s requires field", rule.Action)
+		}
+	case models.RuleActionSet:
+		if rule.Field == "" || rule.Value == "" {
+			return nil, invalid("set requires field and value")
+		}
+	case models.RuleActionRoute:
+		if rule.Target == "" {
+			return nil, invalid("route requires target")
+		}
+	default:
+		return nil, invalid("unknown action %q", rule.Action)
+	}
+
+	compiled := &Compiled{Rule: rule}
+	if rule.Condition != "" {
+		program, err := expr.Compile(rule.Condition, expr.Env(sampleEnv()), expr.AsBool(), expr.MaxNodes(maxExpressionNodes), hashFunction)
+		if err != nil {
+			return nil, invalid("condition: %v", err)
+		}
+		compiled.condition = program
+	}
+	if rule.Action == models.RuleActionSet {
+		program, err := expr.Compile(rule.Value, expr.Env(sampleEnv()), expr.MaxNodes(maxExpressionNodes), hashFunction)
+		if err != nil {
+			return nil, invalid("value: %v", err)
+		}
+		compiled.value = program
+	}
+
+	return compiled, nil
+}
+
+// CompileAll compiles the enabled rules, preserving their order.
+func CompileAll(rules []*models.Rule) ([]*Compiled, error) {
+	compiled := make([]*Compiled, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		c, err := Compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// Apply runs compiled against ev in order, modifying it in place. It stops at
+// the first rule that drops the event.
+func Apply(ev *models.Event, compiled []*Compiled) *Result {
+	res := &Result{Applied: []string{}}
+
+	for _, c := range compiled {
+		if err := c.apply(ev, res); err != nil {
+			res.Errors = append(res.Errors, RuleError{RuleID: c.Rule.ID, Error: err.Error()})
+			continue
+		}
+		if res.Dropped {
+			break
+		}
+	}
+
+	return res
+}
+
+func (c *Compiled) apply(ev *models.Event, res *Result) error {
+	if c.condition != nil {
+		matched, err := expr.Run(c.condition, env(ev))
+		if err != nil {
+			return err
+		}
+		if matched != true {
+			return nil
+		}
+	}
+
+	rule := c.Rule
+	switch rule.Action {
+	case models.RuleActionDrop:
+		res.Dropped = true
+		res.DroppedBy = rule.ID
+	case models.RuleActionRename:
+		value, ok := getPath(ev.Payload, rule.Field)
+		if !ok {
+			return nil
+		}
+		deletePath(ev.Payload, rule.Field)
+		setPath(ev.Payload, rule.Target, value)
+	case models.RuleActionRedact:
+		if !deletePath(ev.Payload, rule.Field) {
+			return nil
+		}
+	case models.RuleActionHash:
+		value, ok := getPath(ev.Payload, rule.Field)
+		if !ok || value == nil {
+			return nil
+		}
+		setPath(ev.Payload, rule.Field, hashValue(value))
+	case models.RuleActionSet:
+		value, err := expr.Run(c.value, env(ev))
+		if err != nil {
+			return err
+		}
+		setPath(ev.Payload, rule.Field, value)
+	case models.RuleActionRoute:
+		for _, route := range ev.Routes {
+			if route == rule.Target {
+				return nil
+			}
+		}
+		ev.Routes = append(ev.Routes, rule.Target)
+	}
+
+	res.Applied = append(res.Applied, rule.ID)
+	return nil
+}
+
+// env is what expressions see: the payload plus the fields the processor has
+// resolved, e.g. `payload.event == "page_view" && context.ip startsWith "10."`.
+func env(ev *models.Event) map[string]any {
+	return map[string]any{
+		"payload":     ev.Payload,
+		"type":        ev.Payload["type"],
+		"distinct_id": ev.DistinctID,
+		"person_id":   ev.PersonID,
+		"timestamp":   ev.Timestamp,
+		"context": map[string]any{
+			"ip":         ev.Context.IP,
+			"user_agent": ev.Context.UserAgent,
+		},
+	}
+}
+
+func sampleEnv() map[string]any {
+	return env(&models.Event{Payload: map[string]any{}, Timestamp: time.Time{}})
+}
+
+func hashValue(value any) string {
+	s, ok := value.(string)
+	if !ok {
+		s = fmt.Sprint(value)
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,219 @@
+package rules
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+)
+
+func compileRules(t *testing.T, rules ...*models.Rule) []*Compiled {
+	t.Helper()
+	compiled := make([]*Compiled, 0, len(rules))
+	for _, rule := range rules {
+		c, err := Compile(rule)
+		if err != nil {
+			t.Fatalf("Compile(%s): %v", rule.ID, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+func testEvent() *models.Event {
+	return &models.Event{
+		ID:         "ev-1",
+		DistinctID: "user-1",
+		Payload: map[string]any{
+			"event": "signup",
+			"properties": map[string]any{
+				"email": "ada@example.com",
+				"plan":  "pro",
+			},
+		},
+		Context: models.EventContext{IP: "10.0.0.7"},
+	}
+}
+
+func TestApplyActions(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		rule       *models.Rule
+		wantDrop   bool
+		wantRoutes []string
+		check      func(t *testing.T, payload map[string]any)
+	}{
+		{
+			name:     "drop",
+			rule:     &models.Rule{ID: "r", Action: models.RuleActionDrop, Condition: `payload.event == "signup"`},
+			wantDrop: true,
+		},
+		{
+			name: "rename",
+			rule: &models.Rule{ID: "r", Action: models.RuleActionRename, Field: "properties.plan", Target: "plan"},
+			check: func(t *testing.T, payload map[string]any) {
+				props := payload["properties"].(map[string]any)
+				if _, ok := props["plan"]; ok || payload["plan"] != "pro" {
+					t.Fatalf("after rename payload = %v, want plan moved to the top level", payload)
+				}
+			},
+		},
+		{
+			name: "redact",
+			rule: &models.Rule{ID: "r", Action: models.RuleActionRedact, Field: "properties.email"},
+			check: func(t *testing.T, payload map[string]any) {
+				if _, ok := payload["properties"].(map[string]any)["email"]; ok {
+					t.Fatalf("after redact payload = %v, want no email", payload)
+				}
+			},
+		},
+		{
+			name: "hash",
+			rule: &models.Rule{ID: "r", Action: models.RuleActionHash, Field: "properties.email"},
+			check: func(t *testing.T, payload map[string]any) {
+				got := payload["properties"].(map[string]any)["email"]
+				if got != hashValue("ada@example.com") {
+					t.Fatalf("after hash email = %v, want its sha256", got)
+				}
+			},
+		},
+		{
+			name: "set",
+			rule: &models.Rule{ID: "r", Action: models.RuleActionSet, Field: "context.internal", Value: `context.ip startsWith "10."`},
+			check: func(t *testing.T, payload map[string]any) {
+				if got := payload["context"].(map[string]any)["internal"]; got != true {
+					t.Fatalf("after set context.internal = %v, want true", got)
+				}
+			},
+		},
+		{
+			name:       "route",
+			rule:       &models.Rule{ID: "r", Action: models.RuleActionRoute, Target: "warehouse"},
+			wantRoutes: []string{"warehouse"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ev := testEvent()
+			res := Apply(ev, compileRules(t, tc.rule))
+
+			if len(res.Errors) != 0 || !reflect.DeepEqual(res.Applied, []string{"r"}) {
+				t.Fatalf("result = %+v, want rule r applied without errors", res)
+			}
+			if res.Dropped != tc.wantDrop {
+				t.Fatalf("dropped = %v, want %v", res.Dropped, tc.wantDrop)
+			}
+			if !reflect.DeepEqual(ev.Routes, tc.wantRoutes) {
+				t.Fatalf("routes = %v, want %v", ev.Routes, tc.wantRoutes)
+			}
+			if tc.check != nil {
+				tc.check(t, ev.Payload)
+			}
+		})
+	}
+}
+
+func TestApplySkipsUnmatchedAndStopsAtDrop(t *testing.T) {
+	ev := testEvent()
+	res := Apply(ev, compileRules(t,
+		&models.Rule{ID: "skipped", Action: models.RuleActionRoute, Target: "billing", Condition: `payload.event == "purchase"`},
+		&models.Rule{ID: "drop", Action: models.RuleActionDrop},
+		&models.Rule{ID: "after", Action: models.RuleActionRoute, Target: "warehouse"},
+	))
+
+	if !res.Dropped || res.DroppedBy != "drop" || !reflect.DeepEqual(res.Applied, []string{"drop"}) {
+		t.Fatalf("result = %+v, want only drop applied", res)
+	}
+	if len(ev.Routes) != 0 {
+		t.Fatalf("routes = %v, want none after the drop", ev.Routes)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	payload := map[string]any{"a": map[string]any{"b": map[string]any{"c": 1}}, "scalar": "x"}
+
+	if v, ok := getPath(payload, "a.b.c"); !ok || v != 1 {
+		t.Fatalf("getPath(a.b.c) = %v, %v; want 1", v, ok)
+	}
+	if _, ok := getPath(payload, "scalar.below"); ok {
+		t.Fatal("getPath through a non-object found a value")
+	}
+	if _, ok := getPath(payload, "a.missing.c"); ok {
+		t.Fatal("getPath through a missing key found a value")
+	}
+
+	setPath(payload, "scalar.below.deep", 2)
+	if v, ok := getPath(payload, "scalar.below.deep"); !ok || v != 2 {
+		t.Fatalf("after setPath over a scalar, getPath = %v, %v; want 2", v, ok)
+	}
+	setPath(payload, "new.path", 3)
+	if v, _ := getPath(payload, "new.path"); v != 3 {
+		t.Fatalf("setPath did not create intermediate objects: %v", payload)
+	}
+
+	if !deletePath(payload, "a.b.c") {
+		t.Fatal("deletePath(a.b.c) = false, want true")
+	}
+	if deletePath(payload, "a.b.c") || deletePath(payload, "a.missing.c") {
+		t.Fatal("deletePath of a missing path = true, want false")
+	}
+	if _, ok := payload["a"].(map[string]any)["b"]; !ok {
+		t.Fatal("deletePath removed the parent object")
+	}
+}
+
+func TestCompileRejectsOversizedExpression(t *testing.T) {
+	condition := "true" + strings.Repeat(" && true", maxExpressionNodes)
+	_, err := Compile(&models.Rule{ID: "r", Action: models.RuleActionDrop, Condition: condition})
+	if !errors.Is(err, internalErrors.ErrInvalidRule) {
+		t.Fatalf("Compile with %d nodes: %v, want ErrInvalidRule", maxExpressionNodes*2, err)
+	}
+
+	small := "true" + strings.Repeat(" && true", 10)
+	if _, err := Compile(&models.Rule{ID: "r", Action: models.RuleActionDrop, Condition: small}); err != nil {
+		t.Fatalf("Compile with a small condition: %v", err)
+	}
+}
+
+func TestCompileRejectsNonBoolCondition(t *testing.T) {
+	_, err := Compile(&models.Rule{ID: "r", Action: models.RuleActionDrop, Condition: `"signup"`})
+	if !errors.Is(err, internalErrors.ErrInvalidRule) {
+		t.Fatalf("Compile with a string condition: %v, want ErrInvalidRule", err)
+	}
+}
+
+func TestApplyNonBoolConditionAtRuntime(t *testing.T) {
+	// Payload fields are untyped, so this only fails once it runs.
+	ev := testEvent()
+	res := Apply(ev, compileRules(t,
+		&models.Rule{ID: "bad", Action: models.RuleActionDrop, Condition: `payload.event`},
+		&models.Rule{ID: "next", Action: models.RuleActionRoute, Target: "warehouse"},
+	))
+
+	if res.Dropped {
+		t.Fatal("a non-bool condition dropped the event")
+	}
+	if len(res.Errors) != 1 || res.Errors[0].RuleID != "bad" {
+		t.Fatalf("errors = %+v, want one for rule bad", res.Errors)
+	}
+	if !reflect.DeepEqual(res.Applied, []string{"next"}) {
+		t.Fatalf("applied = %v, want the following rule to still run", res.Applied)
+	}
+}
+
+func TestCompileValidatesActionFields(t *testing.T) {
+	for _, rule := range []*models.Rule{
+		{Action: "explode"},
+		{Action: models.RuleActionRename, Field: "a"},
+		{Action: models.RuleActionRedact},
+		{Action: models.RuleActionHash},
+		{Action: models.RuleActionSet, Field: "a"},
+		{Action: models.RuleActionRoute},
+	} {
+		if _, err := Compile(rule); !errors.Is(err, internalErrors.ErrInvalidRule) {
+			t.Errorf("Compile(%+v): %v, want ErrInvalidRule", rule, err)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+)
+
+// UpdateAccountRequest changes only the fields that are set. Changing the
+// email takes the current password.
+type UpdateAccountRequest struct {
+	Name            *string `json:"name" validate:"omitempty,min=2"`
+	Email           *string `json:"email" validate:"omitempty,email"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// Mailer delivers the token confirming an email change to the new address.
+type Mailer interface {
+	SendEmailConfirmation(ctx context.Context, to string, token string) error
+}
+
+// Account returns the signed-in user.
+func (s *AuthService) Account(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to fetch user from repository")
+		return nil, err
+	}
+	if user == nil {
+		return nil, internalErrors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// UpdateAccount applies req and returns the user with a fresh token. A new
+// email is only held as pending: a confirmation token is sent to it, and the
+// email changes once ConfirmEmail is called with that token.
+func (s *AuthService) UpdateAccount(ctx context.Context, userID string, req UpdateAccountRequest) (*AuthResponse, error) {
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	pending := ""
+	if req.Email != nil {
+		if email := NormalizeEmail(*req.Email); email != user.Email {
+			if s.mailer == nil {
+				return nil, internalErrors.ErrEmailChangeUnavailable
+			}
+			if err := s.checkPassword(user, req.CurrentPassword); err != nil {
+				return nil, err
+			}
+			taken, err := s.repo.GetUserByEmail(ctx, email)
+			if err != nil {
+				return nil, err
+			}
+			if taken != nil {
+				return nil, internalErrors.ErrUserAlreadyExists
+			}
+			pending = email
+			user.PendingEmail = email
+		}
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to update user in repository")
+		return nil, err
+	}
+
+	if pending != "" {
+		if err := s.sendEmailConfirmation(ctx, userID, pending); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Api_Key, user.TokenVersion, s.jwtConfig)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to generate JWT token")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Bool("email_change_pending", pending != "").
+		Msg("Account updated")
+
+	return &AuthResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
+
+// sendEmailConfirmation mails email a token that proves, once passed to
+// ConfirmEmail, that the user owns it.
+func (s *AuthService) sendEmailConfirmation(ctx context.Context, userID string, email string) error {
+	confirmation, err := utils.GenerateEmailConfirmation(userID, email, s.jwtConfig)
+	if err == nil {
+		err = s.mailer.SendEmailConfirmation(ctx, email, confirmation)
+	}
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to send email confirmation")
+	}
+	return err
+}
+
+// ConfirmEmail marks the account verified with a token sent by Signup to its
+// email, or completes an email change with the token sent to the new
+// address. Only the latest change requested can be confirmed.
+func (s *AuthService) ConfirmEmail(ctx context.Context, req ConfirmEmailRequest) (*models.User, error) {
+	userID, email, err := utils.ParseEmailConfirmation(req.Token, s.jwtConfig.Secret)
+	if err != nil {
+		return nil, internalErrors.ErrInvalidEmailConfirmation
+	}
+
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetActor(ctx, user.ID, user.Api_Key)
+	switch email {
+	case user.PendingEmail:
+		user.Email = email
+		user.PendingEmail = ""
+	case user.Email:
+	default:
+		return nil, internalErrors.ErrInvalidEmailConfirmation
+	}
+	user.IsVerified = true
+	user.UpdatedAt = time.Now()
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to confirm email in repository")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("Email confirmed")
+	return user, nil
+}
+
+// ChangePassword replaces the password after checking the current one,
+// returning ErrInvalidCredentials if it does not match. Every token issued
+// before, the caller's included, is revoked.
+func (s *AuthService) ChangePassword(ctx context.Context, userID string, req ChangePasswordRequest) error {
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkPassword(user, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	if err := s.policy.Check(req.NewPassword, user.Email); err != nil {
+		s.logger.Warn().Err(err).
+			Str("user_id", userID).
+			Msg("New password rejected by policy")
+		return err
+	}
+
+	hashed, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to hash password")
+		return err
+	}
+
+	if err := s.repo.ChangePassword(ctx, userID, hashed, time.Now()); err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to update password in repository")
+		return err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("Password changed")
+	return nil
+}
+
+// DeleteAccount soft-deletes the user after checking their current password,
+// revokes their write keys and schedules their event data for erasure after
+// the configured delay.
+func (s *AuthService) DeleteAccount(ctx context.Context, userID string, req DeleteAccountRequest) error {
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPassword(user, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	eraseAfter := time.Now().Add(s.erasureDelay)
+	if err := s.repo.DeleteUser(ctx, userID, eraseAfter); err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to delete user in repository")
+		return err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Time("erase_after", eraseAfter).
+		Msg("Account deleted, data erasure scheduled")
+	return nil
+}
+
+// checkPassword returns ErrInvalidCredentials unless plain is the user's
+// password. Accounts created through OIDC have none and never match.
+func (s *AuthService) checkPassword(user *models.User, plain string) error {
+	match, _, err := s.hasher.Verify(user.Password, plain)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", user.ID).
+			Msg("Failed to verify password hash")
+		return err
+	}
+	if !match {
+		s.logger.Warn().
+			Str("user_id", user.ID).
+			Msg("Invalid current password")
+		return internalErrors.ErrInvalidCredentials
+	}
+	return nil
+}
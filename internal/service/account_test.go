@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+)
+
+func signupTestUser(t *testing.T, svc *AuthService) string {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := svc.Signup(ctx, SignupRequest{Email: "ada@example.com", Password: "correct-horse", Name: "Ada"}); err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	res, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	return res.User.ID
+}
+
+// captureMailer records the confirmation tokens it is asked to send.
+type captureMailer struct {
+	sent map[string]string
+}
+
+func (m *captureMailer) SendEmailConfirmation(_ context.Context, to string, token string) error {
+	m.sent[to] = token
+	return nil
+}
+
+func TestUpdateAccountEmailPendingUntilConfirmed(t *testing.T) {
+	svc, _ := newTestAuthService()
+	mailer := &captureMailer{sent: map[string]string{}}
+	svc.mailer = mailer
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+
+	name, email := "Ada L.", " Ada@Lovelace.dev "
+	res, err := svc.UpdateAccount(ctx, userID, UpdateAccountRequest{Name: &name, Email: &email, CurrentPassword: "correct-horse"})
+	if err != nil {
+		t.Fatalf("UpdateAccount: %v", err)
+	}
+	if res.User.Name != name || res.User.Email != "ada@example.com" || res.User.PendingEmail != "ada@lovelace.dev" {
+		t.Fatalf("updated user %+v, want new name, old email and the new one pending", res.User)
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@lovelace.dev", Password: "correct-horse"}); err == nil {
+		t.Fatal("Signin with the unconfirmed email succeeded")
+	}
+
+	token := mailer.sent["ada@lovelace.dev"]
+	if _, err := svc.ConfirmEmail(ctx, ConfirmEmailRequest{Token: token + "x"}); !errors.Is(err, internalErrors.ErrInvalidEmailConfirmation) {
+		t.Fatalf("ConfirmEmail with bad token = %v, want ErrInvalidEmailConfirmation", err)
+	}
+	user, err := svc.ConfirmEmail(ctx, ConfirmEmailRequest{Token: token})
+	if err != nil {
+		t.Fatalf("ConfirmEmail: %v", err)
+	}
+	if user.Email != "ada@lovelace.dev" || user.PendingEmail != "" || !user.IsVerified {
+		t.Fatalf("confirmed user %+v, want new verified email", user)
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@lovelace.dev", Password: "correct-horse"}); err != nil {
+		t.Fatalf("Signin with new email: %v", err)
+	}
+}
+
+func TestSignupEmailUnverifiedUntilConfirmed(t *testing.T) {
+	svc, _ := newTestAuthService()
+	mailer := &captureMailer{sent: map[string]string{}}
+	svc.mailer = mailer
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+
+	user, err := svc.Account(ctx, userID)
+	if err != nil || user.IsVerified {
+		t.Fatalf("Account after signup = %+v, %v; want unverified", user, err)
+	}
+	user, err = svc.ConfirmEmail(ctx, ConfirmEmailRequest{Token: mailer.sent["ada@example.com"]})
+	if err != nil {
+		t.Fatalf("ConfirmEmail: %v", err)
+	}
+	if user.Email != "ada@example.com" || !user.IsVerified {
+		t.Fatalf("confirmed user %+v, want verified", user)
+	}
+}
+
+func TestConfirmEmailOnlyAcceptsLatestChange(t *testing.T) {
+	svc, _ := newTestAuthService()
+	mailer := &captureMailer{sent: map[string]string{}}
+	svc.mailer = mailer
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		if _, err := svc.UpdateAccount(ctx, userID, UpdateAccountRequest{Email: &email, CurrentPassword: "correct-horse"}); err != nil {
+			t.Fatalf("UpdateAccount: %v", err)
+		}
+	}
+	if _, err := svc.ConfirmEmail(ctx, ConfirmEmailRequest{Token: mailer.sent["first@example.com"]}); !errors.Is(err, internalErrors.ErrInvalidEmailConfirmation) {
+		t.Fatalf("ConfirmEmail for superseded change = %v, want ErrInvalidEmailConfirmation", err)
+	}
+}
+
+func TestUpdateAccountEmailRequiresPassword(t *testing.T) {
+	svc, _ := newTestAuthService()
+	svc.mailer = &captureMailer{sent: map[string]string{}}
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+
+	email := "mallory@example.com"
+	if _, err := svc.UpdateAccount(ctx, userID, UpdateAccountRequest{Email: &email, CurrentPassword: "wrong-password"}); !errors.Is(err, internalErrors.ErrInvalidCredentials) {
+		t.Fatalf("UpdateAccount with wrong password = %v, want ErrInvalidCredentials", err)
+	}
+
+	svc.mailer = nil
+	if _, err := svc.UpdateAccount(ctx, userID, UpdateAccountRequest{Email: &email, CurrentPassword: "correct-horse"}); !errors.Is(err, internalErrors.ErrEmailChangeUnavailable) {
+		t.Fatalf("UpdateAccount without mailer = %v, want ErrEmailChangeUnavailable", err)
+	}
+}
+
+func TestUpdateAccountRejectsTakenEmail(t *testing.T) {
+	svc, _ := newTestAuthService()
+	svc.mailer = &captureMailer{sent: map[string]string{}}
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	if _, err := svc.Signup(ctx, SignupRequest{Email: "grace@example.com", Password: "correct-horse", Name: "Grace"}); err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+
+	email := "Grace@example.com"
+	if _, err := svc.UpdateAccount(ctx, userID, UpdateAccountRequest{Email: &email, CurrentPassword: "correct-horse"}); !errors.Is(err, internalErrors.ErrUserAlreadyExists) {
+		t.Fatalf("UpdateAccount to taken email = %v, want ErrUserAlreadyExists", err)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+
+	err := svc.ChangePassword(ctx, userID, ChangePasswordRequest{CurrentPassword: "wrong-password", NewPassword: "new-password"})
+	if !errors.Is(err, internalErrors.ErrInvalidCredentials) {
+		t.Fatalf("ChangePassword with wrong current password = %v, want ErrInvalidCredentials", err)
+	}
+
+	err = svc.ChangePassword(ctx, userID, ChangePasswordRequest{CurrentPassword: "correct-horse", NewPassword: "ada@example.com"})
+	if !errors.Is(err, internalErrors.ErrWeakPassword) {
+		t.Fatalf("ChangePassword to email = %v, want ErrWeakPassword", err)
+	}
+
+	if err := svc.ChangePassword(ctx, userID, ChangePasswordRequest{CurrentPassword: "correct-horse", NewPassword: "new-password"}); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if version, _, _ := store.TokenVersion(ctx, userID); version != 1 {
+		t.Fatalf("token version after ChangePassword = %d, want 1 so older tokens are revoked", version)
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"}); err == nil {
+		t.Fatal("Signin with old password succeeded")
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "new-password"}); err != nil {
+		t.Fatalf("Signin with new password: %v", err)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+
+	if err := svc.DeleteAccount(ctx, userID, DeleteAccountRequest{CurrentPassword: "wrong-password"}); !errors.Is(err, internalErrors.ErrInvalidCredentials) {
+		t.Fatalf("DeleteAccount with wrong password = %v, want ErrInvalidCredentials", err)
+	}
+
+	before := time.Now()
+	if err := svc.DeleteAccount(ctx, userID, DeleteAccountRequest{CurrentPassword: "correct-horse"}); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+
+	if _, err := svc.Account(ctx, userID); !errors.Is(err, internalErrors.ErrUserNotFound) {
+		t.Fatalf("Account after delete = %v, want ErrUserNotFound", err)
+	}
+	at, ok := store.ErasureScheduled(userID)
+	if !ok || at.Before(before.Add(72*time.Hour)) {
+		t.Fatalf("erasure scheduled at %v (%v), want 72h after deletion", at, ok)
+	}
+
+	// The email is free for a new account.
+	if _, err := svc.Signup(ctx, SignupRequest{Email: "ada@example.com", Password: "correct-horse", Name: "Ada"}); err != nil {
+		t.Fatalf("Signup after delete: %v", err)
+	}
+}
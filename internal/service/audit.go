@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"strings"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+	// auditPurgeBatch bounds how many entries one purge statement deletes.
+	auditPurgeBatch = 10000
+)
+
+// auditMetrics counts audit log writes, so entries lost while Postgres is
+// failing show up at /debug/vars and not only in the logs.
+var auditMetrics = expvar.NewMap("audit_log")
+
+type AuditService struct {
+	repo   *repositories.AuditRepository
+	logger zerolog.Logger
+}
+
+func NewAuditService(repo *repositories.AuditRepository, logger zerolog.Logger) *AuditService {
+	return &AuditService{
+		repo:   repo,
+		logger: logger.With().Str("service", "audit").Logger(),
+	}
+}
+
+// AuditQuery filters the audit log. Action takes a comma-separated list;
+// From and To are RFC 3339 times bounding occurred_at.
+type AuditQuery struct {
+	Action  string    `form:"action"`
+	Actor   string    `form:"actor"`
+	Outcome string    `form:"outcome" validate:"omitempty,oneof=success failure"`
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Before and Limit page through List; Export ignores them.
+	Before int64 `form:"before" validate:"omitempty,min=1"`
+	Limit  int   `form:"limit" validate:"omitempty,min=1"`
+}
+
+func (q AuditQuery) filter() repositories.AuditFilter {
+	f := repositories.AuditFilter{
+		ActorID: q.Actor,
+		Outcome: q.Outcome,
+		From:    q.From,
+		To:      q.To,
+	}
+	for _, action := range strings.Split(q.Action, ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			f.Actions = append(f.Actions, action)
+		}
+	}
+	return f
+}
+
+// Record appends entry to the audit log. A failure is logged rather than
+// returned: the action being audited has already happened.
+func (s *AuditService) Record(ctx context.Context, entry *models.AuditEntry) {
+	if err := s.repo.AppendAuditEntry(ctx, entry); err != nil {
+		auditMetrics.Add("failed", 1)
+		s.logger.Error().Err(err).
+			Str("action", entry.Action).
+			Str("actor_id", entry.ActorID).
+			Str("outcome", entry.Outcome).
+			Str("request_id", entry.RequestID).
+			Msg("Failed to record audit log entry")
+		return
+	}
+	auditMetrics.Add("recorded", 1)
+}
+
+// RunRetention deletes entries older than retention every interval until
+// ctx is cancelled.
+func (s *AuditService) RunRetention(ctx context.Context, retention time.Duration, interval time.Duration) error {
+	s.logger.Info().Dur("retention", retention).Msg("Audit log retention started")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.purge(ctx, time.Now().Add(-retention))
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("Audit log retention stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge deletes the entries that occurred before cutoff, a batch at a time.
+func (s *AuditService) purge(ctx context.Context, cutoff time.Time) {
+	var total int64
+	for {
+		purged, err := s.repo.PurgeAuditEntries(ctx, cutoff, auditPurgeBatch)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error().Err(err).Msg("Failed to purge audit log")
+			}
+			return
+		}
+		total += purged
+		auditMetrics.Add("purged", purged)
+		if purged < auditPurgeBatch {
+			break
+		}
+	}
+	if total > 0 {
+		s.logger.Info().
+			Int64("purged", total).
+			Time("cutoff", cutoff).
+			Msg("Purged audit log entries past retention")
+	}
+}
+
+// AuditPage is one page of the audit log. NextBefore, when set, is the
+// Before that fetches the next page.
+type AuditPage struct {
+	Entries    []*models.AuditEntry `json:"entries"`
+	NextBefore int64                `json:"next_before,omitempty"`
+}
+
+// List returns a page of apiKey's audit log, newest first.
+func (s *AuditService) List(ctx context.Context, apiKey string, q AuditQuery) (*AuditPage, error) {
+	f := q.filter()
+	f.Before = q.Before
+	f.Limit = q.Limit
+	if f.Limit <= 0 || f.Limit > maxAuditLimit {
+		f.Limit = defaultAuditLimit
+	}
+
+	entries, err := s.repo.ListAuditEntries(ctx, apiKey, f)
+	if err != nil {
+		return nil, err
+	}
+	page := &AuditPage{Entries: entries}
+	if len(entries) == f.Limit {
+		page.NextBefore = entries[len(entries)-1].ID
+	}
+	return page, nil
+}
+
+// Export streams every entry of apiKey's audit log matching q to fn,
+// newest first.
+func (s *AuditService) Export(ctx context.Context, apiKey string, q AuditQuery, fn func(*models.AuditEntry) error) error {
+	return s.repo.EachAuditEntry(ctx, apiKey, q.filter(), fn)
+}
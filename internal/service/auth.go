@@ -3,31 +3,56 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/Vighnesh-V-H/sync/internal/audit"
 	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
 	"github.com/Vighnesh-V-H/sync/internal/models"
-	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/password"
 	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/google/uuid"
+	gonanoid "github.com/matoous/go-nanoid/v2"
 	"github.com/rs/zerolog"
 )
 
+// apiKeyAttempts bounds how many api keys Signup draws before giving up.
+const apiKeyAttempts = 3
+
 type AuthService struct {
-	repo      *repositories.AuthRepository
-	jwtConfig utils.JWTConfig
-	logger    zerolog.Logger
+	repo         UserStore
+	jwtConfig    utils.JWTConfig
+	policy       *password.Policy
+	hasher       *password.Hasher
+	erasureDelay time.Duration
+	mailer       Mailer
+	logger       zerolog.Logger
 }
 
-func NewAuthService(repo *repositories.AuthRepository, jwtCfg utils.JWTConfig, logger zerolog.Logger) *AuthService {
+// NewAuthService checks new passwords against policy and hashes them with
+// hasher. It erases a deleted account's data erasureDelay after deletion and
+// confirms email changes through mailer; without one, emails cannot change.
+func NewAuthService(repo UserStore, jwtCfg utils.JWTConfig, policy *password.Policy, hasher *password.Hasher, erasureDelay time.Duration, mailer Mailer, logger zerolog.Logger) *AuthService {
 	return &AuthService{
-		repo:      repo,
-		jwtConfig: jwtCfg,
-		logger:    logger.With().Str("service", "auth").Logger(),
+		repo:         repo,
+		jwtConfig:    jwtCfg,
+		policy:       policy,
+		hasher:       hasher,
+		erasureDelay: erasureDelay,
+		mailer:       mailer,
+		logger:       logger.With().Str("service", "auth").Logger(),
 	}
 }
 
+// NormalizeEmail is the form emails are stored and compared in: trimmed and
+// lower-cased, matching the users_email_normalized_key index.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 type SignupRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required"`
 	Name     string `json:"name" validate:"required,min=2"`
 }
 
@@ -41,9 +66,13 @@ type SigninRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// AuthResponse carries either a token or, when the user has two-factor
+// authentication enabled, an MFA challenge to complete with SigninMFA.
 type AuthResponse struct {
-	User  *models.User `json:"user"`
-	Token string       `json:"token"`
+	User        *models.User `json:"user,omitempty"`
+	Token       string       `json:"token,omitempty"`
+	MFARequired bool         `json:"mfa_required,omitempty"`
+	MFAToken    string       `json:"mfa_token,omitempty"`
 }
 
 func (s *AuthService) Signup(ctx context.Context, req SignupRequest) (*SignupResponse, error) {
@@ -52,36 +81,95 @@ func (s *AuthService) Signup(ctx context.Context, req SignupRequest) (*SignupRes
 		Str("name", req.Name).
 		Msg("Starting user signup process")
 
-	user := &models.User{
-		Email:    req.Email,
-		Password: req.Password,
-		Name:     req.Name,
+	if err := s.policy.Check(req.Password, req.Email); err != nil {
+		s.logger.Warn().Err(err).
+			Str("email", req.Email).
+			Msg("Signup password rejected by policy")
+		return nil, err
 	}
 
-	if err := s.repo.CreateUser(ctx, user); err != nil {
+	hashed, err := s.hasher.Hash(req.Password)
+	if err != nil {
 		s.logger.Error().Err(err).
 			Str("email", req.Email).
-			Msg("Failed to create user in repository")
+			Msg("Failed to hash password")
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:         uuid.New().String(),
+		Email:      NormalizeEmail(req.Email),
+		Password:   hashed,
+		Name:       req.Name,
+		IsVerified: false,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.createUser(ctx, user); err != nil {
 		return nil, err
 	}
+	audit.SetActor(ctx, user.ID, user.Api_Key)
 
 	s.logger.Info().
 		Str("email", req.Email).
 		Str("user_id", user.ID).
 		Msg("User created successfully")
 
+	// The account is usable straight away, but its email is only trusted,
+	// for instance to link an OIDC login to it, once confirmed.
+	if s.mailer != nil {
+		if err := s.sendEmailConfirmation(ctx, user.ID, user.Email); err != nil {
+			s.logger.Warn().Err(err).
+				Str("user_id", user.ID).
+				Msg("Signed up without sending email confirmation")
+		}
+	}
+
 	return &SignupResponse{
 		Success: true,
 		Message: "Signup successful. Please verify your email.",
 	}, nil
 }
 
+// createUser assigns user an api key and stores it. A fresh api key
+// colliding with an existing one is vanishingly unlikely, but the unique
+// index would reject it, so draw again rather than fail.
+func (s *AuthService) createUser(ctx context.Context, user *models.User) error {
+	for attempt := 1; ; attempt++ {
+		apiKey, err := gonanoid.New()
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("email", user.Email).
+				Msg("Failed to generate api key")
+			return err
+		}
+		user.Api_Key = "sync_" + apiKey
+
+		err = s.repo.CreateUser(ctx, user)
+		if errors.Is(err, internalErrors.ErrAPIKeyConflict) && attempt < apiKeyAttempts {
+			s.logger.Warn().
+				Str("email", user.Email).
+				Int("attempt", attempt).
+				Msg("Generated api key already in use, retrying")
+			continue
+		}
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("email", user.Email).
+				Msg("Failed to create user in repository")
+		}
+		return err
+	}
+}
+
 func (s *AuthService) Signin(ctx context.Context, req SigninRequest) (*AuthResponse, error) {
 	s.logger.Debug().
 		Str("email", req.Email).
 		Msg("Starting user signin process")
 
-	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	user, err := s.repo.GetUserByEmail(ctx, NormalizeEmail(req.Email))
 	if err != nil {
 		s.logger.Error().Err(err).
 			Str("email", req.Email).
@@ -95,30 +183,90 @@ func (s *AuthService) Signin(ctx context.Context, req SigninRequest) (*AuthRespo
 			Msg("User not found during signin attempt")
 		return nil, internalErrors.ErrUserNotFound
 	}
+	// Failed attempts are recorded against the account too.
+	audit.SetActor(ctx, user.ID, user.Api_Key)
 
-	if err := utils.ComparePassword(user.Password, req.Password); err != nil {
+	match, rehash, err := s.hasher.Verify(user.Password, req.Password)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", user.ID).
+			Msg("Failed to verify password hash")
+		return nil, err
+	}
+	if !match {
 		s.logger.Warn().
 			Str("email", req.Email).
 			Str("user_id", user.ID).
 			Msg("Invalid password attempt")
-		return nil, errors.New("invalid credentials")
+		return nil, internalErrors.ErrInvalidCredentials
+	}
+	if rehash {
+		s.rehashPassword(ctx, user.ID, req.Password)
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Api_Key, s.jwtConfig)
+	res, err := s.session(ctx, user)
 	if err != nil {
-		s.logger.Error().Err(err).
-			Str("email", req.Email).
-			Str("user_id", user.ID).
-			Str("api_key", user.Api_Key).
-			Msg("Failed to generate JWT token")
 		return nil, err
 	}
 
 	s.logger.Info().
 		Str("email", req.Email).
 		Str("user_id", user.ID).
+		Bool("mfa_required", res.MFARequired).
 		Msg("User signin successful")
 
+	return res, nil
+}
+
+// rehashPassword replaces a hash made with an outdated algorithm or cost
+// while the plaintext is at hand. Failing to do so does not fail the signin;
+// it is retried next time.
+func (s *AuthService) rehashPassword(ctx context.Context, userID string, plain string) {
+	hashed, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.repo.UpdatePassword(ctx, userID, hashed, time.Now())
+	}
+	if err != nil {
+		s.logger.Warn().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to upgrade password hash")
+		return
+	}
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("Password hash upgraded")
+}
+
+// session completes a signin for user: a JWT, or an MFA challenge if the
+// user has two-factor authentication enabled. A challenge supersedes any
+// issued before, and comes without the user, whose api key is as good as a
+// token.
+func (s *AuthService) session(ctx context.Context, user *models.User) (*AuthResponse, error) {
+	if user.MFAEnabled {
+		challenge, challengeID, err := utils.GenerateMFAChallenge(user.ID, s.jwtConfig)
+		if err == nil {
+			err = s.repo.SetMFAChallenge(ctx, user.ID, challengeID)
+		}
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("user_id", user.ID).
+				Msg("Failed to issue MFA challenge")
+			return nil, err
+		}
+		return &AuthResponse{
+			MFARequired: true,
+			MFAToken:    challenge,
+		}, nil
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Api_Key, user.TokenVersion, s.jwtConfig)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", user.ID).
+			Str("api_key", user.Api_Key).
+			Msg("Failed to generate JWT token")
+		return nil, err
+	}
 	return &AuthResponse{
 		User:  user,
 		Token: token,
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/password"
+	"github.com/Vighnesh-V-H/sync/internal/repositories/repotest"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testHasher keeps argon2id cheap in tests.
+var testHasher = password.NewHasher(password.Params{Memory: 64, Iterations: 1, Parallelism: 1})
+
+func newTestAuthService() (*AuthService, *repotest.UserStore) {
+	store := repotest.NewUserStore()
+	return newAuthServiceWithStore(store), store
+}
+
+func newAuthServiceWithStore(store UserStore) *AuthService {
+	policy := &password.Policy{MinLength: 8, MaxLength: 128}
+	return NewAuthService(store, utils.JWTConfig{Secret: "test-secret", Expiry: time.Hour}, policy, testHasher, 72*time.Hour, nil, zerolog.Nop())
+}
+
+func TestSignupStoresHashedUser(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+
+	if _, err := svc.Signup(ctx, SignupRequest{Email: "ada@example.com", Password: "correct-horse", Name: "Ada"}); err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+
+	user, err := store.GetUserByEmail(ctx, "ada@example.com")
+	if err != nil || user == nil {
+		t.Fatalf("user not stored: %v", err)
+	}
+	if user.ID == "" || !strings.HasPrefix(user.Api_Key, "sync_") {
+		t.Fatalf("user not fully populated: %+v", user)
+	}
+	if user.Password == "correct-horse" {
+		t.Fatal("password stored in plain text")
+	}
+}
+
+func TestSignupEnforcesPasswordPolicy(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+
+	for _, pw := range []string{"short", "Ada@Example.com"} {
+		_, err := svc.Signup(ctx, SignupRequest{Email: "ada@example.com", Password: pw, Name: "Ada"})
+		if !errors.Is(err, internalErrors.ErrWeakPassword) {
+			t.Fatalf("Signup with password %q = %v, want ErrWeakPassword", pw, err)
+		}
+	}
+}
+
+func TestSigninUpgradesOutdatedHash(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct-horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	if err := store.CreateUser(ctx, &models.User{ID: "user-1", Email: "ada@example.com", Password: string(legacy), Api_Key: "sync_legacy"}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"}); err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	user, _ := store.GetUserByID(ctx, "user-1")
+	if !strings.HasPrefix(user.Password, "$argon2id$") {
+		t.Fatalf("stored hash %q, want it upgraded to argon2id", user.Password)
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"}); err != nil {
+		t.Fatalf("Signin after upgrade: %v", err)
+	}
+}
+
+func TestSignupRejectsDuplicateEmail(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	req := SignupRequest{Email: "ada@example.com", Password: "correct-horse", Name: "Ada"}
+
+	if _, err := svc.Signup(ctx, req); err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	if _, err := svc.Signup(ctx, req); !errors.Is(err, internalErrors.ErrUserAlreadyExists) {
+		t.Fatalf("second Signup = %v, want ErrUserAlreadyExists", err)
+	}
+}
+
+func TestSignin(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+
+	if _, err := svc.Signup(ctx, SignupRequest{Email: "ada@example.com", Password: "correct-horse", Name: "Ada"}); err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+
+	res, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	apiKey, err := utils.APIKeyFromJWT(res.Token, "test-secret")
+	if err != nil || apiKey != res.User.Api_Key {
+		t.Fatalf("token carries api key %q (%v), want %q", apiKey, err, res.User.Api_Key)
+	}
+
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "wrong-password"}); !errors.Is(err, internalErrors.ErrInvalidCredentials) {
+		t.Fatalf("Signin with wrong password = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "nobody@example.com", Password: "correct-horse"}); !errors.Is(err, internalErrors.ErrUserNotFound) {
+		t.Fatalf("Signin unknown user = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestSignupNormalizesEmail(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+
+	if _, err := svc.Signup(ctx, SignupRequest{Email: "  Ada@Example.COM ", Password: "correct-horse", Name: "Ada"}); err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	user, _ := store.GetUserByEmail(ctx, "ada@example.com")
+	if user == nil || user.Email != "ada@example.com" {
+		t.Fatalf("stored user %+v, want normalized email", user)
+	}
+
+	_, err := svc.Signup(ctx, SignupRequest{Email: "ADA@example.com", Password: "correct-horse", Name: "Ada"})
+	if !errors.Is(err, internalErrors.ErrUserAlreadyExists) {
+		t.Fatalf("Signup differing only in case = %v, want ErrUserAlreadyExists", err)
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ADA@EXAMPLE.COM", Password: "correct-horse"}); err != nil {
+		t.Fatalf("Signin with different case: %v", err)
+	}
+}
+
+// conflictingUserStore reports an api key collision for the first conflicts
+// inserts.
+type conflictingUserStore struct {
+	*repotest.UserStore
+	conflicts int
+	keys      []string
+}
+
+func (s *conflictingUserStore) CreateUser(ctx context.Context, user *models.User) error {
+	s.keys = append(s.keys, user.Api_Key)
+	if len(s.keys) <= s.conflicts {
+		return internalErrors.ErrAPIKeyConflict
+	}
+	return s.UserStore.CreateUser(ctx, user)
+}
+
+func TestSignupRetriesAPIKeyConflict(t *testing.T) {
+	store := &conflictingUserStore{UserStore: repotest.NewUserStore(), conflicts: 2}
+	svc := newAuthServiceWithStore(store)
+
+	if _, err := svc.Signup(context.Background(), SignupRequest{Email: "ada@example.com", Password: "correct-horse", Name: "Ada"}); err != nil {
+		t.Fatalf("Signup: %v", err)
+	}
+	if len(store.keys) != 3 || store.keys[0] == store.keys[1] || store.keys[1] == store.keys[2] {
+		t.Fatalf("tried keys %v, want 3 distinct attempts", store.keys)
+	}
+
+	store = &conflictingUserStore{UserStore: repotest.NewUserStore(), conflicts: apiKeyAttempts}
+	svc = newAuthServiceWithStore(store)
+	_, err := svc.Signup(context.Background(), SignupRequest{Email: "ada@example.com", Password: "correct-horse", Name: "Ada"})
+	if !errors.Is(err, internalErrors.ErrAPIKeyConflict) {
+		t.Fatalf("Signup after %d conflicts = %v, want ErrAPIKeyConflict", apiKeyAttempts, err)
+	}
+}
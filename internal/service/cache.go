@@ -0,0 +1,92 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// tenantCacheSize caps how many tenants' settings the processor keeps in
+// memory.
+const tenantCacheSize = 10000
+
+// tenantCache is a per-tenant LRU whose entries also expire after ttl, so
+// its memory stays bounded however many api keys send events.
+type tenantCache[V any] struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order holds *tenantCacheEntry values, most recently used first.
+	order *list.List
+}
+
+type tenantCacheEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+func newTenantCache[V any](ttl time.Duration, size int) *tenantCache[V] {
+	return &tenantCache[V]{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *tenantCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*tenantCacheEntry[V])
+	if !time.Now().Before(entry.expiresAt) {
+		c.remove(el)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *tenantCache[V]) set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &tenantCacheEntry[V]{key: key, value: value, expiresAt: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *tenantCache[V]) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+func (c *tenantCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *tenantCache[V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*tenantCacheEntry[V]).key)
+}
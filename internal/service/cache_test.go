@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTenantCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newTenantCache[int](time.Minute, 2)
+	c.set("a", 1)
+	c.set("b", 2)
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	c.set("c", 3)
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("b was not evicted")
+	}
+	if v, ok := c.get("a"); !ok || v != 1 {
+		t.Fatalf("a = %d, %v; want 1, true", v, ok)
+	}
+	if c.len() != 2 {
+		t.Fatalf("len = %d, want 2", c.len())
+	}
+}
+
+func TestTenantCacheExpiresEntries(t *testing.T) {
+	c := newTenantCache[int](time.Millisecond, 10)
+	c.set("a", 1)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.get("a"); ok {
+		t.Fatal("expired entry returned")
+	}
+	if c.len() != 0 {
+		t.Fatalf("len = %d, want expired entry removed", c.len())
+	}
+}
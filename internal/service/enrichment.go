@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/enrichment"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/rs/zerolog"
+)
+
+// enrichmentSettingsTTL bounds how long the processor keeps using settings
+// after a project changes them.
+const enrichmentSettingsTTL = time.Minute
+
+type EnrichmentService struct {
+	repo   *repositories.EnrichmentRepository
+	logger zerolog.Logger
+	cache  *tenantCache[*models.EnrichmentSettings]
+}
+
+func NewEnrichmentService(repo *repositories.EnrichmentRepository, logger zerolog.Logger) *EnrichmentService {
+	return &EnrichmentService{
+		repo:   repo,
+		logger: logger.With().Str("service", "enrichment").Logger(),
+		cache:  newTenantCache[*models.EnrichmentSettings](enrichmentSettingsTTL, tenantCacheSize),
+	}
+}
+
+type UpdateEnrichmentRequest struct {
+	Enrichers        []string       `json:"enrichers" validate:"dive,required"`
+	StaticProperties map[string]any `json:"static_properties"`
+}
+
+// GetSettings returns the project's settings, or the defaults (every
+// built-in enricher, no static properties) if it has none.
+func (s *EnrichmentService) GetSettings(ctx context.Context, apiKey string) (*models.EnrichmentSettings, error) {
+	settings, err := s.repo.GetSettings(ctx, apiKey)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to load enrichment settings")
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.EnrichmentSettings{
+			APIKey:           apiKey,
+			Enrichers:        append([]string(nil), enrichment.Builtin...),
+			StaticProperties: map[string]any{},
+		}
+	}
+	return settings, nil
+}
+
+func (s *EnrichmentService) UpdateSettings(ctx context.Context, apiKey string, req UpdateEnrichmentRequest) (*models.EnrichmentSettings, error) {
+	for _, name := range req.Enrichers {
+		if !containsString(enrichment.Builtin, name) {
+			return nil, internalErrors.ErrUnknownEnricher
+		}
+	}
+
+	settings := &models.EnrichmentSettings{
+		APIKey:           apiKey,
+		Enrichers:        req.Enrichers,
+		StaticProperties: req.StaticProperties,
+	}
+	if settings.Enrichers == nil {
+		settings.Enrichers = []string{}
+	}
+	if settings.StaticProperties == nil {
+		settings.StaticProperties = map[string]any{}
+	}
+
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	s.cache.delete(apiKey)
+
+	s.logger.Info().
+		Str("api_key", apiKey).
+		Strs("enrichers", settings.Enrichers).
+		Msg("Enrichment settings updated")
+
+	return settings, nil
+}
+
+// CachedSettings is GetSettings for the processor's hot path.
+func (s *EnrichmentService) CachedSettings(ctx context.Context, apiKey string) (*models.EnrichmentSettings, error) {
+	if settings, ok := s.cache.get(apiKey); ok {
+		return settings, nil
+	}
+
+	settings, err := s.GetSettings(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.set(apiKey, settings)
+
+	return settings, nil
+}
@@ -2,26 +2,33 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/Vighnesh-V-H/sync/internal/models"
 	"github.com/Vighnesh-V-H/sync/internal/repositories"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
 type EventService struct {
-	repo   *repositories.EventRepository
+	repo   EventStore
+	usage  *UsageService
 	logger zerolog.Logger
 }
 
-func NewEventService(repo *repositories.EventRepository, logger zerolog.Logger) *EventService {
+// NewEventService enforces monthly quotas through usage; pass nil to disable them.
+func NewEventService(repo EventStore, usage *UsageService, logger zerolog.Logger) *EventService {
 	return &EventService{
 		repo:   repo,
+		usage:  usage,
 		logger: logger.With().Str("service", "event").Logger(),
 	}
 }
 
 type AddEventRequest struct {
 	Payload map[string]any `json:"payload"`
+	// Context is set by the transport from the request itself, never from the body.
+	Context models.EventContext `json:"-"`
 }
 
 type AddEventResponse struct {
@@ -34,25 +41,46 @@ func (s *EventService) AddEvent(ctx context.Context, apiKey string, req AddEvent
 	// Generate unique event ID
 	eventID := uuid.New().String()
 
+	return s.AddEventWithID(ctx, apiKey, eventID, req)
+}
+
+// AddEventWithID is AddEvent for callers that already carry an idempotency
+// key, such as a client-generated message ID. Resending an ID that was queued
+// in the last day is a no-op: the event is not queued again and does not
+// count against the quota.
+func (s *EventService) AddEventWithID(ctx context.Context, apiKey string, eventID string, req AddEventRequest) (*AddEventResponse, error) {
 	s.logger.Debug().
 		Str("event_id", eventID).
 		Str("api_key", apiKey).
 		Int("payload_size", len(req.Payload)).
 		Msg("Processing event addition")
 
-	err := s.repo.AddEvent(ctx, apiKey, eventID, req.Payload)
+	reserved, err := s.reserveQuota(ctx, apiKey, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	queued, err := s.repo.AddEvent(ctx, apiKey, eventID, req.Payload, req.Context)
 	if err != nil {
+		s.releaseQuota(ctx, apiKey, reserved)
 		s.logger.Error().Err(err).
 			Str("event_id", eventID).
 			Str("api_key", apiKey).
 			Msg("Failed to add event to repository")
 		return nil, err
 	}
-
-	s.logger.Info().
-		Str("event_id", eventID).
-		Str("api_key", apiKey).
-		Msg("Event persisted successfully")
+	if !queued {
+		s.releaseQuota(ctx, apiKey, reserved)
+		s.logger.Debug().
+			Str("event_id", eventID).
+			Str("api_key", apiKey).
+			Msg("Duplicate event ignored")
+	} else {
+		s.logger.Info().
+			Str("event_id", eventID).
+			Str("api_key", apiKey).
+			Msg("Event persisted successfully")
+	}
 
 	return &AddEventResponse{
 		Success: true,
@@ -60,3 +88,70 @@ func (s *EventService) AddEvent(ctx context.Context, apiKey string, req AddEvent
 		EventID: eventID,
 	}, nil
 }
+
+// BatchEvent is one event of an AddEvents batch. Like the ID passed to
+// AddEventWithID, ID is an idempotency key.
+type BatchEvent struct {
+	ID string
+	AddEventRequest
+}
+
+// AddEvents queues a batch of events, such as a Segment batch, in one publish
+// and reports how many were queued. The batch is accepted or rejected as a
+// whole; IDs queued in the last day are skipped and not charged, so a batch
+// that failed can be retried whole.
+func (s *EventService) AddEvents(ctx context.Context, apiKey string, batch []BatchEvent) (int, error) {
+	now := time.Now()
+	events := make([]repositories.QueuedEvent, len(batch))
+	for i, ev := range batch {
+		events[i] = repositories.QueuedEvent{ID: ev.ID, Payload: ev.Payload, Timestamp: now, Context: ev.Context}
+	}
+
+	reserved, err := s.reserveQuota(ctx, apiKey, int64(len(events)))
+	if err != nil {
+		return 0, err
+	}
+
+	queued, err := s.repo.AddEvents(ctx, apiKey, events)
+	if err != nil {
+		s.releaseQuota(ctx, apiKey, reserved)
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Int("batch_size", len(events)).
+			Msg("Failed to add event batch to repository")
+		return 0, err
+	}
+	s.releaseQuota(ctx, apiKey, max(reserved-int64(queued), 0))
+
+	s.logger.Info().
+		Str("api_key", apiKey).
+		Int("batch_size", len(events)).
+		Int("queued", queued).
+		Msg("Event batch persisted successfully")
+
+	return queued, nil
+}
+
+// reserveQuota counts n events against the caller's quota before they are
+// queued, so concurrent requests cannot overshoot it, and returns how many
+// were counted. Events that end up not being queued are handed back with
+// releaseQuota, so only accepted events are charged.
+func (s *EventService) reserveQuota(ctx context.Context, apiKey string, n int64) (int64, error) {
+	if s.usage == nil {
+		return 0, nil
+	}
+
+	if _, err := s.usage.ConsumeEvents(ctx, apiKey, n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *EventService) releaseQuota(ctx context.Context, apiKey string, n int64) {
+	if s.usage == nil || n == 0 {
+		return
+	}
+	// ReleaseEvents logs its own failures; at worst the caller is
+	// overcharged until the period resets.
+	_ = s.usage.ReleaseEvents(ctx, apiKey, n)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Vighnesh-V-H/sync/internal/dedup"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/queue"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/repositories/repotest"
+	"github.com/rs/zerolog"
+)
+
+func TestAddEventQueuesOnStore(t *testing.T) {
+	store := repotest.NewEventStore()
+	svc := NewEventService(store, nil, zerolog.Nop())
+	ctx := context.Background()
+
+	res, err := svc.AddEvent(ctx, "sync_key", AddEventRequest{
+		Payload: map[string]any{"event": "signup"},
+		Context: models.EventContext{IP: "203.0.113.7"},
+	})
+	if err != nil {
+		t.Fatalf("AddEvent: %v", err)
+	}
+
+	queued := store.Queued()
+	if len(queued) != 1 {
+		t.Fatalf("queued %d events, want 1", len(queued))
+	}
+	ev := queued[0]
+	if ev.ID != res.EventID || ev.APIKey != "sync_key" || ev.Context.IP != "203.0.113.7" {
+		t.Fatalf("queued %+v, want event %s for sync_key", ev, res.EventID)
+	}
+}
+
+func TestAddEventWithIDIsIdempotent(t *testing.T) {
+	store := repotest.NewEventStore()
+	svc := NewEventService(store, nil, zerolog.Nop())
+	ctx := context.Background()
+	req := AddEventRequest{Payload: map[string]any{"event": "signup"}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.AddEventWithID(ctx, "sync_key", "msg-1", req); err != nil {
+			t.Fatalf("AddEventWithID: %v", err)
+		}
+	}
+	if n := len(store.Queued()); n != 1 {
+		t.Fatalf("queued %d events, want 1", n)
+	}
+}
+
+// flakyQueue fails the next failures publishes.
+type flakyQueue struct {
+	*queue.MemoryQueue
+	failures int
+}
+
+func (q *flakyQueue) Publish(ctx context.Context, msgs ...queue.Message) error {
+	if q.failures > 0 {
+		q.failures--
+		return errors.New("queue unavailable")
+	}
+	return q.MemoryQueue.Publish(ctx, msgs...)
+}
+
+func TestAddEventsSkipsQueuedIDs(t *testing.T) {
+	q := queue.NewMemoryQueue()
+	repo := repositories.NewEventRepository(nil, dedup.NewMemoryStore(), q, zerolog.Nop())
+	usage := newTestUsageService(t, map[string]string{"sync_free": "free"}, map[string]int64{"free": 10})
+	svc := NewEventService(repo, usage, zerolog.Nop())
+	ctx := context.Background()
+	req := AddEventRequest{Payload: map[string]any{"event": "signup"}}
+
+	if _, err := svc.AddEventWithID(ctx, "sync_free", "a", req); err != nil {
+		t.Fatalf("AddEventWithID: %v", err)
+	}
+	queued, err := svc.AddEvents(ctx, "sync_free", []BatchEvent{{ID: "a", AddEventRequest: req}, {ID: "b", AddEventRequest: req}})
+	if err != nil {
+		t.Fatalf("AddEvents: %v", err)
+	}
+	if queued != 1 || q.Len() != 2 {
+		t.Fatalf("queued %d (queue holds %d), want 1 (2)", queued, q.Len())
+	}
+	if got, _ := usage.GetUsage(ctx, "sync_free"); got.Used != 2 {
+		t.Fatalf("used = %d, want 2", got.Used)
+	}
+}
+
+func TestAddEventsFailedBatchCanBeRetried(t *testing.T) {
+	q := &flakyQueue{MemoryQueue: queue.NewMemoryQueue(), failures: 1}
+	repo := repositories.NewEventRepository(nil, dedup.NewMemoryStore(), q, zerolog.Nop())
+	usage := newTestUsageService(t, map[string]string{"sync_free": "free"}, map[string]int64{"free": 10})
+	svc := NewEventService(repo, usage, zerolog.Nop())
+	ctx := context.Background()
+	req := AddEventRequest{Payload: map[string]any{"event": "signup"}}
+	batch := []BatchEvent{{ID: "a", AddEventRequest: req}, {ID: "b", AddEventRequest: req}}
+
+	if _, err := svc.AddEvents(ctx, "sync_free", batch); err == nil {
+		t.Fatal("AddEvents with the queue down succeeded")
+	}
+	if got, _ := usage.GetUsage(ctx, "sync_free"); got.Used != 0 {
+		t.Fatalf("used = %d after a failed batch, want 0", got.Used)
+	}
+
+	queued, err := svc.AddEvents(ctx, "sync_free", batch)
+	if err != nil {
+		t.Fatalf("retried AddEvents: %v", err)
+	}
+	if queued != 2 || q.Len() != 2 {
+		t.Fatalf("retry queued %d (queue holds %d), want 2", queued, q.Len())
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/rs/zerolog"
+)
+
+const (
+	EventTypeIdentify = "identify"
+	EventTypeAlias    = "alias"
+)
+
+type IdentityService struct {
+	repo   *repositories.IdentityRepository
+	logger zerolog.Logger
+}
+
+func NewIdentityService(repo *repositories.IdentityRepository, logger zerolog.Logger) *IdentityService {
+	return &IdentityService{
+		repo:   repo,
+		logger: logger.With().Str("service", "identity").Logger(),
+	}
+}
+
+// IdentifyRequest attaches traits to distinct_id. If anonymous_id is given,
+// the anonymous visitor is merged into the identified person.
+type IdentifyRequest struct {
+	DistinctID  string         `json:"distinct_id" validate:"required"`
+	AnonymousID string         `json:"anonymous_id"`
+	Traits      map[string]any `json:"traits"`
+}
+
+// AliasRequest declares that alias and distinct_id are the same person.
+type AliasRequest struct {
+	DistinctID string `json:"distinct_id" validate:"required"`
+	Alias      string `json:"alias" validate:"required,nefield=DistinctID"`
+}
+
+// Payload returns the event payload an identify call is queued as.
+func (r IdentifyRequest) Payload() map[string]any {
+	payload := map[string]any{
+		"type":        EventTypeIdentify,
+		"distinct_id": r.DistinctID,
+	}
+	if r.AnonymousID != "" {
+		payload["anonymous_id"] = r.AnonymousID
+	}
+	if r.Traits != nil {
+		payload["traits"] = r.Traits
+	}
+	return payload
+}
+
+func (r AliasRequest) Payload() map[string]any {
+	return map[string]any{
+		"type":        EventTypeAlias,
+		"distinct_id": r.DistinctID,
+		"alias":       r.Alias,
+	}
+}
+
+// Resolve sets ev.DistinctID and ev.PersonID, updating the identity graph
+// along the way. Both sync's own field names and the Segment ones are
+// understood. Events that carry no ID at all are left unresolved.
+func (s *IdentityService) Resolve(ctx context.Context, ev *models.Event) error {
+	userID := payloadString(ev.Payload, "distinct_id", "userId")
+	anonymousID := payloadString(ev.Payload, "anonymous_id", "anonymousId")
+
+	primary, secondary := userID, anonymousID
+	switch payloadString(ev.Payload, "type") {
+	case EventTypeAlias:
+		secondary = payloadString(ev.Payload, "alias", "previousId")
+	default:
+		if primary == "" {
+			primary, secondary = anonymousID, ""
+		}
+	}
+
+	if primary == "" {
+		return nil
+	}
+
+	personID, err := s.repo.Link(ctx, ev.APIKey, primary, secondary)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", ev.ID).
+			Msg("Failed to resolve identity")
+		return err
+	}
+	ev.DistinctID = primary
+	ev.PersonID = personID
+
+	if payloadString(ev.Payload, "type") == EventTypeIdentify {
+		if traits, ok := ev.Payload["traits"].(map[string]any); ok && len(traits) > 0 {
+			if err := s.repo.MergeTraits(ctx, personID, traits); err != nil {
+				s.logger.Error().Err(err).
+					Str("person_id", personID).
+					Msg("Failed to update person traits")
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *IdentityService) GetPerson(ctx context.Context, apiKey string, distinctID string) (*models.Person, error) {
+	person, err := s.repo.GetPersonByDistinctID(ctx, apiKey, distinctID)
+	if err != nil {
+		return nil, err
+	}
+	if person == nil {
+		return nil, internalErrors.ErrPersonNotFound
+	}
+	return person, nil
+}
+
+// payloadString returns the first non-empty string among keys.
+func payloadString(payload map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := payload[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,558 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	ImportFormatNDJSON = "ndjson"
+	ImportFormatCSV    = "csv"
+
+	maxImportLineBytes = 1 << 20
+)
+
+var errImportInterrupted = errors.New("import interrupted by shutdown")
+
+type ImportService struct {
+	events    EventStore
+	imports   *repositories.ImportRepository
+	usage     *UsageService
+	chunkSize int
+	logger    zerolog.Logger
+
+	// ctx is cancelled by Shutdown to stop running jobs; running counts them.
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewImportService enqueues imported rows chunkSize at a time; usage may be
+// nil to skip quota accounting. Call Shutdown to stop background jobs.
+func NewImportService(events EventStore, imports *repositories.ImportRepository, usage *UsageService, chunkSize int, logger zerolog.Logger) *ImportService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ImportService{
+		events:    events,
+		imports:   imports,
+		usage:     usage,
+		chunkSize: chunkSize,
+		logger:    logger.With().Str("service", "import").Logger(),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Shutdown stops accepting imports, interrupts running jobs and waits for
+// them to record how far they got, or until ctx is done. Interrupted jobs are
+// marked failed; the rows they accepted stay queued.
+func (s *ImportService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ImportColumn maps a CSV column onto a payload field. Type is one of
+// string (default), number, bool or json.
+type ImportColumn struct {
+	Field string `json:"field" validate:"required"`
+	Type  string `json:"type" validate:"omitempty,oneof=string number bool json"`
+}
+
+// ImportMapping describes how CSV columns become events. Without Columns,
+// every column is imported as a string field named after its header.
+type ImportMapping struct {
+	Columns         map[string]ImportColumn `json:"columns" validate:"omitempty,dive"`
+	TimestampColumn string                  `json:"timestamp_column"`
+}
+
+type ImportJobResponse struct {
+	Job    *models.ImportJob       `json:"job"`
+	Errors []models.ImportRowError `json:"errors"`
+}
+
+// StartImport spools the body to a temporary file so the upload completes
+// without holding it in memory, then processes it in the background. The
+// returned job can be polled with GetImport.
+func (s *ImportService) StartImport(ctx context.Context, apiKey string, format string, mapping *ImportMapping, body io.Reader) (*models.ImportJob, error) {
+	if format != ImportFormatNDJSON && format != ImportFormatCSV {
+		return nil, internalErrors.ErrUnsupportedImportFormat
+	}
+
+	spool, err := os.CreateTemp("", "sync-import-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(spool, body); err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		return nil, err
+	}
+
+	job := &models.ImportJob{
+		ID:        uuid.New().String(),
+		APIKey:    apiKey,
+		Format:    format,
+		Status:    models.ImportStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if err := s.imports.CreateJob(ctx, job); err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		s.logger.Error().Err(err).Msg("Failed to create import job")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("import_id", job.ID).
+		Str("api_key", apiKey).
+		Str("format", format).
+		Msg("Import job created")
+
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		spool.Close()
+		os.Remove(spool.Name())
+		return nil, internalErrors.ErrImportsStopped
+	}
+	s.running.Add(1)
+	s.mu.Unlock()
+
+	go s.run(job, spool, mapping)
+
+	return job, nil
+}
+
+func (s *ImportService) GetImport(ctx context.Context, apiKey string, id string) (*ImportJobResponse, error) {
+	job, err := s.imports.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.APIKey != apiKey {
+		return nil, internalErrors.ErrImportNotFound
+	}
+
+	rowErrors, err := s.imports.GetRowErrors(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportJobResponse{Job: job, Errors: rowErrors}, nil
+}
+
+func (s *ImportService) run(job *models.ImportJob, spool *os.File, mapping *ImportMapping) {
+	defer s.running.Done()
+
+	ctx := s.ctx
+	// Job state is still recorded after Shutdown cancels ctx.
+	stateCtx := context.WithoutCancel(ctx)
+	log := s.logger.With().Str("import_id", job.ID).Logger()
+
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	fail := func(err error) {
+		log.Error().Err(err).Msg("Import job failed")
+		if setErr := s.imports.SetStatus(stateCtx, job.ID, models.ImportStatusFailed, err.Error()); setErr != nil {
+			log.Error().Err(setErr).Msg("Failed to mark import job as failed")
+		}
+	}
+
+	if err := s.imports.SetStatus(ctx, job.ID, models.ImportStatusProcessing, ""); err != nil {
+		log.Error().Err(err).Msg("Failed to mark import job as processing")
+	}
+
+	// The whole file is charged against the quota before anything is queued,
+	// so an import that does not fit fails without being half imported.
+	// Whatever is not queued in the end is given back.
+	var reserved, accepted int64
+	if s.usage != nil {
+		rows, err := openImportRows(spool, job.Format, mapping)
+		if err != nil {
+			fail(err)
+			return
+		}
+		n, err := countImportEvents(ctx, rows)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if n > 0 {
+			if _, err := s.usage.ConsumeEvents(ctx, job.APIKey, n); err != nil {
+				fail(err)
+				return
+			}
+			reserved = n
+		}
+		defer func() {
+			if reserved > accepted {
+				_ = s.usage.ReleaseEvents(stateCtx, job.APIKey, reserved-accepted)
+			}
+		}()
+	}
+
+	rows, err := openImportRows(spool, job.Format, mapping)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	events := make([]repositories.QueuedEvent, 0, s.chunkSize)
+	var rowErrors []models.ImportRowError
+
+	flush := func() error {
+		queued, err := s.events.AddEvents(ctx, job.APIKey, events)
+		if err != nil {
+			return err
+		}
+		accepted += int64(queued)
+		if err := s.imports.RecordProgress(stateCtx, job.ID, int64(len(events)), rowErrors); err != nil {
+			return err
+		}
+		events = events[:0]
+		rowErrors = rowErrors[:0]
+		return nil
+	}
+
+	for {
+		if ctx.Err() != nil {
+			fail(errImportInterrupted)
+			return
+		}
+
+		row, ev, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *importRowError
+		if errors.As(err, &rowErr) {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: row, Error: rowErr.Error()})
+		} else if err != nil {
+			fail(err)
+			return
+		} else {
+			events = append(events, ev)
+		}
+
+		if len(events)+len(rowErrors) >= s.chunkSize {
+			if err := flush(); err != nil {
+				fail(importError(ctx, err))
+				return
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		fail(importError(ctx, err))
+		return
+	}
+
+	if err := s.imports.SetStatus(stateCtx, job.ID, models.ImportStatusCompleted, ""); err != nil {
+		log.Error().Err(err).Msg("Failed to mark import job as completed")
+		return
+	}
+	log.Info().Msg("Import job completed")
+}
+
+// importError reports a failure caused by Shutdown as an interruption.
+func importError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return errImportInterrupted
+	}
+	return err
+}
+
+func openImportRows(spool *os.File, format string, mapping *ImportMapping) (importReader, error) {
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if format == ImportFormatCSV {
+		return newCSVImportReader(spool, mapping)
+	}
+	return newNDJSONImportReader(spool), nil
+}
+
+// countImportEvents returns how many rows of the import are valid events.
+func countImportEvents(ctx context.Context, rows importReader) (int64, error) {
+	var n int64
+	for {
+		if ctx.Err() != nil {
+			return 0, errImportInterrupted
+		}
+		_, _, err := rows.Next()
+		if err == io.EOF {
+			return n, nil
+		}
+		var rowErr *importRowError
+		if errors.As(err, &rowErr) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		n++
+	}
+}
+
+// importRowError is a problem with a single row; the import carries on.
+// Any other error from an importReader aborts the job.
+type importRowError struct {
+	msg string
+}
+
+func (e *importRowError) Error() string { return e.msg }
+
+func rowErrorf(format string, args ...any) error {
+	return &importRowError{msg: fmt.Sprintf(format, args...)}
+}
+
+type importReader interface {
+	// Next returns the 1-based row number and its event, or io.EOF.
+	Next() (int64, repositories.QueuedEvent, error)
+}
+
+type ndjsonImportReader struct {
+	reader *bufio.Reader
+	row    int64
+}
+
+func newNDJSONImportReader(r io.Reader) *ndjsonImportReader {
+	return &ndjsonImportReader{reader: bufio.NewReaderSize(r, 64*1024)}
+}
+
+type ndjsonImportLine struct {
+	Payload   map[string]any `json:"payload"`
+	Timestamp string         `json:"timestamp"`
+}
+
+func (r *ndjsonImportReader) Next() (int64, repositories.QueuedEvent, error) {
+	for {
+		line, tooLong, err := r.readLine()
+		if err != nil {
+			return r.row, repositories.QueuedEvent{}, err
+		}
+		r.row++
+		if tooLong {
+			return r.row, repositories.QueuedEvent{}, rowErrorf("line exceeds %d bytes", maxImportLineBytes)
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var parsed ndjsonImportLine
+		if err := json.Unmarshal(line, &parsed); err != nil {
+			return r.row, repositories.QueuedEvent{}, rowErrorf("invalid JSON: %v", err)
+		}
+		if len(parsed.Payload) == 0 {
+			return r.row, repositories.QueuedEvent{}, rowErrorf("payload is required")
+		}
+
+		ts, err := parseImportTimestamp(parsed.Timestamp)
+		if err != nil {
+			return r.row, repositories.QueuedEvent{}, err
+		}
+
+		return r.row, repositories.QueuedEvent{
+			ID:        uuid.New().String(),
+			Payload:   parsed.Payload,
+			Timestamp: ts,
+		}, nil
+	}
+}
+
+// readLine returns the next line without its line ending, or io.EOF. A line
+// longer than maxImportLineBytes is skipped without being buffered and
+// reported as tooLong, so one bad line does not end the import.
+func (r *ndjsonImportReader) readLine() (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		if !tooLong {
+			line = append(line, chunk...)
+			// Allow for the "\r\n" line ending.
+			if len(line) > maxImportLineBytes+2 {
+				line, tooLong = nil, true
+			}
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && (len(line) > 0 || tooLong):
+			// Last line without a trailing newline.
+		case err != nil:
+			return nil, false, err
+		}
+
+		line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+		if len(line) > maxImportLineBytes {
+			return nil, true, nil
+		}
+		return line, tooLong, nil
+	}
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	header  []string
+	mapping *ImportMapping
+	row     int64
+}
+
+func newCSVImportReader(r io.Reader, mapping *ImportMapping) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	header = append([]string(nil), header...)
+
+	if mapping == nil {
+		mapping = &ImportMapping{}
+	}
+	for column := range mapping.Columns {
+		if !containsString(header, column) {
+			return nil, fmt.Errorf("mapped column %q not found in CSV header", column)
+		}
+	}
+	if mapping.TimestampColumn != "" && !containsString(header, mapping.TimestampColumn) {
+		return nil, fmt.Errorf("timestamp column %q not found in CSV header", mapping.TimestampColumn)
+	}
+
+	return &csvImportReader{reader: reader, header: header, mapping: mapping}, nil
+}
+
+func (r *csvImportReader) Next() (int64, repositories.QueuedEvent, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return r.row, repositories.QueuedEvent{}, io.EOF
+	}
+	r.row++
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return r.row, repositories.QueuedEvent{}, rowErrorf("%v", parseErr.Err)
+	}
+	if err != nil {
+		return r.row, repositories.QueuedEvent{}, err
+	}
+
+	payload := make(map[string]any, len(record))
+	var rawTimestamp string
+	for i, value := range record {
+		column := r.header[i]
+		if column == r.mapping.TimestampColumn {
+			rawTimestamp = value
+			continue
+		}
+		if value == "" {
+			continue
+		}
+
+		col, mapped := r.mapping.Columns[column]
+		if !mapped {
+			if len(r.mapping.Columns) > 0 {
+				continue
+			}
+			col = ImportColumn{Field: column}
+		}
+
+		converted, err := convertImportValue(value, col.Type)
+		if err != nil {
+			return r.row, repositories.QueuedEvent{}, rowErrorf("column %q: %v", column, err)
+		}
+		payload[col.Field] = converted
+	}
+
+	if len(payload) == 0 {
+		return r.row, repositories.QueuedEvent{}, rowErrorf("row has no mapped values")
+	}
+
+	ts, err := parseImportTimestamp(rawTimestamp)
+	if err != nil {
+		return r.row, repositories.QueuedEvent{}, err
+	}
+
+	return r.row, repositories.QueuedEvent{
+		ID:        uuid.New().String(),
+		Payload:   payload,
+		Timestamp: ts,
+	}, nil
+}
+
+func convertImportValue(value string, typ string) (any, error) {
+	switch typ {
+	case "", "string":
+		return value, nil
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	case "json":
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", typ)
+	}
+}
+
+// parseImportTimestamp accepts RFC 3339 or unix seconds; an empty value means now.
+func parseImportTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Now(), nil
+	}
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Time{}, rowErrorf("invalid timestamp %q", value)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/repositories/repotest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func newTestImportService(t *testing.T, events EventStore, usage *UsageService) *ImportService {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	svc := NewImportService(events, repositories.NewImportRepository(client, zerolog.Nop()), usage, 2, zerolog.Nop())
+	t.Cleanup(func() { svc.Shutdown(context.Background()) })
+	return svc
+}
+
+// waitForImport polls the job until it has finished.
+func waitForImport(t *testing.T, svc *ImportService, apiKey string, id string) *ImportJobResponse {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		res, err := svc.GetImport(context.Background(), apiKey, id)
+		if err != nil {
+			t.Fatalf("GetImport: %v", err)
+		}
+		if res.Job.Status == models.ImportStatusCompleted || res.Job.Status == models.ImportStatusFailed {
+			return res
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("import %s did not finish", id)
+	return nil
+}
+
+func TestImportNDJSONReportsRowErrors(t *testing.T) {
+	store := repotest.NewEventStore()
+	svc := newTestImportService(t, store, nil)
+
+	body := strings.Join([]string{
+		`{"payload":{"event":"a"}}`,
+		`{"payload":{"event":"` + strings.Repeat("x", maxImportLineBytes) + `"}}`,
+		`not json`,
+		``,
+		`{"payload":{"event":"b"},"timestamp":"2026-01-02T03:04:05Z"}`,
+	}, "\n")
+	job, err := svc.StartImport(context.Background(), "sync_key", ImportFormatNDJSON, nil, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("StartImport: %v", err)
+	}
+
+	res := waitForImport(t, svc, "sync_key", job.ID)
+	if res.Job.Status != models.ImportStatusCompleted || res.Job.RowsAccepted != 2 || res.Job.RowsFailed != 2 {
+		t.Fatalf("job = %+v, want completed with 2 accepted and 2 failed", res.Job)
+	}
+	if len(res.Errors) != 2 || res.Errors[0].Row != 2 || !strings.Contains(res.Errors[0].Error, "exceeds") || res.Errors[1].Row != 3 {
+		t.Fatalf("row errors = %+v, want rows 2 (too long) and 3", res.Errors)
+	}
+	if n := len(store.Queued()); n != 2 {
+		t.Fatalf("queued %d events, want 2", n)
+	}
+}
+
+func TestImportOverQuotaQueuesNothing(t *testing.T) {
+	store := repotest.NewEventStore()
+	usage := newTestUsageService(t, map[string]string{"sync_free": "free"}, map[string]int64{"free": 2})
+	svc := newTestImportService(t, store, usage)
+
+	body := "{\"payload\":{\"n\":1}}\n{\"payload\":{\"n\":2}}\n{\"payload\":{\"n\":3}}\n"
+	job, err := svc.StartImport(context.Background(), "sync_free", ImportFormatNDJSON, nil, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("StartImport: %v", err)
+	}
+
+	res := waitForImport(t, svc, "sync_free", job.ID)
+	if res.Job.Status != models.ImportStatusFailed || !strings.Contains(res.Job.Error, internalErrors.ErrQuotaExceeded.Error()) {
+		t.Fatalf("job = %+v, want failed on quota", res.Job)
+	}
+	if n := len(store.Queued()); n != 0 {
+		t.Fatalf("queued %d events, want none", n)
+	}
+	if got, _ := usage.GetUsage(context.Background(), "sync_free"); got.Used != 0 {
+		t.Fatalf("used = %d, want 0", got.Used)
+	}
+}
+
+func TestImportChargesOnlyAcceptedRows(t *testing.T) {
+	store := repotest.NewEventStore()
+	usage := newTestUsageService(t, map[string]string{"sync_free": "free"}, map[string]int64{"free": 10})
+	svc := newTestImportService(t, store, usage)
+
+	body := "{\"payload\":{\"n\":1}}\nbroken\n{\"payload\":{\"n\":2}}\n"
+	job, err := svc.StartImport(context.Background(), "sync_free", ImportFormatNDJSON, nil, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("StartImport: %v", err)
+	}
+
+	if res := waitForImport(t, svc, "sync_free", job.ID); res.Job.Status != models.ImportStatusCompleted {
+		t.Fatalf("job = %+v, want completed", res.Job)
+	}
+	if got, _ := usage.GetUsage(context.Background(), "sync_free"); got.Used != 2 {
+		t.Fatalf("used = %d, want 2", got.Used)
+	}
+}
+
+// blockingEventStore holds AddEvents until its context is cancelled.
+type blockingEventStore struct {
+	*repotest.EventStore
+	started chan struct{}
+}
+
+func (s *blockingEventStore) AddEvents(ctx context.Context, _ string, _ []repositories.QueuedEvent) (int, error) {
+	close(s.started)
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestImportShutdownInterruptsJobs(t *testing.T) {
+	store := &blockingEventStore{EventStore: repotest.NewEventStore(), started: make(chan struct{})}
+	svc := newTestImportService(t, store, nil)
+	ctx := context.Background()
+
+	job, err := svc.StartImport(ctx, "sync_key", ImportFormatNDJSON, nil, strings.NewReader("{\"payload\":{\"n\":1}}\n"))
+	if err != nil {
+		t.Fatalf("StartImport: %v", err)
+	}
+	<-store.started
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := svc.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	res, err := svc.GetImport(ctx, "sync_key", job.ID)
+	if err != nil {
+		t.Fatalf("GetImport: %v", err)
+	}
+	if res.Job.Status != models.ImportStatusFailed || res.Job.Error != errImportInterrupted.Error() {
+		t.Fatalf("job = %+v, want failed as interrupted", res.Job)
+	}
+
+	if _, err := svc.StartImport(ctx, "sync_key", ImportFormatNDJSON, nil, strings.NewReader("")); !errors.Is(err, internalErrors.ErrImportsStopped) {
+		t.Fatalf("StartImport after Shutdown = %v, want ErrImportsStopped", err)
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/totp"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+)
+
+const (
+	// mfaIssuer labels the account in authenticator apps.
+	mfaIssuer = "Sync"
+	// mfaSkew accepts codes one step either side of now for clock drift.
+	mfaSkew           = 1
+	recoveryCodeCount = 10
+	// mfaMaxFailures invalid codes in a row, whether entered to sign in or
+	// to enable or disable MFA, lock the account's codes for mfaLockout.
+	// With mfaSkew each guess matches 3 codes in a million, so a guesser has
+	// about one chance in 67000 per lockout.
+	mfaMaxFailures = 5
+	mfaLockout     = 15 * time.Minute
+)
+
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFASigninRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnrollMFA generates a TOTP secret for the user. It has no effect on
+// signin until confirmed with VerifyMFA; enrolling again replaces a pending
+// secret.
+func (s *AuthService) EnrollMFA(ctx context.Context, userID string) (*MFAEnrollment, error) {
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, internalErrors.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to generate TOTP secret")
+		return nil, err
+	}
+	if err := s.repo.SetMFASecret(ctx, userID, secret); err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to store TOTP secret in repository")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("MFA enrollment started")
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// VerifyMFA confirms enrollment with a code from the authenticator app and
+// turns MFA on. The recovery codes it returns are stored hashed and cannot
+// be shown again.
+func (s *AuthService) VerifyMFA(ctx context.Context, userID string, req MFACodeRequest) (*MFARecoveryCodes, error) {
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, internalErrors.ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, internalErrors.ErrMFANotEnrolled
+	}
+
+	var step int64
+	err = s.attemptMFACode(ctx, user, func() error {
+		var ok bool
+		step, ok = totp.Match(user.MFASecret, req.Code, time.Now(), mfaSkew)
+		if !ok {
+			s.logger.Warn().
+				Str("user_id", userID).
+				Msg("Invalid TOTP code on MFA verification")
+			return internalErrors.ErrInvalidMFACode
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i] = newRecoveryCode()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.repo.EnableMFA(ctx, userID, step, hashes); err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to enable MFA in repository")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("MFA enabled")
+
+	return &MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA turns MFA off. It takes a current TOTP or recovery code, under
+// the same lockout as signins, so a stolen session alone cannot remove the
+// second factor.
+func (s *AuthService) DisableMFA(ctx context.Context, userID string, req MFACodeRequest) error {
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled {
+		return internalErrors.ErrMFANotEnrolled
+	}
+	err = s.attemptMFACode(ctx, user, func() error {
+		return s.checkMFACode(ctx, userID, user.MFASecret, req.Code)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DisableMFA(ctx, userID); err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to disable MFA in repository")
+		return err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("MFA disabled")
+	return nil
+}
+
+// SigninMFA completes a signin that Signin answered with an MFA challenge,
+// accepting either a TOTP or a recovery code. Only the latest challenge can
+// be used, and only once. Codes are subject to the lockout in
+// attemptMFACode.
+func (s *AuthService) SigninMFA(ctx context.Context, req MFASigninRequest) (*AuthResponse, error) {
+	userID, challengeID, err := utils.ParseMFAChallenge(req.MFAToken, s.jwtConfig.Secret)
+	if err != nil {
+		return nil, internalErrors.ErrInvalidMFAChallenge
+	}
+
+	user, err := s.Account(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetActor(ctx, user.ID, user.Api_Key)
+	if !user.MFAEnabled || user.MFAChallenge != challengeID {
+		// MFA was turned off, or the challenge used or superseded, since it
+		// was issued.
+		return nil, internalErrors.ErrInvalidMFAChallenge
+	}
+	err = s.attemptMFACode(ctx, user, func() error {
+		return s.checkMFACode(ctx, userID, user.MFASecret, req.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.repo.UseMFAChallenge(ctx, userID, challengeID)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to use MFA challenge in repository")
+		return nil, err
+	}
+	if !used {
+		// A concurrent request completed the signin with it.
+		return nil, internalErrors.ErrInvalidMFAChallenge
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Api_Key, user.TokenVersion, s.jwtConfig)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to generate JWT token")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("MFA signin successful")
+
+	return &AuthResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
+
+// attemptMFACode runs check, which verifies a code the user entered, unless
+// the account's codes are locked, in which case it returns ErrMFALocked.
+// check returning ErrInvalidMFACode counts towards the lockout.
+func (s *AuthService) attemptMFACode(ctx context.Context, user *models.User, check func() error) error {
+	if user.MFALockedUntil != nil && time.Now().Before(*user.MFALockedUntil) {
+		s.logger.Warn().
+			Str("user_id", user.ID).
+			Msg("MFA code entered while locked")
+		return internalErrors.ErrMFALocked
+	}
+	err := check()
+	if errors.Is(err, internalErrors.ErrInvalidMFACode) {
+		return s.recordMFAFailure(ctx, user.ID)
+	}
+	return err
+}
+
+// recordMFAFailure counts an invalid code and returns the error to answer it
+// with: ErrMFALocked if it locked the account, else ErrInvalidMFACode.
+func (s *AuthService) recordMFAFailure(ctx context.Context, userID string) error {
+	locked, err := s.repo.RecordMFAFailure(ctx, userID, mfaMaxFailures, time.Now().Add(mfaLockout))
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to record invalid MFA code in repository")
+		return err
+	}
+	if !locked {
+		return internalErrors.ErrInvalidMFACode
+	}
+	s.logger.Warn().
+		Str("user_id", userID).
+		Dur("lockout", mfaLockout).
+		Msg("MFA locked after too many invalid codes")
+	return internalErrors.ErrMFALocked
+}
+
+// checkMFACode accepts code as a TOTP not used before or as an unused
+// recovery code, consuming it either way.
+func (s *AuthService) checkMFACode(ctx context.Context, userID string, secret string, code string) error {
+	if step, ok := totp.Match(secret, code, time.Now(), mfaSkew); ok {
+		fresh, err := s.repo.UseMFAStep(ctx, userID, step)
+		if err != nil {
+			s.logger.Error().Err(err).
+				Str("user_id", userID).
+				Msg("Failed to record TOTP step in repository")
+			return err
+		}
+		if fresh {
+			return nil
+		}
+		s.logger.Warn().
+			Str("user_id", userID).
+			Msg("Replayed TOTP code")
+		return internalErrors.ErrInvalidMFACode
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to use recovery code in repository")
+		return err
+	}
+	if !used {
+		s.logger.Warn().
+			Str("user_id", userID).
+			Msg("Invalid MFA code")
+		return internalErrors.ErrInvalidMFACode
+	}
+
+	s.logger.Info().
+		Str("user_id", userID).
+		Msg("Recovery code used")
+	return nil
+}
+
+// newRecoveryCode returns a code like "k3v9q-2hx7m".
+func newRecoveryCode() string {
+	code := strings.ToLower(rand.Text()[:10])
+	return code[:5] + "-" + code[5:]
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed as
+// loosely as they are read.
+func hashRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
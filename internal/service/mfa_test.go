@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/totp"
+)
+
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatalf("totp.Code: %v", err)
+	}
+	return code
+}
+
+// enableTestMFA enrolls the user and returns their secret and recovery codes.
+func enableTestMFA(t *testing.T, svc *AuthService, userID string) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	enrollment, err := svc.EnrollMFA(ctx, userID)
+	if err != nil {
+		t.Fatalf("EnrollMFA: %v", err)
+	}
+	codes, err := svc.VerifyMFA(ctx, userID, MFACodeRequest{Code: totpCode(t, enrollment.Secret, totp.Step(time.Now()))})
+	if err != nil {
+		t.Fatalf("VerifyMFA: %v", err)
+	}
+	if len(codes.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes.RecoveryCodes), recoveryCodeCount)
+	}
+	return enrollment.Secret, codes.RecoveryCodes
+}
+
+func TestMFASignin(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	secret, _ := enableTestMFA(t, svc, userID)
+
+	res, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if !res.MFARequired || res.MFAToken == "" || res.Token != "" || res.User != nil {
+		t.Fatalf("Signin with MFA = %+v, want only a challenge", res)
+	}
+
+	// The step used to verify enrollment is spent; the next one is within skew.
+	code := totpCode(t, secret, totp.Step(time.Now())+1)
+	done, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: code})
+	if err != nil {
+		t.Fatalf("SigninMFA: %v", err)
+	}
+	if done.Token == "" {
+		t.Fatal("SigninMFA returned no token")
+	}
+
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: totpCode(t, secret, totp.Step(time.Now()))}); !errors.Is(err, internalErrors.ErrInvalidMFAChallenge) {
+		t.Fatalf("SigninMFA with used challenge = %v, want ErrInvalidMFAChallenge", err)
+	}
+
+	res, err = svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: code}); !errors.Is(err, internalErrors.ErrInvalidMFACode) {
+		t.Fatalf("SigninMFA with replayed code = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestSigninMFAOnlyAcceptsLatestChallenge(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	_, recovery := enableTestMFA(t, svc, userID)
+
+	first, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"}); err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: first.MFAToken, Code: recovery[0]}); !errors.Is(err, internalErrors.ErrInvalidMFAChallenge) {
+		t.Fatalf("SigninMFA with superseded challenge = %v, want ErrInvalidMFAChallenge", err)
+	}
+}
+
+func TestSigninMFALocksAfterInvalidCodes(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	_, recovery := enableTestMFA(t, svc, userID)
+
+	res, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	for i := 1; i < mfaMaxFailures; i++ {
+		if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: "not-a-code"}); !errors.Is(err, internalErrors.ErrInvalidMFACode) {
+			t.Fatalf("invalid code %d = %v, want ErrInvalidMFACode", i, err)
+		}
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: "not-a-code"}); !errors.Is(err, internalErrors.ErrMFALocked) {
+		t.Fatalf("invalid code %d = %v, want ErrMFALocked", mfaMaxFailures, err)
+	}
+
+	// The lock holds for a fresh challenge and a valid code.
+	res, err = svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: recovery[0]}); !errors.Is(err, internalErrors.ErrMFALocked) {
+		t.Fatalf("SigninMFA while locked = %v, want ErrMFALocked", err)
+	}
+
+	// Let the lockout lapse.
+	past := time.Now().Add(-time.Second)
+	store.RecordMFAFailure(ctx, userID, 1, past)
+	res, err = svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: recovery[0]}); err != nil {
+		t.Fatalf("SigninMFA after lockout: %v", err)
+	}
+}
+
+func TestMFARecoveryCodeWorksOnce(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	_, recovery := enableTestMFA(t, svc, userID)
+
+	res, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: recovery[0]}); err != nil {
+		t.Fatalf("SigninMFA with recovery code: %v", err)
+	}
+	res, err = svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken, Code: recovery[0]}); !errors.Is(err, internalErrors.ErrInvalidMFACode) {
+		t.Fatalf("SigninMFA with used recovery code = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestSigninMFARejectsTamperedChallenge(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	enableTestMFA(t, svc, userID)
+
+	res, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if _, err := svc.SigninMFA(ctx, MFASigninRequest{MFAToken: res.MFAToken + "x", Code: "000000"}); !errors.Is(err, internalErrors.ErrInvalidMFAChallenge) {
+		t.Fatalf("SigninMFA with tampered challenge = %v, want ErrInvalidMFAChallenge", err)
+	}
+}
+
+func TestDisableMFARequiresCode(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	_, recovery := enableTestMFA(t, svc, userID)
+
+	if err := svc.DisableMFA(ctx, userID, MFACodeRequest{Code: "not-a-code"}); !errors.Is(err, internalErrors.ErrInvalidMFACode) {
+		t.Fatalf("DisableMFA with wrong code = %v, want ErrInvalidMFACode", err)
+	}
+	if err := svc.DisableMFA(ctx, userID, MFACodeRequest{Code: recovery[1]}); err != nil {
+		t.Fatalf("DisableMFA: %v", err)
+	}
+
+	res, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Signin: %v", err)
+	}
+	if res.MFARequired || res.Token == "" {
+		t.Fatalf("Signin after disabling MFA = %+v, want a token", res)
+	}
+}
+
+func TestDisableMFALocksAfterInvalidCodes(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	_, recovery := enableTestMFA(t, svc, userID)
+
+	for i := 1; i < mfaMaxFailures; i++ {
+		if err := svc.DisableMFA(ctx, userID, MFACodeRequest{Code: "not-a-code"}); !errors.Is(err, internalErrors.ErrInvalidMFACode) {
+			t.Fatalf("invalid code %d = %v, want ErrInvalidMFACode", i, err)
+		}
+	}
+	if err := svc.DisableMFA(ctx, userID, MFACodeRequest{Code: "not-a-code"}); !errors.Is(err, internalErrors.ErrMFALocked) {
+		t.Fatalf("invalid code %d = %v, want ErrMFALocked", mfaMaxFailures, err)
+	}
+	if err := svc.DisableMFA(ctx, userID, MFACodeRequest{Code: recovery[0]}); !errors.Is(err, internalErrors.ErrMFALocked) {
+		t.Fatalf("DisableMFA with valid code while locked = %v, want ErrMFALocked", err)
+	}
+
+	account, err := svc.Account(ctx, userID)
+	if err != nil || !account.MFAEnabled {
+		t.Fatalf("Account = %+v, %v; want MFA still enabled", account, err)
+	}
+}
+
+func TestVerifyMFACountsInvalidCodes(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	userID := signupTestUser(t, svc)
+	enrollment, err := svc.EnrollMFA(ctx, userID)
+	if err != nil {
+		t.Fatalf("EnrollMFA: %v", err)
+	}
+
+	for i := 1; i < mfaMaxFailures; i++ {
+		if _, err := svc.VerifyMFA(ctx, userID, MFACodeRequest{Code: "not-a-code"}); !errors.Is(err, internalErrors.ErrInvalidMFACode) {
+			t.Fatalf("invalid code %d = %v, want ErrInvalidMFACode", i, err)
+		}
+	}
+	if _, err := svc.VerifyMFA(ctx, userID, MFACodeRequest{Code: "not-a-code"}); !errors.Is(err, internalErrors.ErrMFALocked) {
+		t.Fatalf("invalid code %d = %v, want ErrMFALocked", mfaMaxFailures, err)
+	}
+	code := totpCode(t, enrollment.Secret, totp.Step(time.Now()))
+	if _, err := svc.VerifyMFA(ctx, userID, MFACodeRequest{Code: code}); !errors.Is(err, internalErrors.ErrMFALocked) {
+		t.Fatalf("VerifyMFA with valid code while locked = %v, want ErrMFALocked", err)
+	}
+}
+
+func TestEnrollMFAWhenEnabled(t *testing.T) {
+	svc, _ := newTestAuthService()
+	userID := signupTestUser(t, svc)
+	enableTestMFA(t, svc, userID)
+
+	if _, err := svc.EnrollMFA(context.Background(), userID); !errors.Is(err, internalErrors.ErrMFAAlreadyEnabled) {
+		t.Fatalf("EnrollMFA when enabled = %v, want ErrMFAAlreadyEnabled", err)
+	}
+}
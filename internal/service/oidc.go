@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/audit"
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/oidc"
+	"github.com/google/uuid"
+)
+
+// OIDCSignin signs in the user linked to id, linking it first if needed.
+// An unlinked identity is matched to an existing account by email, or gets
+// a new passwordless account, but only if the provider verified the email:
+// otherwise anyone could claim an account by asserting its address. Nor is
+// it matched to an account whose own email was never confirmed, which
+// whoever owns the address may not have created.
+func (s *AuthService) OIDCSignin(ctx context.Context, id *oidc.Identity) (*AuthResponse, error) {
+	log := s.logger.With().
+		Str("provider", id.Provider).
+		Str("subject", id.Subject).
+		Logger()
+
+	user, err := s.repo.GetUserByIdentity(ctx, id.Provider, id.Subject)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch user by identity from repository")
+		return nil, err
+	}
+
+	if user == nil {
+		if !id.EmailVerified || id.Email == "" {
+			log.Warn().Str("email", id.Email).Msg("Rejected OIDC login with unverified email")
+			return nil, internalErrors.ErrEmailNotVerified
+		}
+
+		user, err = s.oidcAccount(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.LinkIdentity(ctx, user.ID, id.Provider, id.Subject, NormalizeEmail(id.Email)); err != nil {
+			log.Error().Err(err).Str("user_id", user.ID).Msg("Failed to link identity")
+			return nil, err
+		}
+		log.Info().Str("user_id", user.ID).Msg("Linked OIDC identity to user")
+	}
+
+	audit.SetActor(ctx, user.ID, user.Api_Key)
+
+	// Signing in through a provider does not skip the second factor.
+	res, err := s.session(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("user_id", user.ID).
+		Bool("mfa_required", res.MFARequired).
+		Msg("OIDC signin successful")
+	return res, nil
+}
+
+// oidcAccount returns the account with id's email, creating it if there is
+// none. A concurrent login creating the same account is tolerated.
+func (s *AuthService) oidcAccount(ctx context.Context, id *oidc.Identity) (*models.User, error) {
+	email := NormalizeEmail(id.Email)
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		if !user.IsVerified {
+			s.logger.Warn().
+				Str("user_id", user.ID).
+				Str("provider", id.Provider).
+				Msg("Refused to link OIDC login to account with unconfirmed email")
+			return nil, internalErrors.ErrAccountNotVerified
+		}
+		return user, nil
+	}
+
+	name := id.Name
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	now := time.Now()
+	user = &models.User{
+		ID:         uuid.New().String(),
+		Email:      email,
+		Name:       name,
+		IsVerified: true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	err = s.createUser(ctx, user)
+	if errors.Is(err, internalErrors.ErrUserAlreadyExists) {
+		user, err = s.repo.GetUserByEmail(ctx, email)
+		if err == nil && user == nil {
+			err = internalErrors.ErrUserNotFound
+		}
+		if err == nil && !user.IsVerified {
+			err = internalErrors.ErrAccountNotVerified
+		}
+		if err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("email", email).
+		Str("user_id", user.ID).
+		Str("provider", id.Provider).
+		Msg("User created from OIDC login")
+	return user, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/oidc"
+)
+
+func TestOIDCSigninCreatesAccount(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+
+	res, err := svc.OIDCSignin(ctx, &oidc.Identity{Provider: "google", Subject: "g-1", Email: "Ada@Example.com", EmailVerified: true, Name: "Ada"})
+	if err != nil {
+		t.Fatalf("OIDCSignin: %v", err)
+	}
+	if res.Token == "" || res.User.Email != "ada@example.com" || res.User.Api_Key == "" {
+		t.Fatalf("OIDCSignin returned %+v", res)
+	}
+
+	linked, _ := store.GetUserByIdentity(ctx, "google", "g-1")
+	if linked == nil || linked.ID != res.User.ID {
+		t.Fatalf("identity linked to %+v, want %s", linked, res.User.ID)
+	}
+
+	// The account has no password to sign in with.
+	if _, err := svc.Signin(ctx, SigninRequest{Email: "ada@example.com", Password: ""}); err == nil {
+		t.Fatal("password signin to an OIDC-only account succeeded")
+	}
+}
+
+func TestOIDCSigninLinksExistingAccountByVerifiedEmail(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	mailer := &captureMailer{sent: map[string]string{}}
+	svc.mailer = mailer
+	userID := signupTestUser(t, svc)
+	if _, err := svc.ConfirmEmail(ctx, ConfirmEmailRequest{Token: mailer.sent["ada@example.com"]}); err != nil {
+		t.Fatalf("ConfirmEmail: %v", err)
+	}
+
+	res, err := svc.OIDCSignin(ctx, &oidc.Identity{Provider: "google", Subject: "g-1", Email: "ada@example.com", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("OIDCSignin: %v", err)
+	}
+	if res.User.ID != userID {
+		t.Fatalf("signed in as %s, want existing user %s", res.User.ID, userID)
+	}
+
+	// Once linked, the provider account keeps resolving to the user even if
+	// its email changes.
+	res, err = svc.OIDCSignin(ctx, &oidc.Identity{Provider: "google", Subject: "g-1", Email: "ada@elsewhere.com"})
+	if err != nil || res.User.ID != userID {
+		t.Fatalf("second OIDCSignin = %+v, %v; want user %s", res, err, userID)
+	}
+}
+
+func TestOIDCSigninRejectsUnverifiedEmail(t *testing.T) {
+	svc, _ := newTestAuthService()
+	ctx := context.Background()
+	signupTestUser(t, svc)
+
+	_, err := svc.OIDCSignin(ctx, &oidc.Identity{Provider: "google", Subject: "attacker", Email: "ada@example.com", EmailVerified: false})
+	if !errors.Is(err, internalErrors.ErrEmailNotVerified) {
+		t.Fatalf("OIDCSignin with unverified email = %v, want ErrEmailNotVerified", err)
+	}
+}
+
+func TestOIDCSigninDoesNotLinkUnconfirmedAccount(t *testing.T) {
+	svc, store := newTestAuthService()
+	ctx := context.Background()
+	signupTestUser(t, svc)
+
+	_, err := svc.OIDCSignin(ctx, &oidc.Identity{Provider: "google", Subject: "g-1", Email: "ada@example.com", EmailVerified: true})
+	if !errors.Is(err, internalErrors.ErrAccountNotVerified) {
+		t.Fatalf("OIDCSignin matching unconfirmed account = %v, want ErrAccountNotVerified", err)
+	}
+	if linked, _ := store.GetUserByIdentity(ctx, "google", "g-1"); linked != nil {
+		t.Fatalf("identity linked to %+v", linked)
+	}
+}
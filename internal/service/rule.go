@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/rules"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// rulesTTL bounds how long the processor keeps applying a project's old
+// rules after they are edited.
+const rulesTTL = 30 * time.Second
+
+type RuleService struct {
+	repo   RuleStore
+	logger zerolog.Logger
+	cache  *tenantCache[[]*rules.Compiled]
+}
+
+func NewRuleService(repo RuleStore, logger zerolog.Logger) *RuleService {
+	return &RuleService{
+		repo:   repo,
+		logger: logger.With().Str("service", "rule").Logger(),
+		cache:  newTenantCache[[]*rules.Compiled](rulesTTL, tenantCacheSize),
+	}
+}
+
+type RuleRequest struct {
+	Name      string `json:"name" validate:"required,max=200"`
+	Position  int    `json:"position"`
+	Enabled   *bool  `json:"enabled"`
+	Condition string `json:"condition"`
+	Action    string `json:"action" validate:"required,oneof=drop rename redact hash set route"`
+	Field     string `json:"field"`
+	Target    string `json:"target"`
+	Value     string `json:"value"`
+}
+
+// DryRunRequest applies Rules, or the project's saved rules when Rules is
+// empty, to a sample event without storing anything.
+type DryRunRequest struct {
+	Rules []RuleRequest `json:"rules" validate:"omitempty,dive"`
+	Event DryRunEvent   `json:"event" validate:"required"`
+}
+
+type DryRunEvent struct {
+	Payload    map[string]any      `json:"payload" validate:"required"`
+	DistinctID string              `json:"distinct_id"`
+	Context    models.EventContext `json:"context"`
+}
+
+type DryRunResponse struct {
+	*rules.Result
+	Payload map[string]any `json:"payload"`
+	Routes  []string       `json:"routes"`
+}
+
+func (r RuleRequest) rule(apiKey string) *models.Rule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &models.Rule{
+		APIKey:    apiKey,
+		Name:      r.Name,
+		Position:  r.Position,
+		Enabled:   enabled,
+		Condition: r.Condition,
+		Action:    r.Action,
+		Field:     r.Field,
+		Target:    r.Target,
+		Value:     r.Value,
+	}
+}
+
+func (s *RuleService) CreateRule(ctx context.Context, apiKey string, req RuleRequest) (*models.Rule, error) {
+	rule := req.rule(apiKey)
+	if _, err := rules.Compile(rule); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to create rule")
+		return nil, err
+	}
+	s.invalidate(apiKey)
+
+	s.logger.Info().
+		Str("rule_id", rule.ID).
+		Str("action", rule.Action).
+		Msg("Rule created")
+
+	return rule, nil
+}
+
+func (s *RuleService) ListRules(ctx context.Context, apiKey string) ([]*models.Rule, error) {
+	return s.repo.ListRules(ctx, apiKey)
+}
+
+func (s *RuleService) UpdateRule(ctx context.Context, apiKey string, id string, req RuleRequest) (*models.Rule, error) {
+	existing, err := s.repo.GetRule(ctx, apiKey, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := req.rule(apiKey)
+	if _, err := rules.Compile(rule); err != nil {
+		return nil, err
+	}
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.invalidate(apiKey)
+
+	s.logger.Info().
+		Str("rule_id", rule.ID).
+		Msg("Rule updated")
+
+	return rule, nil
+}
+
+func (s *RuleService) DeleteRule(ctx context.Context, apiKey string, id string) error {
+	if err := s.repo.DeleteRule(ctx, apiKey, id); err != nil {
+		return err
+	}
+	s.invalidate(apiKey)
+
+	s.logger.Info().
+		Str("rule_id", id).
+		Msg("Rule deleted")
+
+	return nil
+}
+
+func (s *RuleService) DryRun(ctx context.Context, apiKey string, req DryRunRequest) (*DryRunResponse, error) {
+	var compiled []*rules.Compiled
+	if len(req.Rules) > 0 {
+		candidates := make([]*models.Rule, 0, len(req.Rules))
+		for i, r := range req.Rules {
+			rule := r.rule(apiKey)
+			// Unsaved rules have no ID; name them by position in the request.
+			rule.ID = fmt.Sprintf("rules[%d]", i)
+			candidates = append(candidates, rule)
+		}
+		c, err := rules.CompileAll(candidates)
+		if err != nil {
+			return nil, err
+		}
+		compiled = c
+	} else {
+		c, err := s.loadRules(ctx, apiKey)
+		if err != nil {
+			return nil, err
+		}
+		compiled = c
+	}
+
+	ev := &models.Event{
+		ID:         "dry-run",
+		APIKey:     apiKey,
+		Payload:    req.Event.Payload,
+		Timestamp:  time.Now(),
+		Context:    req.Event.Context,
+		DistinctID: req.Event.DistinctID,
+	}
+	res := rules.Apply(ev, compiled)
+
+	routes := ev.Routes
+	if routes == nil {
+		routes = []string{}
+	}
+	return &DryRunResponse{Result: res, Payload: ev.Payload, Routes: routes}, nil
+}
+
+// CachedRules returns the project's compiled rules for the processor.
+func (s *RuleService) CachedRules(ctx context.Context, apiKey string) ([]*rules.Compiled, error) {
+	if compiled, ok := s.cache.get(apiKey); ok {
+		return compiled, nil
+	}
+
+	compiled, err := s.loadRules(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.set(apiKey, compiled)
+
+	return compiled, nil
+}
+
+func (s *RuleService) loadRules(ctx context.Context, apiKey string) ([]*rules.Compiled, error) {
+	list, err := s.repo.ListRules(ctx, apiKey)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to load rules")
+		return nil, err
+	}
+	return rules.CompileAll(list)
+}
+
+func (s *RuleService) invalidate(apiKey string) {
+	s.cache.delete(apiKey)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/repositories/repotest"
+	"github.com/rs/zerolog"
+)
+
+func dryRunEvent() DryRunEvent {
+	return DryRunEvent{
+		Payload: map[string]any{
+			"event":      "signup",
+			"properties": map[string]any{"email": "ada@example.com"},
+		},
+		DistinctID: "user-1",
+	}
+}
+
+func TestDryRunInlineRules(t *testing.T) {
+	svc := NewRuleService(repotest.NewRuleStore(), zerolog.Nop())
+
+	res, err := svc.DryRun(context.Background(), "sync_key", DryRunRequest{
+		Rules: []RuleRequest{
+			{Name: "redact email", Action: "redact", Field: "properties.email"},
+			{Name: "route signups", Action: "route", Target: "crm", Condition: `payload.event == "signup"`},
+		},
+		Event: dryRunEvent(),
+	})
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+
+	if !reflect.DeepEqual(res.Applied, []string{"rules[0]", "rules[1]"}) {
+		t.Fatalf("applied = %v, want both inline rules by position", res.Applied)
+	}
+	if _, ok := res.Payload["properties"].(map[string]any)["email"]; ok {
+		t.Fatalf("payload = %v, want email redacted", res.Payload)
+	}
+	if !reflect.DeepEqual(res.Routes, []string{"crm"}) {
+		t.Fatalf("routes = %v, want [crm]", res.Routes)
+	}
+}
+
+func TestDryRunRejectsInvalidInlineRule(t *testing.T) {
+	svc := NewRuleService(repotest.NewRuleStore(), zerolog.Nop())
+
+	_, err := svc.DryRun(context.Background(), "sync_key", DryRunRequest{
+		Rules: []RuleRequest{{Name: "bad", Action: "drop", Condition: `"not a bool"`}},
+		Event: dryRunEvent(),
+	})
+	if !errors.Is(err, internalErrors.ErrInvalidRule) {
+		t.Fatalf("DryRun with an invalid rule: %v, want ErrInvalidRule", err)
+	}
+}
+
+func TestDryRunSavedRules(t *testing.T) {
+	store := repotest.NewRuleStore()
+	svc := NewRuleService(store, zerolog.Nop())
+	ctx := context.Background()
+
+	disabled := false
+	hash, err := svc.CreateRule(ctx, "sync_key", RuleRequest{Name: "hash email", Position: 1, Action: "hash", Field: "properties.email"})
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	drop, err := svc.CreateRule(ctx, "sync_key", RuleRequest{Name: "drop", Position: 2, Enabled: &disabled, Action: "drop"})
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if _, err := svc.CreateRule(ctx, "other_key", RuleRequest{Name: "drop", Action: "drop"}); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	res, err := svc.DryRun(ctx, "sync_key", DryRunRequest{Event: dryRunEvent()})
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if res.Dropped || !reflect.DeepEqual(res.Applied, []string{hash.ID}) {
+		t.Fatalf("result = %+v, want only the enabled saved rule %s", res.Result, hash.ID)
+	}
+	if email := res.Payload["properties"].(map[string]any)["email"]; email == "ada@example.com" {
+		t.Fatal("saved hash rule left the email in place")
+	}
+	if res.Routes == nil {
+		t.Fatal("routes = nil, want an empty list")
+	}
+
+	// Saved rules are read fresh, so an edit shows up in the next dry run.
+	if _, err := svc.UpdateRule(ctx, "sync_key", drop.ID, RuleRequest{Name: "drop", Position: 0, Action: "drop"}); err != nil {
+		t.Fatalf("UpdateRule: %v", err)
+	}
+	res, err = svc.DryRun(ctx, "sync_key", DryRunRequest{Event: dryRunEvent()})
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if !res.Dropped || res.DroppedBy != drop.ID {
+		t.Fatalf("result = %+v, want the enabled drop rule to drop the event", res.Result)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// sessionTTL bounds how long a revoked token, or one whose account was
+// deleted, keeps working in a process that checked it shortly before.
+const sessionTTL = 10 * time.Second
+
+// SessionStore looks up a user's token version. *repositories.AuthRepository
+// implements it.
+type SessionStore interface {
+	// TokenVersion returns false if the user does not exist or was deleted.
+	TokenVersion(ctx context.Context, userID string) (int, bool, error)
+}
+
+type session struct {
+	version int
+	active  bool
+}
+
+// SessionService tells the auth middleware and gRPC interceptors whether a
+// token is still valid, caching each user's token version for sessionTTL so
+// authenticated requests do not each cost a query.
+type SessionService struct {
+	repo   SessionStore
+	logger zerolog.Logger
+	cache  *tenantCache[session]
+}
+
+func NewSessionService(repo SessionStore, logger zerolog.Logger) *SessionService {
+	return &SessionService{
+		repo:   repo,
+		logger: logger.With().Str("service", "session").Logger(),
+		cache:  newTenantCache[session](sessionTTL, tenantCacheSize),
+	}
+}
+
+// TokenVersion returns the user's current token version, and false once the
+// user has been deleted.
+func (s *SessionService) TokenVersion(ctx context.Context, userID string) (int, bool, error) {
+	if cached, ok := s.cache.get(userID); ok {
+		return cached.version, cached.active, nil
+	}
+
+	version, active, err := s.repo.TokenVersion(ctx, userID)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("user_id", userID).
+			Msg("Failed to load token version")
+		return 0, false, err
+	}
+	s.cache.set(userID, session{version: version, active: active})
+	return version, active, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+)
+
+// EventStore accepts events for processing. *repositories.EventRepository
+// queues them; repotest.EventStore keeps them in memory for tests.
+type EventStore interface {
+	// AddEvent queues one event and reports whether it was queued. Adding an
+	// ID that is already queued is a no-op that reports false.
+	AddEvent(ctx context.Context, apiKey string, id string, payload map[string]any, evCtx models.EventContext) (bool, error)
+	// AddEvents queues a batch of events in one call, skipping IDs that are
+	// already queued, and reports how many were queued. A batch that fails can
+	// be retried whole.
+	AddEvents(ctx context.Context, apiKey string, events []repositories.QueuedEvent) (int, error)
+}
+
+// UserStore persists accounts. *repositories.AuthRepository stores them in
+// Postgres; repotest.UserStore keeps them in memory for tests.
+type UserStore interface {
+	// CreateUser stores a fully populated user, returning
+	// ErrUserAlreadyExists if the email is taken.
+	CreateUser(ctx context.Context, user *models.User) error
+	// GetUserByEmail and GetUserByID return nil, nil when there is no such
+	// user or it has been deleted.
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	// GetUserByIdentity finds the user linked to an OIDC provider account.
+	GetUserByIdentity(ctx context.Context, provider string, subject string) (*models.User, error)
+	// LinkIdentity links an OIDC provider account to a user; relinking is a
+	// no-op.
+	LinkIdentity(ctx context.Context, userID string, provider string, subject string, email string) error
+	// UpdateUser saves name, email, pending email and verification state.
+	UpdateUser(ctx context.Context, user *models.User) error
+	UpdatePassword(ctx context.Context, id string, hashed string, at time.Time) error
+	// ChangePassword is UpdatePassword that also bumps the token version,
+	// revoking every token issued before.
+	ChangePassword(ctx context.Context, id string, hashed string, at time.Time) error
+	// SetMFASecret stores a pending TOTP secret; EnableMFA activates it,
+	// marking step used and replacing the recovery codes. Both return
+	// ErrMFAAlreadyEnabled if MFA is already on.
+	SetMFASecret(ctx context.Context, userID string, secret string) error
+	EnableMFA(ctx context.Context, userID string, step int64, codeHashes []string) error
+	DisableMFA(ctx context.Context, userID string) error
+	// UseMFAStep and UseRecoveryCode consume a TOTP step or recovery code,
+	// reporting false if it was already used.
+	UseMFAStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+	// SetMFAChallenge makes challengeID the one challenge that can complete
+	// the next signin; UseMFAChallenge consumes it, reporting false if it was
+	// used or superseded, and resets the count of invalid codes.
+	SetMFAChallenge(ctx context.Context, userID string, challengeID string) error
+	UseMFAChallenge(ctx context.Context, userID string, challengeID string) (bool, error)
+	// RecordMFAFailure counts an invalid code, locking MFA codes until
+	// lockedUntil on the maxFailures-th in a row. It reports whether it did.
+	RecordMFAFailure(ctx context.Context, userID string, maxFailures int, lockedUntil time.Time) (bool, error)
+	// DeleteUser soft-deletes the user, revokes their write keys and
+	// schedules their data for erasure at eraseAfter.
+	DeleteUser(ctx context.Context, id string, eraseAfter time.Time) error
+}
+
+// RuleStore persists transformation rules. *repositories.RuleRepository
+// stores them in Postgres; repotest.RuleStore keeps them in memory for tests.
+type RuleStore interface {
+	CreateRule(ctx context.Context, rule *models.Rule) error
+	// ListRules returns the project's rules in evaluation order.
+	ListRules(ctx context.Context, apiKey string) ([]*models.Rule, error)
+	// GetRule, UpdateRule and DeleteRule return ErrRuleNotFound when the
+	// project has no such rule.
+	GetRule(ctx context.Context, apiKey string, id string) (*models.Rule, error)
+	UpdateRule(ctx context.Context, rule *models.Rule) error
+	DeleteRule(ctx context.Context, apiKey string, id string) error
+}
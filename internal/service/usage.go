@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/rs/zerolog"
+)
+
+const defaultPlan = "free"
+
+type UsageService struct {
+	repo   *repositories.UsageRepository
+	quotas map[string]int64
+	logger zerolog.Logger
+}
+
+// NewUsageService takes the monthly event quota for each plan. Plans missing
+// from the map fall back to the free quota; a quota of 0 means unlimited.
+func NewUsageService(repo *repositories.UsageRepository, quotas map[string]int64, logger zerolog.Logger) *UsageService {
+	return &UsageService{
+		repo:   repo,
+		quotas: quotas,
+		logger: logger.With().Str("service", "usage").Logger(),
+	}
+}
+
+// UsageResponse reports Limit 0 and Remaining -1 for unlimited plans.
+type UsageResponse struct {
+	Plan      string    `json:"plan"`
+	Period    string    `json:"period"`
+	Used      int64     `json:"used"`
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// ConsumeEvent counts a single event against the caller's monthly quota and
+// returns ErrQuotaExceeded once the plan limit has been reached.
+func (s *UsageService) ConsumeEvent(ctx context.Context, apiKey string) (*UsageResponse, error) {
+	return s.ConsumeEvents(ctx, apiKey, 1)
+}
+
+// ConsumeEvents counts n events at once; either all of them fit in the
+// remaining quota or none are counted and ErrQuotaExceeded is returned.
+func (s *UsageService) ConsumeEvents(ctx context.Context, apiKey string, n int64) (*UsageResponse, error) {
+	plan, limit, err := s.planLimit(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	period, resetsAt := currentPeriod()
+	allowed, used, err := s.repo.ConsumeMonthlyEvents(ctx, apiKey, period, n, limit, resetsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := newUsageResponse(plan, period, resetsAt, used, limit)
+	if !allowed {
+		s.logger.Warn().
+			Str("api_key", apiKey).
+			Str("plan", plan).
+			Int64("used", used).
+			Int64("limit", limit).
+			Msg("Monthly event quota exceeded")
+		return usage, internalErrors.ErrQuotaExceeded
+	}
+
+	return usage, nil
+}
+
+// ReleaseEvents gives back n events consumed by ConsumeEvents that were not
+// accepted after all, e.g. because they were duplicates or failed to queue.
+func (s *UsageService) ReleaseEvents(ctx context.Context, apiKey string, n int64) error {
+	if n <= 0 {
+		return nil
+	}
+	period, _ := currentPeriod()
+	if err := s.repo.ReleaseMonthlyEvents(ctx, apiKey, period, n); err != nil {
+		s.logger.Warn().Err(err).
+			Str("api_key", apiKey).
+			Int64("events", n).
+			Msg("Failed to release unused quota")
+		return err
+	}
+	return nil
+}
+
+func (s *UsageService) GetUsage(ctx context.Context, apiKey string) (*UsageResponse, error) {
+	plan, limit, err := s.planLimit(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	period, resetsAt := currentPeriod()
+	used, err := s.repo.GetMonthlyEvents(ctx, apiKey, period)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to fetch monthly usage")
+		return nil, err
+	}
+
+	return newUsageResponse(plan, period, resetsAt, used, limit), nil
+}
+
+func (s *UsageService) planLimit(ctx context.Context, apiKey string) (string, int64, error) {
+	plan, err := s.repo.GetPlanByAPIKey(ctx, apiKey)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to resolve plan for api key")
+		return "", 0, err
+	}
+
+	limit, ok := s.quotas[plan]
+	if !ok {
+		limit = s.quotas[defaultPlan]
+	}
+	return plan, limit, nil
+}
+
+func currentPeriod() (time.Time, time.Time) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+func newUsageResponse(plan string, period, resetsAt time.Time, used, limit int64) *UsageResponse {
+	remaining := int64(-1)
+	if limit > 0 {
+		remaining = max(limit-used, 0)
+	}
+	return &UsageResponse{
+		Plan:      plan,
+		Period:    period.Format("2006-01"),
+		Used:      used,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetsAt:  resetsAt,
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/repositories/repotest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// newTestUsageService serves plans from the repository's Redis cache, so no
+// database is needed. Each key in plans is an api key.
+func newTestUsageService(t *testing.T, plans map[string]string, quotas map[string]int64) *UsageService {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	for apiKey, plan := range plans {
+		srv.Set("plan:"+apiKey, plan)
+	}
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewUsageService(repositories.NewUsageRepository(nil, client, zerolog.Nop()), quotas, zerolog.Nop())
+}
+
+func TestConsumeEventsEnforcesQuota(t *testing.T) {
+	svc := newTestUsageService(t, map[string]string{"sync_free": "free"}, map[string]int64{"free": 3})
+	ctx := context.Background()
+
+	if _, err := svc.ConsumeEvents(ctx, "sync_free", 2); err != nil {
+		t.Fatalf("ConsumeEvents: %v", err)
+	}
+	// A batch that does not fit is rejected whole.
+	if _, err := svc.ConsumeEvents(ctx, "sync_free", 2); !errors.Is(err, internalErrors.ErrQuotaExceeded) {
+		t.Fatalf("ConsumeEvents over quota = %v, want ErrQuotaExceeded", err)
+	}
+	usage, err := svc.ConsumeEvent(ctx, "sync_free")
+	if err != nil {
+		t.Fatalf("ConsumeEvent: %v", err)
+	}
+	if usage.Used != 3 || usage.Remaining != 0 {
+		t.Fatalf("usage = %+v, want 3 used and 0 remaining", usage)
+	}
+}
+
+func TestConsumeEventsZeroQuotaIsUnlimited(t *testing.T) {
+	svc := newTestUsageService(t, map[string]string{"sync_ent": "enterprise"}, map[string]int64{"free": 1, "enterprise": 0})
+	ctx := context.Background()
+
+	usage, err := svc.ConsumeEvents(ctx, "sync_ent", 1000)
+	if err != nil {
+		t.Fatalf("ConsumeEvents: %v", err)
+	}
+	if usage.Limit != 0 || usage.Remaining != -1 {
+		t.Fatalf("usage = %+v, want unlimited", usage)
+	}
+}
+
+func TestReleaseEventsReturnsQuota(t *testing.T) {
+	svc := newTestUsageService(t, map[string]string{"sync_free": "free"}, map[string]int64{"free": 2})
+	ctx := context.Background()
+
+	if _, err := svc.ConsumeEvents(ctx, "sync_free", 2); err != nil {
+		t.Fatalf("ConsumeEvents: %v", err)
+	}
+	if err := svc.ReleaseEvents(ctx, "sync_free", 5); err != nil {
+		t.Fatalf("ReleaseEvents: %v", err)
+	}
+	usage, err := svc.GetUsage(ctx, "sync_free")
+	if err != nil {
+		t.Fatalf("GetUsage: %v", err)
+	}
+	if usage.Used != 0 {
+		t.Fatalf("used = %d after release, want 0", usage.Used)
+	}
+}
+
+func TestAddEventChargesOnlyQueuedEvents(t *testing.T) {
+	usage := newTestUsageService(t, map[string]string{"sync_free": "free"}, map[string]int64{"free": 2})
+	svc := NewEventService(repotest.NewEventStore(), usage, zerolog.Nop())
+	ctx := context.Background()
+	req := AddEventRequest{Payload: map[string]any{"event": "signup"}}
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.AddEventWithID(ctx, "sync_free", "msg-1", req); err != nil {
+			t.Fatalf("AddEventWithID: %v", err)
+		}
+	}
+	got, err := usage.GetUsage(ctx, "sync_free")
+	if err != nil {
+		t.Fatalf("GetUsage: %v", err)
+	}
+	if got.Used != 1 {
+		t.Fatalf("used = %d after resending one event, want 1", got.Used)
+	}
+}
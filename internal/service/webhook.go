@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/webhook"
+	"github.com/google/uuid"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// webhooksTTL bounds how long the processor keeps delivering to a
+	// deleted webhook or missing a new one.
+	webhooksTTL = 30 * time.Second
+
+	defaultDeliveryLimit = 50
+)
+
+type WebhookService struct {
+	repo   *repositories.WebhookRepository
+	logger zerolog.Logger
+	cache  *tenantCache[[]*models.Webhook]
+	// allowPrivate accepts webhook URLs on loopback and private addresses,
+	// for local development.
+	allowPrivate bool
+}
+
+func NewWebhookService(repo *repositories.WebhookRepository, allowPrivate bool, logger zerolog.Logger) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		logger:       logger.With().Str("service", "webhook").Logger(),
+		cache:        newTenantCache[[]*models.Webhook](webhooksTTL, tenantCacheSize),
+		allowPrivate: allowPrivate,
+	}
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url"`
+	EventTypes []string `json:"event_types" validate:"omitempty,dive,required"`
+	// Secret is generated when omitted.
+	Secret string `json:"secret" validate:"omitempty,min=16"`
+}
+
+// CreatedWebhook is the response to creating a webhook, the only one that
+// carries its signing secret.
+type CreatedWebhook struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+// webhookEnvelope is the body every delivery carries.
+type webhookEnvelope struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, apiKey string, req CreateWebhookRequest) (*CreatedWebhook, error) {
+	if !s.allowPrivate {
+		if err := webhook.CheckURL(ctx, req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := gonanoid.New(32)
+		if err != nil {
+			return nil, err
+		}
+		secret = "whsec_" + generated
+	}
+
+	eventTypes := req.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	wh := &models.Webhook{
+		ID:         uuid.New().String(),
+		APIKey:     apiKey,
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.CreateWebhook(ctx, wh); err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to create webhook")
+		return nil, err
+	}
+	s.invalidate(apiKey)
+
+	s.logger.Info().
+		Str("webhook_id", wh.ID).
+		Strs("event_types", wh.EventTypes).
+		Msg("Webhook created")
+
+	return &CreatedWebhook{Webhook: wh, Secret: wh.Secret}, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, apiKey string) ([]*models.Webhook, error) {
+	return s.repo.ListWebhooks(ctx, apiKey)
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, apiKey string, id string) error {
+	if err := s.repo.DeleteWebhook(ctx, apiKey, id); err != nil {
+		return err
+	}
+	s.invalidate(apiKey)
+
+	s.logger.Info().
+		Str("webhook_id", id).
+		Msg("Webhook deleted")
+
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, apiKey string, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = defaultDeliveryLimit
+	}
+	return s.repo.ListDeliveries(ctx, apiKey, webhookID, limit)
+}
+
+// Replay queues a new delivery with the same body as an earlier one,
+// whatever that one's outcome.
+func (s *WebhookService) Replay(ctx context.Context, apiKey string, webhookID string, deliveryID string) (*models.WebhookDelivery, error) {
+	original, err := s.repo.GetDelivery(ctx, apiKey, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	replay := &models.WebhookDelivery{
+		ID:            uuid.New().String(),
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.DeliveryStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := s.repo.CreateDeliveries(ctx, []*models.WebhookDelivery{replay}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("delivery_id", replay.ID).
+		Str("replay_of", original.ID).
+		Msg("Webhook delivery replayed")
+
+	return replay, nil
+}
+
+// EnqueueEvent queues a delivery of a processed event to every matching
+// webhook. The event type is the payload's "event" name, falling back to its
+// "type" (e.g. "identify").
+func (s *WebhookService) EnqueueEvent(ctx context.Context, ev *models.Event) error {
+	eventType := payloadString(ev.Payload, "event", "type")
+	if eventType == "" {
+		eventType = "event"
+	}
+	// The api key is deliberately left out of what leaves the system.
+	return s.Publish(ctx, ev.APIKey, ev.ID, eventType, ev.Timestamp, map[string]any{
+		"payload":     ev.Payload,
+		"timestamp":   ev.Timestamp.UTC(),
+		"distinct_id": ev.DistinctID,
+		"person_id":   ev.PersonID,
+	})
+}
+
+// Publish queues data for every webhook of apiKey subscribed to eventType.
+func (s *WebhookService) Publish(ctx context.Context, apiKey string, eventID string, eventType string, createdAt time.Time, data any) error {
+	webhooks, err := s.cachedWebhooks(ctx, apiKey)
+	if err != nil {
+		return err
+	}
+
+	var matching []*models.Webhook
+	for _, wh := range webhooks {
+		if wh.Matches(eventType) {
+			matching = append(matching, wh)
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookEnvelope{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: createdAt.UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]*models.WebhookDelivery, 0, len(matching))
+	for _, wh := range matching {
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			ID:            uuid.New().String(),
+			WebhookID:     wh.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       body,
+			Status:        models.DeliveryStatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		s.logger.Error().Err(err).
+			Str("event_id", eventID).
+			Msg("Failed to queue webhook deliveries")
+		return err
+	}
+	return nil
+}
+
+func (s *WebhookService) cachedWebhooks(ctx context.Context, apiKey string) ([]*models.Webhook, error) {
+	if webhooks, ok := s.cache.get(apiKey); ok {
+		return webhooks, nil
+	}
+
+	webhooks, err := s.repo.ListWebhooks(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.set(apiKey, webhooks)
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) invalidate(apiKey string) {
+	s.cache.delete(apiKey)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/Vighnesh-V-H/sync/internal/repositories"
+	"github.com/Vighnesh-V-H/sync/internal/utils"
+	"github.com/rs/zerolog"
+)
+
+type WriteKeyService struct {
+	repo   *repositories.WriteKeyRepository
+	logger zerolog.Logger
+}
+
+func NewWriteKeyService(repo *repositories.WriteKeyRepository, logger zerolog.Logger) *WriteKeyService {
+	return &WriteKeyService{
+		repo:   repo,
+		logger: logger.With().Str("service", "write_key").Logger(),
+	}
+}
+
+type CreateWriteKeyRequest struct {
+	AllowedOrigins []string `json:"allowed_origins" validate:"required,min=1,dive,required"`
+}
+
+func (s *WriteKeyService) CreateWriteKey(ctx context.Context, apiKey string, req CreateWriteKeyRequest) (*models.WriteKey, error) {
+	wk, err := s.repo.CreateWriteKey(ctx, apiKey, req.AllowedOrigins)
+	if err != nil {
+		s.logger.Error().Err(err).
+			Str("api_key", apiKey).
+			Msg("Failed to create write key")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("write_key_id", wk.ID).
+		Strs("allowed_origins", wk.AllowedOrigins).
+		Msg("Write key created")
+
+	return wk, nil
+}
+
+func (s *WriteKeyService) ListWriteKeys(ctx context.Context, apiKey string) ([]*models.WriteKey, error) {
+	return s.repo.ListWriteKeys(ctx, apiKey)
+}
+
+func (s *WriteKeyService) RevokeWriteKey(ctx context.Context, apiKey string, id string) error {
+	if err := s.repo.RevokeWriteKey(ctx, apiKey, id); err != nil {
+		return err
+	}
+
+	s.logger.Info().
+		Str("write_key_id", id).
+		Msg("Write key revoked")
+
+	return nil
+}
+
+// Resolve validates a write key and returns it with its owner's api key.
+//
+// The origin is only checked when the client sent one, and that is
+// intentional. Write keys are public, and the allowlist exists to stop other
+// websites from sending events with a key lifted from a page: browsers always
+// send Origin on cross-origin POSTs and cannot forge it. A non-browser client
+// can send any Origin it likes, so requiring the header would not keep it
+// out, while it would break pixel requests from email clients and no-JS
+// pages and server-side Segment SDKs, which carry neither Origin nor Referer.
+func (s *WriteKeyService) Resolve(ctx context.Context, key string, origin string) (*models.WriteKey, error) {
+	if key == "" {
+		return nil, internalErrors.ErrInvalidWriteKey
+	}
+
+	wk, err := s.repo.GetActiveWriteKey(ctx, key)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to look up write key")
+		return nil, err
+	}
+	if wk == nil {
+		return nil, internalErrors.ErrInvalidWriteKey
+	}
+
+	if origin != "" && !utils.OriginAllowed(wk.AllowedOrigins, origin) {
+		s.logger.Warn().
+			Str("write_key_id", wk.ID).
+			Str("origin", origin).
+			Msg("Write key used from disallowed origin")
+		return nil, internalErrors.ErrOriginNotAllowed
+	}
+
+	return wk, nil
+}
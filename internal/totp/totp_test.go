@@ -0,0 +1,65 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from RFC 6238 appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; these are their last 6 digits.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range vectors {
+		got, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", unix, err)
+		}
+		if got != want {
+			t.Errorf("Code at %d = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestMatchAllowsSkew(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	now := time.Now()
+	previous, _ := Code(secret, Step(now)-1)
+
+	step, ok := Match(secret, previous, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Fatalf("Match previous code = %d, %v; want step %d", step, ok, Step(now)-1)
+	}
+	if _, ok := Match(secret, previous, now, 0); ok {
+		t.Fatal("Match accepted previous code without skew")
+	}
+	if _, ok := Match(secret, "12345", now, 1); ok {
+		t.Fatal("Match accepted a short code")
+	}
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("Sync", "ada@example.com", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatalf("parse URI: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Sync:ada@example.com" {
+		t.Fatalf("URI %s has wrong scheme, type or label", u)
+	}
+	if q := u.Query(); q.Get("secret") != "JBSWY3DPEHPK3PXP" || q.Get("issuer") != "Sync" {
+		t.Fatalf("URI %s has wrong parameters", u)
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import "strings"
+
+// OriginAllowed reports whether origin matches one of the allowed patterns:
+// "*", an exact origin, or a wildcard subdomain such as "https://*.example.com".
+// It lives here rather than in middleware because the write key service
+// checks origins too, and services do not import middleware.
+func OriginAllowed(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "*":
+			return true
+		case pattern == origin:
+			return true
+		case strings.Contains(pattern, "://*."):
+			scheme, domain, _ := strings.Cut(pattern, "://*")
+			rest, ok := strings.CutPrefix(origin, scheme+"://")
+			if !ok {
+				continue
+			}
+			// domain keeps its leading dot, so "example.com" itself does not match "*.example.com".
+			if sub, ok := strings.CutSuffix(rest, domain); ok && sub != "" && !strings.ContainsAny(sub, "/:") {
+				return true
+			}
+		}
+	}
+	return false
+}
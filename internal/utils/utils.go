@@ -1,34 +1,30 @@
 package utils
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
 )
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(bytes), nil
-}
-
-func ComparePassword(hashed string, plain string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(plain))
-}
-
 type JWTConfig struct {
 	Secret string
 	Expiry time.Duration
 }
 
-func GenerateJWT(id string, email string, apiKey string, cfg JWTConfig) (string, error) {
+// GenerateJWT issues an access token. version is the user's token version,
+// which the auth middleware checks so tokens can be revoked.
+func GenerateJWT(id string, email string, apiKey string, version int, cfg JWTConfig) (string, error) {
 	claims := jwt.MapClaims{
 		"id":      id,
 		"email":   email,
 		"api_key": apiKey,
+		"ver":     version,
 		"exp":     time.Now().Add(cfg.Expiry).Unix(),
 		"iat":     time.Now().Unix(),
 	}
@@ -36,3 +32,180 @@ func GenerateJWT(id string, email string, apiKey string, cfg JWTConfig) (string,
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(cfg.Secret))
 }
+
+var (
+	ErrInvalidToken  = errors.New("invalid or expired token")
+	ErrMissingAPIKey = errors.New("token does not contain api_key")
+)
+
+// Claims are the identity fields GenerateJWT puts in a token.
+type Claims struct {
+	UserID       string
+	Email        string
+	APIKey       string
+	TokenVersion int
+}
+
+// ParseJWT validates a token issued by GenerateJWT and returns its claims.
+func ParseJWT(tokenString string, secret string) (*Claims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	apiKey, ok := claims["api_key"].(string)
+	if !ok {
+		return nil, ErrMissingAPIKey
+	}
+	userID, _ := claims["id"].(string)
+	email, _ := claims["email"].(string)
+	version, _ := claims["ver"].(float64)
+	return &Claims{UserID: userID, Email: email, APIKey: apiKey, TokenVersion: int(version)}, nil
+}
+
+// APIKeyFromJWT validates a token issued by GenerateJWT and returns the api
+// key it carries.
+func APIKeyFromJWT(tokenString string, secret string) (string, error) {
+	claims, err := ParseJWT(tokenString, secret)
+	if err != nil {
+		return "", err
+	}
+	return claims.APIKey, nil
+}
+
+// Sessions reports the current token version of an active user, and false
+// once the user has been deleted.
+type Sessions interface {
+	TokenVersion(ctx context.Context, userID string) (int, bool, error)
+}
+
+// Authenticate validates a token like ParseJWT and then, unless sessions is
+// nil, that its user still exists and it was issued at their current token
+// version, returning ErrInvalidToken otherwise. It is shared by the HTTP
+// middleware and gRPC interceptors.
+func Authenticate(ctx context.Context, tokenString string, secret string, sessions Sessions) (*Claims, error) {
+	claims, err := ParseJWT(tokenString, secret)
+	if err != nil || sessions == nil {
+		return claims, err
+	}
+
+	version, active, err := sessions.TokenVersion(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !active || version != claims.TokenVersion {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// MFAChallengeTTL is how long a user has to enter their second factor after
+// their password was accepted.
+const MFAChallengeTTL = 5 * time.Minute
+
+// GenerateMFAChallenge issues the token that stands in for a JWT between the
+// two signin steps, and returns it with its ID so it can be made single-use.
+// It is signed with a key derived from cfg.Secret and has no api_key, so it
+// is useless as an access token.
+func GenerateMFAChallenge(userID string, cfg JWTConfig) (string, string, error) {
+	id := uuid.NewString()
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"jti": id,
+		"typ": "mfa_challenge",
+		"exp": time.Now().Add(MFAChallengeTTL).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(derivedKey(cfg.Secret, "sync mfa challenge"))
+	if err != nil {
+		return "", "", err
+	}
+	return signed, id, nil
+}
+
+// ParseMFAChallenge validates a token from GenerateMFAChallenge and returns
+// the user ID it was issued for and its ID, or ErrInvalidToken.
+func ParseMFAChallenge(tokenString string, secret string) (string, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return derivedKey(secret, "sync mfa challenge"), nil
+	})
+	if err != nil || !token.Valid {
+		return "", "", ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "mfa_challenge" {
+		return "", "", ErrInvalidToken
+	}
+	userID, _ := claims["sub"].(string)
+	id, _ := claims["jti"].(string)
+	if userID == "" || id == "" {
+		return "", "", ErrInvalidToken
+	}
+	return userID, id, nil
+}
+
+// derivedKey derives a signing key for one kind of token from the JWT
+// secret, so no token can pass for another kind.
+func derivedKey(secret string, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// EmailConfirmationTTL is how long the token confirming an email change is
+// valid.
+const EmailConfirmationTTL = 24 * time.Hour
+
+// GenerateEmailConfirmation issues the token sent to a new address to prove
+// the user owns it. Like an MFA challenge it is signed with its own derived
+// key and is useless as an access token.
+func GenerateEmailConfirmation(userID string, email string, cfg JWTConfig) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": email,
+		"typ":   "email_confirmation",
+		"exp":   time.Now().Add(EmailConfirmationTTL).Unix(),
+		"iat":   time.Now().Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(derivedKey(cfg.Secret, "sync email confirmation"))
+}
+
+// ParseEmailConfirmation validates a token from GenerateEmailConfirmation and
+// returns the user ID and email it was issued for, or ErrInvalidToken.
+func ParseEmailConfirmation(tokenString string, secret string) (string, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return derivedKey(secret, "sync email confirmation"), nil
+	})
+	if err != nil || !token.Valid {
+		return "", "", ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "email_confirmation" {
+		return "", "", ErrInvalidToken
+	}
+	userID, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	if userID == "" || email == "" {
+		return "", "", ErrInvalidToken
+	}
+	return userID, email, nil
+}
@@ -0,0 +1,396 @@
+// Package wal is a segmented, append-only write-ahead log on local disk. The
+// events service spills queued events into it while the queue backend is
+// unreachable and replays them, oldest first, once it recovers.
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentExt = ".wal"
+	headerSize = 8
+
+	// maxRecordBytes guards against allocating for a corrupt length field.
+	maxRecordBytes = 64 << 20
+)
+
+var (
+	ErrFull   = errors.New("wal is full")
+	ErrClosed = errors.New("wal is closed")
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type Options struct {
+	// SegmentBytes is the size at which the active segment is rotated.
+	SegmentBytes int64
+	// MaxBytes caps the log's total size on disk; 0 means unlimited.
+	MaxBytes int64
+}
+
+// Log is safe for concurrent use. Records are framed as a 4-byte length and
+// a 4-byte CRC-32C of the data, so a write torn by a crash is detected and
+// discarded on the next Open.
+type Log struct {
+	dir  string
+	opts Options
+
+	mu       sync.Mutex
+	segments []segment // oldest first; the last one is active
+	active   *os.File
+	size     int64
+	// readOffset is how far into segments[0] has been consumed.
+	readOffset int64
+	closed     bool
+}
+
+type segment struct {
+	id   uint64
+	size int64
+}
+
+// Cursor marks the end of a batch returned by Read. Pass it to Commit once
+// the batch has been handed on.
+type Cursor struct {
+	id     uint64
+	offset int64
+}
+
+func Open(dir string, opts Options) (*Log, error) {
+	if opts.SegmentBytes <= 0 {
+		opts.SegmentBytes = 16 << 20
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Log{dir: dir, opts: opts}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		l.segments = append(l.segments, segment{id: id})
+	}
+	sort.Slice(l.segments, func(i, j int) bool { return l.segments[i].id < l.segments[j].id })
+
+	for i := range l.segments {
+		size, err := l.repair(l.segments[i].id)
+		if err != nil {
+			return nil, err
+		}
+		l.segments[i].size = size
+		l.size += size
+	}
+
+	// Always append to a fresh segment so existing ones are never reopened
+	// for writing.
+	var next uint64 = 1
+	if n := len(l.segments); n > 0 {
+		next = l.segments[n-1].id + 1
+	}
+	if err := l.openSegment(next); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Append writes records and fsyncs them before returning. Either all of the
+// records are written or ErrFull is returned and none are.
+func (l *Log) Append(records ...[]byte) error {
+	var buf []byte
+	for _, rec := range records {
+		if len(rec) > maxRecordBytes {
+			return fmt.Errorf("wal record of %d bytes exceeds %d", len(rec), maxRecordBytes)
+		}
+		var header [headerSize]byte
+		binary.BigEndian.PutUint32(header[0:4], uint32(len(rec)))
+		binary.BigEndian.PutUint32(header[4:8], crc32.Checksum(rec, crcTable))
+		buf = append(buf, header[:]...)
+		buf = append(buf, rec...)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+	if l.opts.MaxBytes > 0 && l.size+int64(len(buf)) > l.opts.MaxBytes {
+		return ErrFull
+	}
+
+	active := &l.segments[len(l.segments)-1]
+	if active.size > 0 && active.size+int64(len(buf)) > l.opts.SegmentBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+		active = &l.segments[len(l.segments)-1]
+	}
+
+	n, err := l.active.Write(buf)
+	if err != nil {
+		// Drop the partial write so the segment stays readable.
+		if truncErr := l.active.Truncate(active.size); truncErr == nil {
+			l.active.Seek(active.size, io.SeekStart)
+		}
+		return err
+	}
+	if err := l.active.Sync(); err != nil {
+		return err
+	}
+	active.size += int64(n)
+	l.size += int64(n)
+	return nil
+}
+
+// Read returns up to max unconsumed records, oldest first, without consuming
+// them. A batch never spans segments. It returns no records when the log has
+// been fully consumed.
+func (l *Log) Read(max int) ([][]byte, Cursor, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, Cursor{}, ErrClosed
+	}
+
+	// Skip over fully consumed segments.
+	for len(l.segments) > 1 && l.readOffset >= l.segments[0].size {
+		if err := l.dropOldest(); err != nil {
+			return nil, Cursor{}, err
+		}
+	}
+	seg := l.segments[0]
+	if l.readOffset >= seg.size {
+		return nil, Cursor{id: seg.id, offset: l.readOffset}, nil
+	}
+
+	f, err := os.Open(l.path(seg.id))
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(l.readOffset, io.SeekStart); err != nil {
+		return nil, Cursor{}, err
+	}
+
+	r := bufio.NewReader(io.LimitReader(f, seg.size-l.readOffset))
+	offset := l.readOffset
+	var records [][]byte
+	for len(records) < max {
+		rec, n, err := readRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Open already trimmed torn tails, so this is corruption in the
+			// middle of a segment; the rest of it cannot be framed.
+			return records, Cursor{id: seg.id, offset: seg.size}, fmt.Errorf("wal segment %d at offset %d: %w", seg.id, offset, err)
+		}
+		records = append(records, rec)
+		offset += n
+	}
+
+	return records, Cursor{id: seg.id, offset: offset}, nil
+}
+
+// Commit marks everything up to c as consumed. Fully consumed segments are
+// deleted, and the active segment is truncated once it has been drained.
+func (l *Log) Commit(c Cursor) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+	if len(l.segments) == 0 || l.segments[0].id != c.id || c.offset < l.readOffset {
+		return nil
+	}
+	l.readOffset = c.offset
+
+	if l.readOffset < l.segments[0].size {
+		return nil
+	}
+	if len(l.segments) > 1 {
+		return l.dropOldest()
+	}
+
+	// The active segment has been drained: reuse it from the start.
+	if err := l.active.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := l.active.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	l.size -= l.segments[0].size
+	l.segments[0].size = 0
+	l.readOffset = 0
+	return nil
+}
+
+// Pending returns the bytes appended but not yet committed.
+func (l *Log) Pending() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size - l.readOffset
+}
+
+// Size returns the bytes the log occupies on disk.
+func (l *Log) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// Segments returns the number of segment files, including the active one.
+func (l *Log) Segments() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.segments)
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	err := l.active.Close()
+
+	// Leave no empty segments behind for the next Open to skip over.
+	if last := l.segments[len(l.segments)-1]; last.size == 0 {
+		os.Remove(l.path(last.id))
+	}
+	return err
+}
+
+func (l *Log) path(id uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+func (l *Log) openSegment(id uint64) error {
+	f, err := os.OpenFile(l.path(id), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := syncDir(l.dir); err != nil {
+		f.Close()
+		return err
+	}
+	l.active = f
+	l.segments = append(l.segments, segment{id: id})
+	return nil
+}
+
+func (l *Log) rotate() error {
+	if err := l.active.Close(); err != nil {
+		return err
+	}
+	return l.openSegment(l.segments[len(l.segments)-1].id + 1)
+}
+
+func (l *Log) dropOldest() error {
+	oldest := l.segments[0]
+	if err := os.Remove(l.path(oldest.id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	l.segments = l.segments[1:]
+	l.size -= oldest.size
+	l.readOffset = 0
+	return nil
+}
+
+// repair truncates a segment after its last intact record and returns its
+// resulting size.
+func (l *Log) repair(id uint64) (int64, error) {
+	f, err := os.OpenFile(l.path(id), os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var size int64
+	for {
+		_, n, err := readRecord(r)
+		if err != nil {
+			break
+		}
+		size += n
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() != size {
+		if err := f.Truncate(size); err != nil {
+			return 0, err
+		}
+		if err := f.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	return size, nil
+}
+
+var errCorrupt = errors.New("corrupt record")
+
+// readRecord returns a record and the bytes it took up on disk.
+func readRecord(r *bufio.Reader) ([]byte, int64, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, 0, errCorrupt
+		}
+		return nil, 0, err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordBytes {
+		return nil, 0, errCorrupt
+	}
+	rec := make([]byte, length)
+	if _, err := io.ReadFull(r, rec); err != nil {
+		return nil, 0, errCorrupt
+	}
+	if crc32.Checksum(rec, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errCorrupt
+	}
+	return rec, headerSize + int64(length), nil
+}
+
+// syncDir makes a newly created segment file's directory entry durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
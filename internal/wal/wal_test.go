@@ -0,0 +1,141 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func readAll(t *testing.T, l *Log) []string {
+	t.Helper()
+	var got []string
+	for {
+		records, cursor, err := l.Read(2)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if len(records) == 0 {
+			return got
+		}
+		for _, rec := range records {
+			got = append(got, string(rec))
+		}
+		if err := l.Commit(cursor); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+}
+
+func TestAppendReadCommit(t *testing.T) {
+	l, err := Open(t.TempDir(), Options{SegmentBytes: 32})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+
+	var want []string
+	for i := range 10 {
+		rec := fmt.Sprintf("record-%d", i)
+		want = append(want, rec)
+		if err := l.Append([]byte(rec)); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if l.Segments() < 2 {
+		t.Fatalf("Segments = %d, want rotation at 32 bytes", l.Segments())
+	}
+
+	got := readAll(t, l)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("read %v, want %v", got, want)
+	}
+	if l.Pending() != 0 || l.Size() != 0 || l.Segments() != 1 {
+		t.Fatalf("after draining: pending=%d size=%d segments=%d", l.Pending(), l.Size(), l.Segments())
+	}
+}
+
+func TestReadWithoutCommitRereads(t *testing.T) {
+	l, err := Open(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+
+	l.Append([]byte("a"), []byte("b"))
+	first, _, _ := l.Read(10)
+	second, _, _ := l.Read(10)
+	if len(first) != 2 || len(second) != 2 {
+		t.Fatalf("uncommitted records were consumed: %q then %q", first, second)
+	}
+}
+
+func TestReopenReplaysUncommitted(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	l.Append([]byte("a"), []byte("b"))
+	l.Close()
+
+	l, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer l.Close()
+	l.Append([]byte("c"))
+
+	if got := readAll(t, l); fmt.Sprint(got) != "[a b c]" {
+		t.Fatalf("read %v after reopen, want [a b c]", got)
+	}
+}
+
+func TestOpenTrimsTornTail(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	l.Append([]byte("intact"))
+	path := l.path(l.segments[0].id)
+	l.Close()
+
+	// Simulate a crash part-way through writing a second record.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 9, 1, 2})
+	f.Close()
+
+	l, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer l.Close()
+
+	if got := readAll(t, l); fmt.Sprint(got) != "[intact]" {
+		t.Fatalf("read %v, want [intact]", got)
+	}
+}
+
+func TestAppendFull(t *testing.T) {
+	l, err := Open(t.TempDir(), Options{MaxBytes: 20})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer l.Close()
+
+	if err := l.Append([]byte("0123456789")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := l.Append([]byte("0123456789")); !errors.Is(err, ErrFull) {
+		t.Fatalf("Append over MaxBytes = %v, want ErrFull", err)
+	}
+
+	readAll(t, l)
+	if err := l.Append([]byte("0123456789")); err != nil {
+		t.Fatalf("Append after draining: %v", err)
+	}
+}
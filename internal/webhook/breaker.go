@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// breakers tracks consecutive failures per webhook. After threshold failures
+// in a row the endpoint's circuit opens for cooldown; the first delivery after
+// that is let through as a probe, and its outcome closes or re-opens it.
+type breakers struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	state map[string]*breakerState
+}
+
+type breakerState struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newBreakers(threshold int, cooldown time.Duration) *breakers {
+	return &breakers{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     make(map[string]*breakerState),
+	}
+}
+
+// allow reports whether a delivery to webhookID may be attempted now and, if
+// not, when to try again.
+func (b *breakers) allow(webhookID string, now time.Time) (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.state[webhookID]
+	if !ok || s.failures < b.threshold {
+		return true, time.Time{}
+	}
+	if now.Before(s.openUntil) {
+		return false, s.openUntil
+	}
+	if s.probing {
+		return false, now.Add(b.cooldown)
+	}
+	s.probing = true
+	return true, time.Time{}
+}
+
+func (b *breakers) success(webhookID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.state, webhookID)
+}
+
+// failure records a failed attempt and reports whether the circuit is now open.
+func (b *breakers) failure(webhookID string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.state[webhookID]
+	if !ok {
+		s = &breakerState{}
+		b.state[webhookID] = s
+	}
+	s.failures++
+	s.probing = false
+	if s.failures >= b.threshold {
+		s.openUntil = now.Add(b.cooldown)
+		return true
+	}
+	return false
+}
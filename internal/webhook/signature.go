@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader  = "X-Sync-Signature"
+	DeliveryIDHeader = "X-Sync-Delivery-Id"
+	EventIDHeader    = "X-Sync-Event-Id"
+)
+
+// Sign returns the X-Sync-Signature value for body: "t=<unix>,v1=<hex>",
+// where v1 is HMAC-SHA256(secret, "<unix>.<body>"). Including the timestamp
+// lets receivers reject replayed requests.
+func Sign(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, computeSignature(secret, unix, body))
+}
+
+// Verify checks a signature produced by Sign and that it is no older than
+// tolerance. Receivers written in Go can use it directly.
+func Verify(secret string, header string, body []byte, tolerance time.Duration) bool {
+	var unix, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			unix = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	secs, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || sig == "" {
+		return false
+	}
+	if tolerance > 0 && time.Since(time.Unix(secs, 0)) > tolerance {
+		return false
+	}
+
+	return hmac.Equal([]byte(sig), []byte(computeSignature(secret, unix, body)))
+}
+
+func computeSignature(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
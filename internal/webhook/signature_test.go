@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"e1"}`)
+	header := Sign("whsec_test", time.Now(), body)
+
+	if !Verify("whsec_test", header, body, 5*time.Minute) {
+		t.Fatal("valid signature rejected")
+	}
+	if Verify("whsec_other", header, body, 5*time.Minute) {
+		t.Fatal("signature accepted with the wrong secret")
+	}
+	if Verify("whsec_test", header, []byte(`{"id":"e2"}`), 5*time.Minute) {
+		t.Fatal("signature accepted for a different body")
+	}
+	if Verify("whsec_test", "t=abc,v1=00", body, 5*time.Minute) {
+		t.Fatal("malformed header accepted")
+	}
+}
+
+func TestVerifyRejectsOldSignatures(t *testing.T) {
+	body := []byte(`{}`)
+	header := Sign("whsec_test", time.Now().Add(-10*time.Minute), body)
+
+	if Verify("whsec_test", header, body, 5*time.Minute) {
+		t.Fatal("signature older than the tolerance accepted")
+	}
+	if !Verify("whsec_test", header, body, 0) {
+		t.Fatal("zero tolerance should skip the age check")
+	}
+}
@@ -0,0 +1,211 @@
+// Package webhook delivers events to tenant-configured HTTP endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/rs/zerolog"
+)
+
+const (
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+	// maxResponseBytes is how much of a receiver's response is read; the
+	// body only matters for keeping the connection reusable.
+	maxResponseBytes = 64 << 10
+)
+
+type Config struct {
+	MaxAttempts      int
+	Timeout          time.Duration
+	Concurrency      int
+	PollInterval     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// AllowPrivateDestinations lets the default client reach loopback and
+	// private addresses, for local development.
+	AllowPrivateDestinations bool
+}
+
+// Store hands out due deliveries and records their outcome.
+// *repositories.WebhookRepository keeps them in Postgres.
+type Store interface {
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, d *models.WebhookDelivery) error
+	Reschedule(ctx context.Context, id string, at time.Time) error
+}
+
+// Worker claims due deliveries from Postgres and sends them. Several workers
+// may run against the same database.
+type Worker struct {
+	repo     Store
+	client   *http.Client
+	cfg      Config
+	breakers *breakers
+	logger   zerolog.Logger
+}
+
+// NewWorker sends with client, or with NewClient(cfg.Timeout,
+// cfg.AllowPrivateDestinations) when client is nil.
+func NewWorker(repo Store, client *http.Client, cfg Config, logger zerolog.Logger) *Worker {
+	if client == nil {
+		client = NewClient(cfg.Timeout, cfg.AllowPrivateDestinations)
+	}
+	return &Worker{
+		repo:     repo,
+		client:   client,
+		cfg:      cfg,
+		breakers: newBreakers(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		logger:   logger.With().Str("component", "webhook_worker").Logger(),
+	}
+}
+
+// Run delivers until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	w.logger.Info().Msg("Webhook worker started")
+
+	// A claimed delivery is leased long enough for every in-flight request
+	// of the batch to time out.
+	lease := 2*w.cfg.Timeout + 30*time.Second
+
+	for {
+		if ctx.Err() != nil {
+			w.logger.Info().Msg("Webhook worker stopped")
+			return nil
+		}
+
+		deliveries, err := w.repo.ClaimDueDeliveries(ctx, w.cfg.Concurrency*4, lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error().Err(err).Msg("Failed to claim webhook deliveries")
+			}
+			sleep(ctx, w.cfg.PollInterval)
+			continue
+		}
+		if len(deliveries) == 0 {
+			sleep(ctx, w.cfg.PollInterval)
+			continue
+		}
+
+		sem := make(chan struct{}, w.cfg.Concurrency)
+		var wg sync.WaitGroup
+		for _, d := range deliveries {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(d *models.WebhookDelivery) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				w.deliver(ctx, d)
+			}(d)
+		}
+		wg.Wait()
+	}
+}
+
+func (w *Worker) deliver(ctx context.Context, d *models.WebhookDelivery) {
+	log := w.logger.With().
+		Str("delivery_id", d.ID).
+		Str("webhook_id", d.WebhookID).
+		Logger()
+
+	now := time.Now()
+	if ok, retryAt := w.breakers.allow(d.WebhookID, now); !ok {
+		if err := w.repo.Reschedule(ctx, d.ID, retryAt); err != nil {
+			log.Error().Err(err).Msg("Failed to defer webhook delivery")
+		}
+		return
+	}
+
+	status, err := w.send(ctx, d)
+	d.Attempts++
+	if status != 0 {
+		d.ResponseStatus = &status
+	}
+
+	if err == nil {
+		w.breakers.success(d.WebhookID)
+		deliveredAt := time.Now()
+		d.Status = models.DeliveryStatusSucceeded
+		d.DeliveredAt = &deliveredAt
+		d.LastError = nil
+		log.Debug().Int("status", status).Msg("Webhook delivered")
+	} else {
+		msg := err.Error()
+		d.LastError = &msg
+		if w.breakers.failure(d.WebhookID, now) {
+			log.Warn().Msg("Webhook circuit open")
+		}
+		if d.Attempts >= w.cfg.MaxAttempts {
+			d.Status = models.DeliveryStatusFailed
+			log.Warn().Err(err).Int("attempts", d.Attempts).Msg("Webhook delivery failed permanently")
+		} else {
+			d.NextAttemptAt = time.Now().Add(backoff(d.Attempts))
+			log.Info().Err(err).
+				Int("attempts", d.Attempts).
+				Time("next_attempt_at", d.NextAttemptAt).
+				Msg("Webhook delivery failed, will retry")
+		}
+	}
+
+	// Record the outcome even if shutdown has begun, so a delivered
+	// webhook is not sent again after the lease expires.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := w.repo.RecordAttempt(recordCtx, d); err != nil {
+		log.Error().Err(err).Msg("Failed to record webhook attempt")
+	}
+}
+
+func (w *Worker) send(ctx context.Context, d *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sync-webhooks/1.0")
+	req.Header.Set(SignatureHeader, Sign(d.Secret, time.Now(), d.Payload))
+	req.Header.Set(DeliveryIDHeader, d.ID)
+	req.Header.Set(EventIDHeader, d.EventID)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles from baseBackoff per attempt up to maxBackoff, with ±20%
+// jitter so retries to a recovering endpoint do not arrive in lockstep.
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << (attempt - 1)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	jitter := time.Duration(rand.Int64N(int64(d)/5*2+1)) - d/5
+	return d + jitter
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
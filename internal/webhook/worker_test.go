@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	internalErrors "github.com/Vighnesh-V-H/sync/internal/error"
+	"github.com/Vighnesh-V-H/sync/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type recordingStore struct {
+	mu       sync.Mutex
+	recorded []models.WebhookDelivery
+}
+
+func (s *recordingStore) ClaimDueDeliveries(context.Context, int, time.Duration) ([]*models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (s *recordingStore) RecordAttempt(_ context.Context, d *models.WebhookDelivery) error {
+	s.mu.Lock()
+	s.recorded = append(s.recorded, *d)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *recordingStore) Reschedule(context.Context, string, time.Time) error {
+	return nil
+}
+
+func (s *recordingStore) last(t *testing.T) models.WebhookDelivery {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.recorded) == 0 {
+		t.Fatal("no attempt recorded")
+	}
+	return s.recorded[len(s.recorded)-1]
+}
+
+func newTestWorker(store Store, maxAttempts int) *Worker {
+	return NewWorker(store, nil, Config{
+		MaxAttempts:              maxAttempts,
+		Timeout:                  5 * time.Second,
+		Concurrency:              1,
+		PollInterval:             time.Millisecond,
+		BreakerThreshold:         100,
+		BreakerCooldown:          time.Minute,
+		AllowPrivateDestinations: true,
+	}, zerolog.Nop())
+}
+
+func TestDeliverSignsRequest(t *testing.T) {
+	body := []byte(`{"id":"e1"}`)
+	var verified bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		verified = Verify("whsec_test", r.Header.Get(SignatureHeader), got, time.Minute) &&
+			r.Header.Get(DeliveryIDHeader) == "d1" &&
+			r.Header.Get(EventIDHeader) == "e1"
+	}))
+	defer srv.Close()
+
+	store := &recordingStore{}
+	newTestWorker(store, 3).deliver(t.Context(), &models.WebhookDelivery{
+		ID: "d1", WebhookID: "w1", EventID: "e1", Payload: body, URL: srv.URL, Secret: "whsec_test",
+	})
+
+	if !verified {
+		t.Fatal("receiver could not verify the request")
+	}
+	if d := store.last(t); d.Status != models.DeliveryStatusSucceeded || d.DeliveredAt == nil {
+		t.Fatalf("delivery = %+v, want succeeded", d)
+	}
+}
+
+func TestDeliverRetriesThenFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	store := &recordingStore{}
+	w := newTestWorker(store, 2)
+	d := &models.WebhookDelivery{ID: "d1", WebhookID: "w1", Payload: []byte(`{}`), URL: srv.URL, Status: models.DeliveryStatusPending}
+
+	before := time.Now()
+	w.deliver(t.Context(), d)
+	first := store.last(t)
+	if first.Status != models.DeliveryStatusPending || first.Attempts != 1 {
+		t.Fatalf("after one failure: status %q attempts %d, want pending 1", first.Status, first.Attempts)
+	}
+	if first.ResponseStatus == nil || *first.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("response status = %v, want 500", first.ResponseStatus)
+	}
+	if wait := first.NextAttemptAt.Sub(before); wait < baseBackoff*4/5 {
+		t.Fatalf("retry scheduled after %v, want about %v", wait, baseBackoff)
+	}
+
+	w.deliver(t.Context(), d)
+	if second := store.last(t); second.Status != models.DeliveryStatusFailed || second.Attempts != 2 {
+		t.Fatalf("after max attempts: status %q attempts %d, want failed 2", second.Status, second.Attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{
+		1:  baseBackoff,
+		2:  2 * baseBackoff,
+		3:  4 * baseBackoff,
+		30: maxBackoff,
+		70: maxBackoff,
+	} {
+		for range 20 {
+			got := backoff(attempt)
+			if got < want*4/5 || got > want*6/5 {
+				t.Fatalf("backoff(%d) = %v, want %v ±20%%", attempt, got, want)
+			}
+		}
+	}
+}
+
+func TestClientRefusesPrivateDestinations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("request reached a loopback receiver")
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(time.Second, false).Get(srv.URL)
+	if !errors.Is(err, internalErrors.ErrWebhookDestination) {
+		t.Fatalf("Get = %v, want ErrWebhookDestination", err)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+		"http://100.64.0.1/hook",
+	} {
+		if err := CheckURL(t.Context(), rawURL); !errors.Is(err, internalErrors.ErrWebhookDestination) {
+			t.Errorf("CheckURL(%s) = %v, want ErrWebhookDestination", rawURL, err)
+		}
+	}
+	if err := CheckURL(t.Context(), "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+}